@@ -0,0 +1,284 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/pipeline"
+)
+
+// AdminConfig is the configuration of the agent's admin listener, which serves
+// health and readiness checks over HTTP, along with an API to inspect, pause and
+// resume the operators of the pipeline.
+type AdminConfig struct {
+	// ListenAddress is the address the admin listener binds to, like `localhost:8081`
+	ListenAddress string `json:"listen_address" yaml:"listen_address"`
+}
+
+// CheckResult is the outcome of a health or readiness check
+type CheckResult struct {
+	OK bool `json:"ok"`
+
+	// Message describes why the check failed, if it did
+	Message string `json:"message,omitempty"`
+
+	// Operators maps the IDs of the operators that failed the check to the reason they failed
+	Operators map[string]string `json:"operators,omitempty"`
+
+	// Pipelines maps the names of the named pipelines that failed the check to the reason they failed
+	Pipelines map[string]string `json:"pipelines,omitempty"`
+}
+
+// OperatorInfo describes an operator of the agent's pipeline
+type OperatorInfo struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Outputs []string        `json:"outputs"`
+	Status  operator.Status `json:"status"`
+
+	// Pending is the number of entries the operator holds, like entries in the buffer of an output
+	Pending int `json:"pending"`
+
+	// Pausable is true if the operator can be paused and resumed
+	Pausable bool `json:"pausable"`
+	Paused   bool `json:"paused"`
+}
+
+// newOperatorInfo creates a description of an operator
+func newOperatorInfo(op operator.Operator) OperatorInfo {
+	info := OperatorInfo{
+		ID:      op.ID(),
+		Type:    op.Type(),
+		Outputs: make([]string, 0),
+		Status:  operator.GetStatus(op),
+		Pending: operator.GetPending(op),
+	}
+
+	if op.CanOutput() {
+		for _, output := range op.Outputs() {
+			info.Outputs = append(info.Outputs, output.ID())
+		}
+	}
+
+	if pausable, ok := operator.Unwrap(op).(operator.Pausable); ok {
+		info.Pausable = true
+		info.Paused = pausable.Paused()
+	}
+	return info
+}
+
+// Operators describes the operators of the agent's pipelines, sorted by ID.
+func (a *LogAgent) Operators() []OperatorInfo {
+	infos := make([]OperatorInfo, 0)
+	for _, p := range a.allPipelines() {
+		for _, op := range p.Operators() {
+			infos = append(infos, newOperatorInfo(op))
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// findOperator returns the operator of the agent's pipelines with the supplied ID
+func (a *LogAgent) findOperator(operatorID string) (operator.Operator, bool) {
+	for _, p := range a.allPipelines() {
+		for _, op := range p.Operators() {
+			if op.ID() == operatorID {
+				return op, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Ready checks that the agent is running, that every named pipeline has been built and
+// started, and that every operator in its pipelines has been started successfully.
+func (a *LogAgent) Ready() CheckResult {
+	a.pipelineMux.Lock()
+	running := a.running
+	pipelineErrors := make(map[string]string, len(a.pipelineErrors))
+	for name, err := range a.pipelineErrors {
+		pipelineErrors[name] = err.Error()
+	}
+	a.pipelineMux.Unlock()
+
+	if !running {
+		return CheckResult{Message: "agent is not running"}
+	}
+
+	unstarted := make([]string, 0)
+	for _, p := range a.allPipelines() {
+		if directed, ok := p.(*pipeline.DirectedPipeline); ok {
+			unstarted = append(unstarted, directed.Unstarted()...)
+		}
+	}
+
+	if len(unstarted) == 0 && len(pipelineErrors) == 0 {
+		return CheckResult{OK: true}
+	}
+
+	result := CheckResult{Message: "not all operators have been started"}
+	if len(pipelineErrors) != 0 {
+		result.Message = "not all pipelines have been started"
+		result.Pipelines = pipelineErrors
+	}
+	if len(unstarted) != 0 {
+		result.Operators = make(map[string]string, len(unstarted))
+		for _, id := range unstarted {
+			result.Operators[id] = "not started"
+		}
+	}
+	return result
+}
+
+// Healthy checks that every operator in the agent's pipelines reports itself as healthy.
+func (a *LogAgent) Healthy() CheckResult {
+	result := CheckResult{OK: true}
+	for _, p := range a.allPipelines() {
+		for _, op := range p.Operators() {
+			status := operator.GetStatus(op)
+			if status.Healthy {
+				continue
+			}
+
+			if result.OK {
+				result = CheckResult{
+					Message:   "not all operators are healthy",
+					Operators: make(map[string]string),
+				}
+			}
+			result.Operators[op.ID()] = status.Message
+		}
+	}
+	return result
+}
+
+// startAdmin will start the admin listener, if one is configured
+func (a *LogAgent) startAdmin() error {
+	if a.admin == nil || a.admin.ListenAddress == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", a.admin.ListenAddress)
+	if err != nil {
+		return errors.Wrap(err, "start admin listener")
+	}
+
+	// Requests are canceled when the listener shuts down, so that taps end
+	ctx, cancel := context.WithCancel(context.Background())
+	a.adminServer = &http.Server{
+		Addr:              listener.Addr().String(),
+		Handler:           a.adminHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	a.adminServer.RegisterOnShutdown(cancel)
+
+	go func() {
+		if err := a.adminServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			a.Errorw("Admin listener failed", "error", err)
+		}
+	}()
+
+	a.Infow("Started admin listener", "address", listener.Addr().String())
+	return nil
+}
+
+// adminHandler returns the handler that serves the endpoints of the admin listener
+func (a *LogAgent) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", checkHandler(a.Healthy))
+	mux.HandleFunc("/readyz", checkHandler(a.Ready))
+	mux.HandleFunc("GET /operators", a.operatorsHandler)
+	mux.HandleFunc("GET /operators/{id}", a.operatorHandler)
+	mux.HandleFunc("POST /operators/{id}/pause", a.pauseHandler(true))
+	mux.HandleFunc("POST /operators/{id}/resume", a.pauseHandler(false))
+	mux.HandleFunc("GET /operators/{id}/tap", a.tapHandler)
+	return mux
+}
+
+// stopAdmin will stop the admin listener, if it is running
+func (a *LogAgent) stopAdmin() error {
+	if a.adminServer == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return a.adminServer.Shutdown(ctx)
+}
+
+// checkHandler will serve the result of a check as json, with a
+// 503 status code if the check failed
+func checkHandler(check func() CheckResult) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		result := check()
+
+		w.Header().Set("Content-Type", "application/json")
+		if !result.OK {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(result)
+	}
+}
+
+// adminError is the body of an admin API response for a request that failed
+type adminError struct {
+	Error string `json:"error"`
+}
+
+// writeJSON will write a value as the json body of a response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// operatorsHandler will serve the descriptions of the operators in the pipeline
+func (a *LogAgent) operatorsHandler(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, a.Operators())
+}
+
+// operatorHandler will serve the description of a single operator in the pipeline
+func (a *LogAgent) operatorHandler(w http.ResponseWriter, r *http.Request) {
+	op, ok := a.findOperator(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, adminError{fmt.Sprintf("operator '%s' does not exist", r.PathValue("id"))})
+		return
+	}
+	writeJSON(w, http.StatusOK, newOperatorInfo(op))
+}
+
+// pauseHandler will pause or resume an operator in the pipeline, and serve its description
+func (a *LogAgent) pauseHandler(pause bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		operatorID := r.PathValue("id")
+		op, ok := a.findOperator(operatorID)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, adminError{fmt.Sprintf("operator '%s' does not exist", operatorID)})
+			return
+		}
+
+		pausable, ok := operator.Unwrap(op).(operator.Pausable)
+		if !ok {
+			writeJSON(w, http.StatusConflict, adminError{fmt.Sprintf("operator '%s' can not be paused", operatorID)})
+			return
+		}
+
+		if pause {
+			pausable.Pause()
+			a.Infow("Paused operator", "operator_id", operatorID)
+		} else {
+			pausable.Resume()
+			a.Infow("Resumed operator", "operator_id", operatorID)
+		}
+		writeJSON(w, http.StatusOK, newOperatorInfo(op))
+	}
+}
@@ -0,0 +1,219 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type unhealthyOperator struct {
+	*testutil.Operator
+}
+
+func (o unhealthyOperator) Status() operator.Status {
+	return operator.Status{Healthy: false, Message: "broken"}
+}
+
+type pausableOperator struct {
+	*testutil.Operator
+	*helper.Pauser
+}
+
+func newOperatorsTestAgent() (*LogAgent, *pausableOperator) {
+	input := &pausableOperator{
+		Operator: testutil.NewMockOperator("$.input"),
+		Pauser:   &helper.Pauser{},
+	}
+	output := unhealthyOperator{testutil.NewMockOperator("$.output")}
+
+	input.On("Type").Return("tcp_input")
+	input.On("Outputs").Return([]operator.Operator{output})
+	output.On("Type").Return("stdout")
+	output.On("Outputs").Return(nil)
+
+	pipeline := &testutil.Pipeline{}
+	pipeline.On("Operators").Return([]operator.Operator{output, input})
+
+	agent := &LogAgent{
+		SugaredLogger: zap.NewNop().Sugar(),
+		pipeline:      pipeline,
+	}
+	return agent, input
+}
+
+func TestAgentReady(t *testing.T) {
+	pipeline := &testutil.Pipeline{}
+	pipeline.On("Start").Return(nil)
+
+	agent := LogAgent{
+		SugaredLogger: zap.NewNop().Sugar(),
+		pipeline:      pipeline,
+	}
+	require.False(t, agent.Ready().OK)
+
+	require.NoError(t, agent.Start())
+	require.True(t, agent.Ready().OK)
+}
+
+func TestAgentHealthy(t *testing.T) {
+	healthy := testutil.NewMockOperator("$.healthy")
+	unhealthy := unhealthyOperator{testutil.NewMockOperator("$.unhealthy")}
+
+	pipeline := &testutil.Pipeline{}
+	pipeline.On("Operators").Return([]operator.Operator{healthy, unhealthy})
+
+	agent := LogAgent{
+		SugaredLogger: zap.NewNop().Sugar(),
+		pipeline:      pipeline,
+	}
+
+	result := agent.Healthy()
+	require.False(t, result.OK)
+	require.Equal(t, map[string]string{"$.unhealthy": "broken"}, result.Operators)
+}
+
+func TestCheckHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		result         CheckResult
+		expectedStatus int
+	}{
+		{"OK", CheckResult{OK: true}, http.StatusOK},
+		{"Failed", CheckResult{Message: "failed"}, http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := checkHandler(func() CheckResult { return tc.result })
+			recorder := httptest.NewRecorder()
+			handler(recorder, httptest.NewRequest("GET", "/healthz", nil))
+			require.Equal(t, tc.expectedStatus, recorder.Code)
+
+			var result CheckResult
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func TestAdminListener(t *testing.T) {
+	pipeline := &testutil.Pipeline{}
+	pipeline.On("Start").Return(nil)
+	pipeline.On("Stop").Return(nil)
+	pipeline.On("Operators").Return([]operator.Operator{})
+	database := &testutil.Database{}
+	database.On("Close").Return(nil)
+
+	agent := LogAgent{
+		SugaredLogger: zap.NewNop().Sugar(),
+		pipeline:      pipeline,
+		database:      database,
+		admin:         &AdminConfig{ListenAddress: "localhost:0"},
+	}
+	require.NoError(t, agent.Start())
+	defer func() { require.NoError(t, agent.Stop()) }()
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		resp, err := http.Get(fmt.Sprintf("http://%s%s", agent.adminServer.Addr, path))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestAgentOperators(t *testing.T) {
+	agent, input := newOperatorsTestAgent()
+	input.Pause()
+
+	expected := []OperatorInfo{
+		{
+			ID:       "$.input",
+			Type:     "tcp_input",
+			Outputs:  []string{"$.output"},
+			Status:   operator.Status{Healthy: true},
+			Pausable: true,
+			Paused:   true,
+		},
+		{
+			ID:      "$.output",
+			Type:    "stdout",
+			Outputs: []string{},
+			Status:  operator.Status{Healthy: false, Message: "broken"},
+		},
+	}
+	require.Equal(t, expected, agent.Operators())
+}
+
+func TestAdminOperatorsAPI(t *testing.T) {
+	agent, input := newOperatorsTestAgent()
+	handler := agent.adminHandler()
+
+	request := func(method, path string) (int, map[string]interface{}) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+
+		var body map[string]interface{}
+		if recorder.Body.Len() != 0 && recorder.Body.Bytes()[0] == '{' {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		}
+		return recorder.Code, body
+	}
+
+	t.Run("List", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/operators", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var operators []OperatorInfo
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &operators))
+		require.Equal(t, agent.Operators(), operators)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		code, body := request("GET", "/operators/$.output")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, "stdout", body["type"])
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		code, body := request("GET", "/operators/$.missing")
+		require.Equal(t, http.StatusNotFound, code)
+		require.Equal(t, "operator '$.missing' does not exist", body["error"])
+	})
+
+	t.Run("PauseResume", func(t *testing.T) {
+		code, body := request("POST", "/operators/$.input/pause")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, true, body["paused"])
+		require.True(t, input.Paused())
+
+		code, body = request("POST", "/operators/$.input/resume")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, false, body["paused"])
+		require.False(t, input.Paused())
+	})
+
+	t.Run("PauseNotPausable", func(t *testing.T) {
+		code, body := request("POST", "/operators/$.output/pause")
+		require.Equal(t, http.StatusConflict, code)
+		require.Equal(t, "operator '$.output' can not be paused", body["error"])
+	})
+
+	t.Run("PauseMissing", func(t *testing.T) {
+		code, _ := request("POST", "/operators/$.missing/pause")
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("PauseWrongMethod", func(t *testing.T) {
+		code, _ := request("GET", "/operators/$.input/pause")
+		require.Equal(t, http.StatusMethodNotAllowed, code)
+	})
+}
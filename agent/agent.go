@@ -1,9 +1,14 @@
 package agent
 
 import (
+	"context"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/observiq/stanza/database"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/pipeline"
 	"go.uber.org/zap"
 )
@@ -13,6 +18,25 @@ type LogAgent struct {
 	database database.Database
 	pipeline pipeline.Pipeline
 
+	// pipelines holds the named pipelines that are running, while pipelineErrors
+	// holds the errors of the named pipelines that failed to build or start
+	pipelines      map[string]pipeline.Pipeline
+	pipelineErrors map[string]error
+
+	configFiles     []string
+	buildContext    operator.BuildContext
+	defaultOutput   operator.Operator
+	reloadInterval  time.Duration
+	shutdownTimeout time.Duration
+
+	admin       *AdminConfig
+	adminServer *http.Server
+
+	pipelineMux sync.Mutex
+	running     bool
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+
 	startOnce sync.Once
 	stopOnce  sync.Once
 
@@ -22,10 +46,26 @@ type LogAgent struct {
 // Start will start the log monitoring process
 func (a *LogAgent) Start() (err error) {
 	a.startOnce.Do(func() {
+		if err = a.startAdmin(); err != nil {
+			return
+		}
+
+		a.pipelineMux.Lock()
+		defer a.pipelineMux.Unlock()
+
 		err = a.pipeline.Start()
 		if err != nil {
+			_ = a.stopAdmin()
 			return
 		}
+		a.startPipelines()
+		a.running = true
+
+		if a.reloadInterval > 0 && len(a.configFiles) > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			a.cancel = cancel
+			a.startConfigWatcher(ctx)
+		}
 	})
 	return
 }
@@ -33,7 +73,21 @@ func (a *LogAgent) Start() (err error) {
 // Stop will stop the log monitoring process
 func (a *LogAgent) Stop() (err error) {
 	a.stopOnce.Do(func() {
-		err = a.pipeline.Stop()
+		if a.cancel != nil {
+			a.cancel()
+			a.wg.Wait()
+		}
+
+		if err := a.stopAdmin(); err != nil {
+			a.Errorw("Failed to stop admin listener", "error", err)
+		}
+
+		a.pipelineMux.Lock()
+		defer a.pipelineMux.Unlock()
+		a.running = false
+
+		a.stopPipelines()
+		err = a.stopPipeline("", a.pipeline)
 		if err != nil {
 			return
 		}
@@ -45,3 +99,101 @@ func (a *LogAgent) Stop() (err error) {
 	})
 	return
 }
+
+// stopPipeline will stop a pipeline, which is named unless it is the default pipeline. If the
+// agent has a shutdown timeout, the pipeline is drained first, so that the entries it holds
+// can be delivered.
+func (a *LogAgent) stopPipeline(name string, p pipeline.Pipeline) error {
+	directed, ok := p.(*pipeline.DirectedPipeline)
+	if a.shutdownTimeout <= 0 || !ok {
+		return p.Stop()
+	}
+
+	logger := a.SugaredLogger
+	if name != "" {
+		logger = logger.With("pipeline", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	start := time.Now()
+	results, err := directed.Drain(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending, remaining := 0, 0
+	for _, result := range results {
+		pending += result.Pending
+		remaining += result.Remaining
+		if result.Remaining > 0 {
+			logger.Warnw("Operator was stopped before delivering all of its entries",
+				"operator_id", result.OperatorID,
+				"remaining", result.Remaining,
+			)
+		}
+	}
+
+	drained := pending - remaining
+	if drained < 0 {
+		drained = 0
+	}
+	logger.Infow("Drained pipeline",
+		"drained", drained,
+		"remaining", remaining,
+		"duration", time.Since(start),
+	)
+	return nil
+}
+
+// Reload will read the agent's config files again and replace the running pipeline
+// with the pipeline they describe. Operators whose config is unchanged keep running
+// untouched, while changed, added and removed operators are stopped and started as
+// needed. If the new config is invalid, the running pipeline is left in place.
+// Named pipelines are reloaded independently of each other and of the default
+// pipeline, so their errors are logged rather than returned. Plugins are not
+// registered again, so changes to plugin templates require a restart.
+func (a *LogAgent) Reload() error {
+	a.pipelineMux.Lock()
+	defer a.pipelineMux.Unlock()
+
+	if !a.running {
+		return errors.NewError(
+			"agent cannot be reloaded, because it is not running",
+			"ensure that the agent is started before reloading it",
+		)
+	}
+
+	if len(a.configFiles) == 0 {
+		return errors.NewError(
+			"agent cannot be reloaded, because it was not built from config files",
+			"build the agent WithConfigFiles to enable reloading",
+		)
+	}
+
+	running, ok := a.pipeline.(*pipeline.DirectedPipeline)
+	if !ok {
+		return errors.NewError(
+			"agent cannot be reloaded, because its pipeline does not support reloading",
+			"this is an unexpected internal error",
+		)
+	}
+
+	cfg, err := NewConfigFromGlobs(a.configFiles)
+	if err != nil {
+		return errors.Wrap(err, "read configs from globs")
+	}
+
+	a.reloadPipelines(cfg.Pipelines)
+
+	next, err := cfg.Pipeline.Reload(a.buildContext, a.defaultOutput, running)
+	if next != nil {
+		a.pipeline = next
+	}
+	if err != nil {
+		return errors.Wrap(err, "reload pipeline")
+	}
+
+	return nil
+}
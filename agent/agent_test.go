@@ -2,8 +2,13 @@ package agent
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/pipeline"
 	"github.com/observiq/stanza/testutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -93,3 +98,85 @@ func TestStopAgentDatabaseFailure(t *testing.T) {
 	pipeline.AssertCalled(t, "Stop")
 	database.AssertCalled(t, "Close")
 }
+
+func TestStopAgentDrain(t *testing.T) {
+	output := testutil.NewFakeOutput(t)
+	running, err := pipeline.NewDirectedPipeline([]operator.Operator{output})
+	require.NoError(t, err)
+	require.NoError(t, running.Start())
+	database := &testutil.Database{}
+	database.On("Close").Return(nil)
+
+	agent := LogAgent{
+		SugaredLogger:   zap.NewNop().Sugar(),
+		pipeline:        running,
+		database:        database,
+		shutdownTimeout: time.Second,
+	}
+	err = agent.Stop()
+	require.NoError(t, err)
+	require.Equal(t, []string{"$.fake"}, running.Unstarted())
+	database.AssertCalled(t, "Close")
+}
+
+func TestReloadAgent(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	configFile := filepath.Join(tempDir, "config.yaml")
+	writeConfig := func(contents string) {
+		err := ioutil.WriteFile(configFile, []byte(contents), 0600)
+		require.NoError(t, err)
+	}
+
+	writeConfig(`
+pipeline:
+  - id: first
+    type: noop
+  - id: second
+    type: noop
+`)
+
+	agent, err := NewBuilder(zap.NewNop().Sugar()).
+		WithConfigFiles([]string{configFile}).
+		WithDefaultOutput(testutil.NewFakeOutput(t)).
+		Build()
+	require.NoError(t, err)
+
+	err = agent.Reload()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not running")
+
+	require.NoError(t, agent.Start())
+	defer agent.Stop()
+
+	operators := func() map[string]operator.Operator {
+		ops := make(map[string]operator.Operator)
+		for _, op := range agent.pipeline.Operators() {
+			ops[op.ID()] = op
+		}
+		return ops
+	}
+	before := operators()
+
+	writeConfig(`
+pipeline:
+  - id: first
+    type: noop
+  - id: second
+    type: noop
+    if: 'true'
+`)
+	require.NoError(t, agent.Reload())
+	after := operators()
+	require.Same(t, before["$.first"], after["$.first"])
+	require.NotSame(t, before["$.second"], after["$.second"])
+
+	writeConfig(`
+pipeline:
+  - id: first
+    type: noop
+    output: missing
+`)
+	err = agent.Reload()
+	require.Error(t, err)
+	require.Equal(t, after, operators())
+}
@@ -13,12 +13,13 @@ import (
 
 // LogAgentBuilder is a construct used to build a log agent
 type LogAgentBuilder struct {
-	configFiles   []string
-	config        *Config
-	logger        *zap.SugaredLogger
-	pluginDir     string
-	databaseFile  string
-	defaultOutput operator.Operator
+	configFiles    []string
+	config         *Config
+	logger         *zap.SugaredLogger
+	pluginDir      string
+	databaseFile   string
+	defaultOutput  operator.Operator
+	reloadInterval time.Duration
 }
 
 // NewBuilder creates a new LogAgentBuilder
@@ -58,6 +59,13 @@ func (b *LogAgentBuilder) WithDefaultOutput(defaultOutput operator.Operator) *Lo
 	return b
 }
 
+// WithReloadInterval sets how often the config files are checked for changes
+// when building a log agent. Changes are not watched for if the interval is zero.
+func (b *LogAgentBuilder) WithReloadInterval(interval time.Duration) *LogAgentBuilder {
+	b.reloadInterval = interval
+	return b
+}
+
 // Build will build a new log agent using the values defined on the builder
 func (b *LogAgentBuilder) Build() (*LogAgent, error) {
 	db, err := database.OpenDatabase(b.databaseFile)
@@ -94,9 +102,27 @@ func (b *LogAgentBuilder) Build() (*LogAgent, error) {
 		return nil, err
 	}
 
+	pipelines, pipelineErrors := buildPipelines(b.config.Pipelines, buildContext)
+	for name, err := range pipelineErrors {
+		b.logger.Errorw("Failed to build pipeline", "pipeline", name, zap.Any("error", err))
+	}
+
+	var shutdownTimeout time.Duration
+	if b.config.ShutdownTimeout != nil {
+		shutdownTimeout = b.config.ShutdownTimeout.Raw()
+	}
+
 	return &LogAgent{
-		pipeline:      pipeline,
-		database:      db,
-		SugaredLogger: b.logger,
+		pipeline:        pipeline,
+		pipelines:       pipelines,
+		pipelineErrors:  pipelineErrors,
+		database:        db,
+		configFiles:     b.configFiles,
+		buildContext:    buildContext,
+		defaultOutput:   b.defaultOutput,
+		reloadInterval:  b.reloadInterval,
+		shutdownTimeout: shutdownTimeout,
+		admin:           b.config.Admin,
+		SugaredLogger:   b.logger,
 	}, nil
 }
@@ -5,25 +5,40 @@ import (
 	"io/ioutil"
 	"path/filepath"
 
+	"github.com/observiq/stanza/operator/helper"
 	"github.com/observiq/stanza/pipeline"
+	"github.com/observiq/stanza/secret"
 	yaml "gopkg.in/yaml.v2"
 )
 
 // Config is the configuration of the stanza log agent.
 type Config struct {
-	Pipeline pipeline.Config `json:"pipeline"                yaml:"pipeline"`
+	Pipeline pipeline.Config `json:"pipeline"            yaml:"pipeline"`
+
+	// Pipelines are named pipelines that run in isolation from each other and from
+	// the default pipeline, keyed by the name of the pipeline
+	Pipelines map[string]pipeline.Config `json:"pipelines,omitempty" yaml:"pipelines,omitempty"`
+
+	Admin           *AdminConfig     `json:"admin,omitempty"            yaml:"admin,omitempty"`
+	ShutdownTimeout *helper.Duration `json:"shutdown_timeout,omitempty" yaml:"shutdown_timeout,omitempty"`
 }
 
-// NewConfigFromFile will create a new agent config from a YAML file.
+// NewConfigFromFile will create a new agent config from a YAML file. References to environment
+// variables and files in the config, like `${env:NAME}`, are replaced by their values.
 func NewConfigFromFile(file string) (*Config, error) {
 	contents, err := ioutil.ReadFile(file) // #nosec - configs load based on user specified directory
 	if err != nil {
 		return nil, fmt.Errorf("could not find config file: %s", err)
 	}
 
+	contents, err = secret.Expand(contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to substitute values in config file: %s", err)
+	}
+
 	config := Config{}
 	if err := yaml.UnmarshalStrict(contents, &config); err != nil {
-		return nil, fmt.Errorf("failed to read config file as yaml: %s", err)
+		return nil, fmt.Errorf("failed to read config file as yaml: %s", secret.Redact(err.Error()))
 	}
 
 	return &config, nil
@@ -31,17 +46,9 @@ func NewConfigFromFile(file string) (*Config, error) {
 
 // NewConfigFromGlobs will create an agent config from multiple files matching a pattern.
 func NewConfigFromGlobs(globs []string) (*Config, error) {
-	paths := make([]string, 0, len(globs))
-	for _, glob := range globs {
-		matches, err := filepath.Glob(glob)
-		if err != nil {
-			return nil, err
-		}
-		paths = append(paths, matches...)
-	}
-
-	if len(paths) == 0 {
-		return nil, fmt.Errorf("No config files found")
+	paths, err := expandGlobs(globs)
+	if err != nil {
+		return nil, err
 	}
 
 	config := &Config{}
@@ -57,8 +64,38 @@ func NewConfigFromGlobs(globs []string) (*Config, error) {
 	return config, nil
 }
 
+// expandGlobs will return the paths of the config files matching the globs.
+func expandGlobs(globs []string) ([]string, error) {
+	paths := make([]string, 0, len(globs))
+	for _, glob := range globs {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("No config files found")
+	}
+
+	return paths, nil
+}
+
 // mergeConfigs will merge two agent configs.
 func mergeConfigs(dst *Config, src *Config) *Config {
 	dst.Pipeline = append(dst.Pipeline, src.Pipeline...)
+	for name, srcPipeline := range src.Pipelines {
+		if dst.Pipelines == nil {
+			dst.Pipelines = make(map[string]pipeline.Config)
+		}
+		dst.Pipelines[name] = append(dst.Pipelines[name], srcPipeline...)
+	}
+	if src.Admin != nil {
+		dst.Admin = src.Admin
+	}
+	if src.ShutdownTimeout != nil {
+		dst.ShutdownTimeout = src.ShutdownTimeout
+	}
 	return dst
 }
@@ -4,10 +4,13 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/observiq/stanza/operator"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/noop"
+	"github.com/observiq/stanza/operator/helper"
 	"github.com/observiq/stanza/pipeline"
+	"github.com/observiq/stanza/plugin"
 	"github.com/observiq/stanza/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -51,6 +54,90 @@ pipeline:
 	require.Contains(t, err.Error(), "failed to read config file as yaml")
 }
 
+func TestNewConfigWithSubstitution(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	secretFile := filepath.Join(tempDir, "secret")
+	err := ioutil.WriteFile(secretFile, []byte("file_secret_value\n"), 0600)
+	require.NoError(t, err)
+
+	pluginContents := []byte(`
+parameters:
+  - name: api_key
+    type: string
+pipeline:
+  - id: {{ .api_key }}
+    type: noop
+    output: {{ .output }}
+`)
+	p, err := plugin.NewPlugin("substitution_plugin", pluginContents)
+	require.NoError(t, err)
+	operator.RegisterPlugin(p.ID, p.NewBuilder)
+
+	t.Setenv("STANZA_TEST_ENV_SECRET", "env_secret_value")
+	configFile := filepath.Join(tempDir, "config.yaml")
+	configContents := `
+pipeline:
+  - id: ${env:STANZA_TEST_ENV_SECRET}
+    type: noop
+  - id: ${file:` + secretFile + `}
+    type: noop
+  - id: ${env:STANZA_TEST_ENV_UNSET:-default_value}
+    type: noop
+  - id: plugin
+    type: substitution_plugin
+    api_key: ${env:STANZA_TEST_ENV_SECRET}_plugin
+`
+	err = ioutil.WriteFile(configFile, []byte(configContents), 0755)
+	require.NoError(t, err)
+
+	config, err := NewConfigFromFile(configFile)
+	require.NoError(t, err)
+	require.Len(t, config.Pipeline, 4)
+	require.Equal(t, "env_secret_value", config.Pipeline[0].ID())
+	require.Equal(t, "file_secret_value", config.Pipeline[1].ID())
+	require.Equal(t, "default_value", config.Pipeline[2].ID())
+
+	operators, err := config.Pipeline.BuildOperators(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.Equal(t, "$.plugin.env_secret_value_plugin", operators[3].ID())
+}
+
+func TestNewConfigWithSubstitutionRedacted(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	t.Setenv("STANZA_TEST_ENV_OUTPUT", "redacted_output_value")
+	configFile := filepath.Join(tempDir, "config.yaml")
+	configContents := `
+pipeline:
+  - type: noop
+    output: ${env:STANZA_TEST_ENV_OUTPUT}
+`
+	err := ioutil.WriteFile(configFile, []byte(configContents), 0755)
+	require.NoError(t, err)
+
+	config, err := NewConfigFromFile(configFile)
+	require.NoError(t, err)
+
+	_, err = config.Pipeline.BuildPipeline(testutil.NewBuildContext(t), nil)
+	require.Error(t, err)
+	require.Equal(t, "operator '$.[REDACTED]' does not exist: {\"operator_id\":\"$.noop\"}", err.Error())
+}
+
+func TestNewConfigWithMissingSubstitution(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	configFile := filepath.Join(tempDir, "config.yaml")
+	configContents := `
+pipeline:
+  - type: noop
+    output: ${env:STANZA_TEST_ENV_UNSET}
+`
+	err := ioutil.WriteFile(configFile, []byte(configContents), 0755)
+	require.NoError(t, err)
+
+	_, err = NewConfigFromFile(configFile)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "environment variable STANZA_TEST_ENV_UNSET is not set")
+}
+
 func TestNewConfigFromGlobs(t *testing.T) {
 	tempDir := testutil.NewTempDir(t)
 	configFile := filepath.Join(tempDir, "config.yaml")
@@ -114,3 +201,37 @@ func TestMergeConfigs(t *testing.T) {
 	config3 := mergeConfigs(&config1, &config2)
 	require.Equal(t, len(config3.Pipeline), 2)
 }
+
+func TestMergeConfigsPipelines(t *testing.T) {
+	config1 := Config{
+		Pipelines: map[string]pipeline.Config{
+			"team_a": {operator.Config{}},
+		},
+	}
+
+	config2 := Config{
+		Pipelines: map[string]pipeline.Config{
+			"team_a": {operator.Config{}},
+			"team_b": {operator.Config{}},
+		},
+	}
+
+	config3 := mergeConfigs(&Config{}, &config1)
+	config3 = mergeConfigs(config3, &config2)
+	require.Len(t, config3.Pipelines["team_a"], 2)
+	require.Len(t, config3.Pipelines["team_b"], 1)
+	require.Len(t, config1.Pipelines["team_a"], 1)
+}
+
+func TestMergeConfigsShutdownTimeout(t *testing.T) {
+	timeout := helper.NewDuration(30 * time.Second)
+	config1 := Config{ShutdownTimeout: &timeout}
+	config2 := Config{}
+
+	config3 := mergeConfigs(&config1, &config2)
+	require.Equal(t, &timeout, config3.ShutdownTimeout)
+
+	override := helper.NewDuration(time.Minute)
+	config4 := mergeConfigs(config3, &Config{ShutdownTimeout: &override})
+	require.Equal(t, &override, config4.ShutdownTimeout)
+}
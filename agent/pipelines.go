@@ -0,0 +1,242 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/pipeline"
+	"go.uber.org/zap"
+)
+
+// pipelineNameRegex matches the valid names of named pipelines
+var pipelineNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// validatePipelineName checks that the name of a named pipeline can be used as a namespace
+func validatePipelineName(name string) error {
+	if !pipelineNameRegex.MatchString(name) {
+		return errors.NewError(
+			fmt.Sprintf("invalid pipeline name `%s`", name),
+			"use a name made of letters, digits, underscores and dashes",
+			"pipeline", name,
+		)
+	}
+	return nil
+}
+
+// buildPipeline will build a named pipeline. The IDs of its operators are namespaced by
+// the name of the pipeline, so they cannot collide with the operators of other pipelines.
+func buildPipeline(name string, cfg pipeline.Config, bc operator.BuildContext) (*pipeline.DirectedPipeline, error) {
+	if err := validatePipelineName(name); err != nil {
+		return nil, err
+	}
+
+	p, err := cfg.BuildPipeline(bc.WithPipeline(name), nil)
+	if err != nil {
+		return nil, errors.WithDetails(err, "pipeline", name)
+	}
+
+	if err := checkOutputs(p.Operators()); err != nil {
+		return nil, errors.WithDetails(err, "pipeline", name)
+	}
+	return p, nil
+}
+
+// checkOutputs returns an error if an operator of a named pipeline has nowhere to send its
+// entries. Unlike the default pipeline, named pipelines have no default output, so an
+// operator without outputs would silently discard every entry.
+func checkOutputs(operators []operator.Operator) error {
+	for _, op := range operators {
+		if op.CanOutput() && len(op.Outputs()) == 0 {
+			return errors.NewError(
+				"operator has no outputs, and named pipelines have no default output",
+				"set the `output` of the last operator of the pipeline, or end the pipeline with an output operator",
+				"operator_id", op.ID(),
+			)
+		}
+	}
+	return nil
+}
+
+// BuildOperators will build the operators of the default pipeline of the config, followed by the
+// operators of each named pipeline, whose IDs are namespaced by the name of their pipeline. The
+// operators are not connected to a default output, and can be connected into a single graph.
+func (c *Config) BuildOperators(bc operator.BuildContext) ([]operator.Operator, error) {
+	operators, err := c.Pipeline.BuildOperators(bc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range pipelineConfigNames(c.Pipelines) {
+		if err := validatePipelineName(name); err != nil {
+			return nil, err
+		}
+
+		ops, err := c.Pipelines[name].BuildOperators(bc.WithPipeline(name))
+		if err != nil {
+			return nil, errors.WithDetails(err, "pipeline", name)
+		}
+		operators = append(operators, ops...)
+	}
+	return operators, nil
+}
+
+// buildPipelines will build each named pipeline of a config. A pipeline that fails to
+// build is left out, and its error is returned instead, so that the others can still run.
+func buildPipelines(configs map[string]pipeline.Config, bc operator.BuildContext) (map[string]pipeline.Pipeline, map[string]error) {
+	pipelines := make(map[string]pipeline.Pipeline, len(configs))
+	pipelineErrors := make(map[string]error)
+	for name, cfg := range configs {
+		p, err := buildPipeline(name, cfg, bc)
+		if err != nil {
+			pipelineErrors[name] = err
+			continue
+		}
+		pipelines[name] = p
+	}
+	return pipelines, pipelineErrors
+}
+
+// pipelineNames returns the names of the named pipelines, sorted
+func pipelineNames(pipelines map[string]pipeline.Pipeline) []string {
+	names := make([]string, 0, len(pipelines))
+	for name := range pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pipelineConfigNames returns the names of the named pipelines of a config, sorted
+func pipelineConfigNames(configs map[string]pipeline.Config) []string {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// allPipelines returns the default pipeline of the agent followed by its named pipelines
+func (a *LogAgent) allPipelines() []pipeline.Pipeline {
+	a.pipelineMux.Lock()
+	defer a.pipelineMux.Unlock()
+
+	pipelines := []pipeline.Pipeline{a.pipeline}
+	for _, name := range pipelineNames(a.pipelines) {
+		pipelines = append(pipelines, a.pipelines[name])
+	}
+	return pipelines
+}
+
+// startPipelines will start each named pipeline of the agent. A pipeline that fails to
+// start is stopped and set aside with its error, without affecting the other pipelines.
+func (a *LogAgent) startPipelines() {
+	for _, name := range pipelineNames(a.pipelines) {
+		p := a.pipelines[name]
+		if err := p.Start(); err != nil {
+			a.Errorw("Failed to start pipeline", "pipeline", name, zap.Any("error", err))
+			_ = p.Stop()
+			delete(a.pipelines, name)
+			a.setPipelineError(name, err)
+		}
+	}
+}
+
+// stopPipelines will stop each named pipeline of the agent, logging the errors
+func (a *LogAgent) stopPipelines() {
+	for _, name := range pipelineNames(a.pipelines) {
+		if err := a.stopPipeline(name, a.pipelines[name]); err != nil {
+			a.Errorw("Failed to stop pipeline", "pipeline", name, zap.Any("error", err))
+		}
+	}
+}
+
+// reloadPipelines will replace the named pipelines of the agent with the pipelines described
+// by the configs. Each pipeline is reloaded on its own, so an invalid config only affects the
+// pipeline it describes. A running pipeline whose new config fails to build is left in place.
+func (a *LogAgent) reloadPipelines(configs map[string]pipeline.Config) {
+	for _, name := range pipelineNames(a.pipelines) {
+		if _, ok := configs[name]; ok {
+			continue
+		}
+		if err := a.stopPipeline(name, a.pipelines[name]); err != nil {
+			a.Errorw("Failed to stop removed pipeline", "pipeline", name, zap.Any("error", err))
+		}
+		delete(a.pipelines, name)
+		a.Infow("Removed pipeline", "pipeline", name)
+	}
+
+	for name := range a.pipelineErrors {
+		if _, ok := configs[name]; !ok {
+			delete(a.pipelineErrors, name)
+		}
+	}
+
+	for _, name := range pipelineConfigNames(configs) {
+		if err := a.reloadPipeline(name, configs[name]); err != nil {
+			a.Errorw("Failed to reload pipeline", "pipeline", name, zap.Any("error", err))
+		}
+	}
+}
+
+// reloadPipeline will reload a single named pipeline, or build and start
+// it if it is not running, like a pipeline that was added to the config
+func (a *LogAgent) reloadPipeline(name string, cfg pipeline.Config) error {
+	if a.pipelines == nil {
+		a.pipelines = make(map[string]pipeline.Pipeline)
+	}
+
+	current, ok := a.pipelines[name]
+	if !ok {
+		next, err := buildPipeline(name, cfg, a.buildContext)
+		if err != nil {
+			a.setPipelineError(name, err)
+			return err
+		}
+
+		if err := next.Start(); err != nil {
+			_ = next.Stop()
+			a.setPipelineError(name, err)
+			return err
+		}
+
+		a.pipelines[name] = next
+		delete(a.pipelineErrors, name)
+		a.Infow("Started pipeline", "pipeline", name)
+		return nil
+	}
+
+	running, ok := current.(*pipeline.DirectedPipeline)
+	if !ok {
+		return errors.NewError(
+			"pipeline cannot be reloaded, because it does not support reloading",
+			"this is an unexpected internal error",
+			"pipeline", name,
+		)
+	}
+
+	// The new config is checked before it replaces the running pipeline
+	if _, err := buildPipeline(name, cfg, a.buildContext); err != nil {
+		return err
+	}
+
+	next, err := cfg.Reload(a.buildContext.WithPipeline(name), nil, running)
+	if next != nil {
+		a.pipelines[name] = next
+	}
+	if err != nil {
+		return errors.WithDetails(err, "pipeline", name)
+	}
+	return nil
+}
+
+// setPipelineError records the error that prevents a named pipeline from running
+func (a *LogAgent) setPipelineError(name string, err error) {
+	if a.pipelineErrors == nil {
+		a.pipelineErrors = make(map[string]error)
+	}
+	a.pipelineErrors[name] = err
+}
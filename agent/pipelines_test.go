@@ -0,0 +1,193 @@
+package agent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/pipeline"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestValidatePipelineName(t *testing.T) {
+	for _, name := range []string{"team_a", "team-b", "Team1"} {
+		require.NoError(t, validatePipelineName(name), name)
+	}
+	for _, name := range []string{"", "team.a", "$.team", "team a"} {
+		require.Error(t, validatePipelineName(name), name)
+	}
+}
+
+func TestNamedPipelines(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	configFile := filepath.Join(tempDir, "config.yaml")
+	err := ioutil.WriteFile(configFile, []byte(`
+pipeline:
+  - id: first
+    type: noop
+pipelines:
+  team_a:
+    - id: first
+      type: noop
+      output: missing
+  team_b:
+    - id: first
+      type: noop
+    - type: drop_output
+  team_c:
+    - id: first
+      type: noop
+`), 0600)
+	require.NoError(t, err)
+
+	agent, err := NewBuilder(zap.NewNop().Sugar()).
+		WithConfigFiles([]string{configFile}).
+		WithDefaultOutput(testutil.NewFakeOutput(t)).
+		Build()
+	require.NoError(t, err)
+
+	require.NoError(t, agent.Start())
+	defer agent.Stop()
+
+	ids := make([]string, 0)
+	for _, info := range agent.Operators() {
+		ids = append(ids, info.ID)
+	}
+	require.Equal(t, []string{"$.fake", "$.first", "$.team_b.drop_output", "$.team_b.first"}, ids)
+
+	result := agent.Ready()
+	require.False(t, result.OK)
+	require.Contains(t, result.Pipelines, "team_a")
+	require.NotContains(t, result.Pipelines, "team_b")
+	require.Contains(t, result.Pipelines["team_a"], "$.team_a.missing")
+
+	// A named pipeline has no default output, so an operator without outputs is rejected
+	require.Contains(t, result.Pipelines, "team_c")
+	require.Contains(t, result.Pipelines["team_c"], "operator has no outputs")
+}
+
+func TestNamedPipelinesStartFailure(t *testing.T) {
+	failure := fmt.Errorf("failed to start")
+	failing := &testutil.Pipeline{}
+	failing.On("Start").Return(failure)
+	failing.On("Stop").Return(nil)
+	failing.On("Operators").Return([]operator.Operator{})
+
+	running := &testutil.Pipeline{}
+	running.On("Start").Return(nil)
+	running.On("Stop").Return(nil)
+	running.On("Operators").Return([]operator.Operator{})
+
+	defaultPipeline := &testutil.Pipeline{}
+	defaultPipeline.On("Start").Return(nil)
+	defaultPipeline.On("Stop").Return(nil)
+	defaultPipeline.On("Operators").Return([]operator.Operator{})
+
+	database := &testutil.Database{}
+	database.On("Close").Return(nil)
+
+	agent := LogAgent{
+		SugaredLogger: zap.NewNop().Sugar(),
+		pipeline:      defaultPipeline,
+		pipelines: map[string]pipeline.Pipeline{
+			"failing": failing,
+			"running": running,
+		},
+		database: database,
+	}
+
+	require.NoError(t, agent.Start())
+	failing.AssertCalled(t, "Stop")
+	running.AssertCalled(t, "Start")
+
+	result := agent.Ready()
+	require.False(t, result.OK)
+	require.Equal(t, map[string]string{"failing": failure.Error()}, result.Pipelines)
+
+	require.NoError(t, agent.Stop())
+	running.AssertCalled(t, "Stop")
+	defaultPipeline.AssertCalled(t, "Stop")
+}
+
+func TestReloadNamedPipelines(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	configFile := filepath.Join(tempDir, "config.yaml")
+	writeConfig := func(contents string) {
+		err := ioutil.WriteFile(configFile, []byte(contents), 0600)
+		require.NoError(t, err)
+	}
+
+	writeConfig(`
+pipelines:
+  team_a:
+    - id: first
+      type: noop
+    - type: drop_output
+  team_b:
+    - id: first
+      type: noop
+    - type: drop_output
+`)
+
+	agent, err := NewBuilder(zap.NewNop().Sugar()).
+		WithConfigFiles([]string{configFile}).
+		Build()
+	require.NoError(t, err)
+	require.NoError(t, agent.Start())
+	defer agent.Stop()
+
+	operators := func() map[string]operator.Operator {
+		ops := make(map[string]operator.Operator)
+		for _, info := range agent.Operators() {
+			op, ok := agent.findOperator(info.ID)
+			require.True(t, ok)
+			ops[info.ID] = op
+		}
+		return ops
+	}
+	before := operators()
+
+	// A broken team_a config leaves the running team_a pipeline in place, and doesn't affect team_b
+	writeConfig(`
+pipelines:
+  team_a:
+    - id: first
+      type: noop
+      output: missing
+  team_b:
+    - id: first
+      type: noop
+      if: 'true'
+    - type: drop_output
+  team_c:
+    - id: first
+      type: noop
+    - type: drop_output
+`)
+	require.NoError(t, agent.Reload())
+	after := operators()
+	require.Same(t, before["$.team_a.first"], after["$.team_a.first"])
+	require.NotSame(t, before["$.team_b.first"], after["$.team_b.first"])
+	require.Same(t, before["$.team_b.drop_output"], after["$.team_b.drop_output"])
+	require.Contains(t, after, "$.team_c.first")
+	require.True(t, agent.Ready().OK)
+
+	// A removed pipeline is stopped
+	writeConfig(`
+pipelines:
+  team_b:
+    - id: first
+      type: noop
+      if: 'true'
+    - type: drop_output
+`)
+	require.NoError(t, agent.Reload())
+	after = operators()
+	require.NotContains(t, after, "$.team_a.first")
+	require.NotContains(t, after, "$.team_c.first")
+	require.Contains(t, after, "$.team_b.first")
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+// tapBufferSize is the number of entries a tap holds for a slow client before it drops entries
+const tapBufferSize = 1000
+
+// entryTap is a tap that sends copies of the entries it observes to a channel. Entries
+// that do not match its expression, or are not sampled, are ignored.
+type entryTap struct {
+	dropped int64
+
+	expression *vm.Program
+	sample     float64
+	entries    chan *entry.Entry
+}
+
+// newEntryTap creates a tap from the `expr` and `sample` parameters of a tap request
+func newEntryTap(query url.Values) (*entryTap, error) {
+	tap := &entryTap{
+		sample:  1,
+		entries: make(chan *entry.Entry, tapBufferSize),
+	}
+
+	if expression := query.Get("expr"); expression != "" {
+		compiled, err := expr.Compile(expression, expr.AsBool(), expr.AllowUndefinedVariables())
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile expression '%s': %s", expression, err)
+		}
+		tap.expression = compiled
+	}
+
+	if sample := query.Get("sample"); sample != "" {
+		rate, err := strconv.ParseFloat(sample, 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			return nil, fmt.Errorf("sample must be a number greater than 0 and at most 1")
+		}
+		tap.sample = rate
+	}
+
+	return tap, nil
+}
+
+// Observe will send a copy of the entry to the channel of the tap, if it matches and is sampled
+func (t *entryTap) Observe(e *entry.Entry) {
+	if t.expression != nil {
+		env := helper.GetExprEnv(e)
+		matches, err := vm.Run(t.expression, env)
+		helper.PutExprEnv(env)
+		if matched, ok := matches.(bool); err != nil || !ok || !matched {
+			return
+		}
+	}
+
+	// #nosec G404 - sampling does not need a secure random number
+	if t.sample < 1 && rand.Float64() >= t.sample {
+		return
+	}
+
+	select {
+	case t.entries <- e.Copy():
+	default:
+		atomic.AddInt64(&t.dropped, 1)
+	}
+}
+
+// tappableOperator returns the operator that writes the entries flowing out of an operator,
+// which is the operator behind a queue if it has one
+func tappableOperator(op operator.Operator) (operator.Tappable, bool) {
+	op = operator.Unwrap(op)
+	tappable, ok := op.(operator.Tappable)
+	if !ok || !op.CanOutput() {
+		return nil, false
+	}
+	return tappable, true
+}
+
+// tapHandler will attach a tap to an operator in the pipeline, and stream the entries
+// it writes as newline delimited json until the request is canceled
+func (a *LogAgent) tapHandler(w http.ResponseWriter, r *http.Request) {
+	operatorID := r.PathValue("id")
+	op, ok := a.findOperator(operatorID)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, adminError{fmt.Sprintf("operator '%s' does not exist", operatorID)})
+		return
+	}
+
+	tappable, ok := tappableOperator(op)
+	if !ok {
+		writeJSON(w, http.StatusConflict, adminError{fmt.Sprintf("operator '%s' does not write entries to other operators", operatorID)})
+		return
+	}
+
+	tap, err := newEntryTap(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, adminError{err.Error()})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, adminError{"streaming is not supported"})
+		return
+	}
+
+	tappable.AttachTap(tap)
+	a.Infow("Attached tap", "operator_id", operatorID)
+	defer func() {
+		tappable.DetachTap(tap)
+		a.Infow("Detached tap", "operator_id", operatorID, "dropped", atomic.LoadInt64(&tap.dropped))
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-tap.entries:
+			if err := encoder.Encode(e); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/builtin/transformer/noop"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewEntryTap(t *testing.T) {
+	cases := []struct {
+		name  string
+		query url.Values
+		valid bool
+	}{
+		{"Default", url.Values{}, true},
+		{"Expression", url.Values{"expr": {`$record.level == "error"`}}, true},
+		{"InvalidExpression", url.Values{"expr": {`$record.level ==`}}, false},
+		{"Sample", url.Values{"sample": {"0.5"}}, true},
+		{"SampleZero", url.Values{"sample": {"0"}}, false},
+		{"SampleTooLarge", url.Values{"sample": {"1.5"}}, false},
+		{"SampleInvalid", url.Values{"sample": {"half"}}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newEntryTap(tc.query)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestEntryTapObserve(t *testing.T) {
+	tap, err := newEntryTap(url.Values{"expr": {`$record.level == "error"`}})
+	require.NoError(t, err)
+
+	e := entry.New()
+	e.Record = map[string]interface{}{"level": "error"}
+	tap.Observe(e)
+	tap.Observe(&entry.Entry{Record: map[string]interface{}{"level": "info"}})
+	tap.Observe(&entry.Entry{Record: "not a map"})
+	require.Len(t, tap.entries, 1)
+
+	// The tap receives a copy, so the entry can be modified by the outputs
+	observed := <-tap.entries
+	e.Record.(map[string]interface{})["level"] = "changed"
+	require.Equal(t, map[string]interface{}{"level": "error"}, observed.Record)
+
+	// Entries are dropped rather than blocking the pipeline when the tap is full
+	e.Record = map[string]interface{}{"level": "error"}
+	for i := 0; i < tapBufferSize+5; i++ {
+		tap.Observe(e)
+	}
+	require.Len(t, tap.entries, tapBufferSize)
+	require.Equal(t, int64(5), tap.dropped)
+}
+
+func TestAdminTap(t *testing.T) {
+	cfg := noop.NewNoopOperatorConfig("noop")
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	pipeline := &testutil.Pipeline{}
+	pipeline.On("Operators").Return([]operator.Operator{op, fake})
+
+	agent := &LogAgent{
+		SugaredLogger: zap.NewNop().Sugar(),
+		pipeline:      pipeline,
+	}
+	server := httptest.NewServer(agent.adminHandler())
+	defer server.Close()
+
+	t.Run("Stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		query := url.Values{"expr": {`$record == "match"`}}.Encode()
+		request, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/operators/$.noop/tap?"+query, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		for _, record := range []string{"skip", "match"} {
+			require.NoError(t, op.Process(context.Background(), &entry.Entry{Record: record}))
+			fake.ExpectRecord(t, record)
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		require.True(t, scanner.Scan())
+		var streamed entry.Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &streamed))
+		require.Equal(t, "match", streamed.Record)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/operators/$.missing/tap")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("NotTappable", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/operators/$.fake/tap")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	t.Run("InvalidQuery", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/operators/$.noop/tap?sample=2")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/pipeline"
+	"github.com/observiq/stanza/secret"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ValidateConfigFiles will read the config files matching the globs and build the pipeline they
+// describe with the supplied build context, without starting it. It returns every error it finds
+// rather than only the first. Each error has a `file` detail naming the config file where it
+// occurred and an `operator_id` detail naming the operator, when they are known.
+func ValidateConfigFiles(globs []string, bc operator.BuildContext) []error {
+	paths, err := expandGlobs(globs)
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := make([]error, 0)
+	config := &Config{}
+	files := make(map[string]string)
+	unreadPipelines := make(map[string]bool)
+	for _, path := range paths {
+		fileConfig, fileErrs := readConfigFile(path, bc)
+		for _, err := range fileErrs {
+			errs = append(errs, errors.WithDetails(err, "file", path))
+			if agentErr, ok := err.(errors.AgentError); ok && agentErr.Details["pipeline"] != "" {
+				unreadPipelines[agentErr.Details["pipeline"]] = true
+			}
+		}
+
+		for _, op := range fileConfig.Pipeline {
+			files[bc.PrependNamespace(op.ID())] = path
+		}
+		for name, namedPipeline := range fileConfig.Pipelines {
+			for _, op := range namedPipeline {
+				files[bc.WithPipeline(name).PrependNamespace(op.ID())] = path
+			}
+		}
+		config = mergeConfigs(config, fileConfig)
+	}
+
+	for _, err := range config.Pipeline.Validate(bc) {
+		errs = append(errs, withFile(err, files))
+	}
+
+	for _, name := range pipelineConfigNames(config.Pipelines) {
+		if err := validatePipelineName(name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// The outputs are only checked once every operator of the pipeline could be read and built,
+		// since an operator that is left out may be the output of the operator before it
+		pipelineErrs := config.Pipelines[name].Validate(bc.WithPipeline(name))
+		if len(pipelineErrs) == 0 && !unreadPipelines[name] {
+			if _, err := buildPipeline(name, config.Pipelines[name], bc); err != nil {
+				pipelineErrs = append(pipelineErrs, err)
+			}
+		}
+		for _, err := range pipelineErrs {
+			errs = append(errs, withFile(errors.WithDetails(err, "pipeline", name), files))
+		}
+	}
+
+	return errs
+}
+
+// readConfigFile will read a config file. If the file is invalid, the errors of each of its
+// operators are returned, along with the operators that are valid so they can still be checked.
+func readConfigFile(path string, bc operator.BuildContext) (*Config, []error) {
+	contents, err := ioutil.ReadFile(path) // #nosec - configs load based on user specified directory
+	if err != nil {
+		return &Config{}, []error{errors.Wrap(err, "read config file")}
+	}
+
+	contents, err = secret.Expand(contents)
+	if err != nil {
+		return &Config{}, []error{errors.Wrap(err, "substitute values in config file")}
+	}
+
+	config := &Config{}
+	fileErr := yaml.UnmarshalStrict(contents, config)
+	if fileErr == nil {
+		return config, nil
+	}
+
+	var raw struct {
+		Pipeline  []interface{}            `yaml:"pipeline"`
+		Pipelines map[string][]interface{} `yaml:"pipelines"`
+	}
+	if err := yaml.Unmarshal(contents, &raw); err != nil {
+		return &Config{}, []error{errors.Wrap(fileErr, "failed to read config file as yaml")}
+	}
+
+	ops, errs := readRawOperators(raw.Pipeline, bc)
+	config = &Config{Pipeline: ops}
+	rawNames := make([]string, 0, len(raw.Pipelines))
+	for name := range raw.Pipelines {
+		rawNames = append(rawNames, name)
+	}
+	sort.Strings(rawNames)
+
+	for _, name := range rawNames {
+		ops, opErrs := readRawOperators(raw.Pipelines[name], bc.WithPipeline(name))
+		for _, err := range opErrs {
+			errs = append(errs, errors.WithDetails(err, "pipeline", name))
+		}
+		if config.Pipelines == nil {
+			config.Pipelines = make(map[string]pipeline.Config)
+		}
+		config.Pipelines[name] = ops
+	}
+
+	// The error is not caused by an operator, like an unknown top level field
+	if len(errs) == 0 {
+		errs = append(errs, errors.Wrap(fileErr, "failed to read config file as yaml"))
+	}
+
+	return config, errs
+}
+
+// readRawOperators will read the operator configs from the raw yaml of a pipeline. The errors of
+// the operators that are invalid are returned, along with the operators that are valid.
+func readRawOperators(rawOperators []interface{}, bc operator.BuildContext) (pipeline.Config, []error) {
+	ops := make(pipeline.Config, 0, len(rawOperators))
+	errs := make([]error, 0)
+	for i, rawOperator := range rawOperators {
+		var op operator.Config
+		if err := unmarshalRawOperator(rawOperator, &op); err != nil {
+			id, ok := rawOperatorID(rawOperator)
+			if !ok {
+				errs = append(errs, errors.WithDetails(errors.Wrap(err, "read operator config"), "index", fmt.Sprint(i)))
+				continue
+			}
+			errs = append(errs, errors.WithDetails(errors.Wrap(err, "read operator config"), "operator_id", bc.PrependNamespace(id)))
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops, errs
+}
+
+// unmarshalRawOperator will unmarshal the raw yaml of a single operator into an operator config
+func unmarshalRawOperator(rawOperator interface{}, op *operator.Config) error {
+	contents, err := yaml.Marshal(rawOperator)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(contents, op)
+}
+
+// rawOperatorID returns the ID of an operator from its raw yaml, which defaults to its type
+func rawOperatorID(rawOperator interface{}) (string, bool) {
+	fields, ok := rawOperator.(map[interface{}]interface{})
+	if !ok {
+		return "", false
+	}
+
+	for _, key := range []string{"id", "type"} {
+		if id, ok := fields[key].(string); ok && id != "" {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// withFile will add a `file` detail to an error that names an operator defined in one of the files
+func withFile(err error, files map[string]string) error {
+	agentErr, ok := err.(errors.AgentError)
+	if !ok {
+		return err
+	}
+
+	for _, key := range []string{"operator_id", "input_operator"} {
+		if file, ok := findFile(agentErr.Details[key], files); ok {
+			return errors.WithDetails(agentErr, "file", file)
+		}
+	}
+	return err
+}
+
+// findFile will find the file where an operator is defined. Operators built
+// by plugins are found by the ID of the plugin that contains them.
+func findFile(operatorID string, files map[string]string) (string, bool) {
+	for operatorID != "" {
+		if file, ok := files[operatorID]; ok {
+			return file, true
+		}
+
+		i := strings.LastIndex(operatorID, ".")
+		if i < 0 {
+			break
+		}
+		operatorID = operatorID[:i]
+	}
+	return "", false
+}
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/observiq/stanza/errors"
+	_ "github.com/observiq/stanza/operator/builtin/input/generate"
+	_ "github.com/observiq/stanza/operator/builtin/output/drop"
+	_ "github.com/observiq/stanza/operator/builtin/parser/regex"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func writeValidateTestFile(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func requireErrorDetails(t *testing.T, err error, description string, details map[string]string) {
+	agentErr, ok := err.(errors.AgentError)
+	require.True(t, ok, "expected an agent error, got %T: %s", err, err)
+	require.Contains(t, agentErr.Description, description)
+	for key, value := range details {
+		require.Equal(t, value, agentErr.Details[key], "detail %s", key)
+	}
+}
+
+func TestValidateConfigFiles(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		dir := testutil.NewTempDir(t)
+		writeValidateTestFile(t, dir, "config.yaml", `
+pipeline:
+  - type: generate_input
+  - type: regex_parser
+    regex: '^(?P<message>.*)$'
+  - type: drop_output
+`)
+		errs := ValidateConfigFiles([]string{filepath.Join(dir, "*.yaml")}, testutil.NewBuildContext(t))
+		require.Empty(t, errs)
+	})
+
+	t.Run("NoFiles", func(t *testing.T) {
+		dir := testutil.NewTempDir(t)
+		errs := ValidateConfigFiles([]string{filepath.Join(dir, "*.yaml")}, testutil.NewBuildContext(t))
+		require.Len(t, errs, 1)
+	})
+
+	t.Run("AllErrors", func(t *testing.T) {
+		dir := testutil.NewTempDir(t)
+		first := writeValidateTestFile(t, dir, "a.yaml", `
+pipeline:
+  - type: generate_input
+    output: parser
+  - id: unknown
+    type: unknown_type
+  - id: parser
+    type: regex_parser
+    regex: '^(?P<message>.*)$'
+    output: missing
+`)
+		second := writeValidateTestFile(t, dir, "b.yaml", `
+pipeline:
+  - id: invalid_regex
+    type: regex_parser
+    regex: '('
+  - type: drop_output
+    unknown_field: true
+`)
+
+		errs := ValidateConfigFiles([]string{filepath.Join(dir, "*.yaml")}, testutil.NewBuildContext(t))
+		require.Len(t, errs, 4, "%v", errs)
+		requireErrorDetails(t, errs[0], "unsupported type 'unknown_type'", map[string]string{"file": first, "operator_id": "$.unknown"})
+		requireErrorDetails(t, errs[1], "field unknown_field not found", map[string]string{"file": second, "operator_id": "$.drop_output"})
+		requireErrorDetails(t, errs[2], "compiling regex", map[string]string{"file": second, "operator_id": "$.invalid_regex"})
+		requireErrorDetails(t, errs[3], "operator '$.missing' does not exist", map[string]string{"file": first, "operator_id": "$.parser"})
+	})
+
+	t.Run("NamedPipelines", func(t *testing.T) {
+		dir := testutil.NewTempDir(t)
+		path := writeValidateTestFile(t, dir, "config.yaml", `
+pipelines:
+  team_a:
+    - type: generate_input
+      output: missing
+  team_b:
+    - type: generate_input
+    - type: drop_output
+      unknown_field: true
+  team.c:
+    - type: drop_output
+`)
+		errs := ValidateConfigFiles([]string{path}, testutil.NewBuildContext(t))
+		require.Len(t, errs, 3, "%v", errs)
+		requireErrorDetails(t, errs[0], "field unknown_field not found", map[string]string{"file": path, "operator_id": "$.team_b.drop_output", "pipeline": "team_b"})
+		requireErrorDetails(t, errs[1], "invalid pipeline name", map[string]string{"pipeline": "team.c"})
+		requireErrorDetails(t, errs[2], "operator '$.team_a.missing' does not exist", map[string]string{"file": path, "operator_id": "$.team_a.generate_input", "pipeline": "team_a"})
+	})
+
+	t.Run("NamedPipelineWithoutOutput", func(t *testing.T) {
+		dir := testutil.NewTempDir(t)
+		path := writeValidateTestFile(t, dir, "config.yaml", `
+pipeline:
+  - type: noop
+pipelines:
+  team_a:
+    - type: generate_input
+    - type: noop
+`)
+		errs := ValidateConfigFiles([]string{path}, testutil.NewBuildContext(t))
+		require.Len(t, errs, 1, "%v", errs)
+		requireErrorDetails(t, errs[0], "operator has no outputs", map[string]string{"file": path, "operator_id": "$.team_a.noop", "pipeline": "team_a"})
+	})
+
+	t.Run("InvalidTopLevelField", func(t *testing.T) {
+		dir := testutil.NewTempDir(t)
+		path := writeValidateTestFile(t, dir, "config.yaml", `
+pipline:
+  - type: drop_output
+`)
+		errs := ValidateConfigFiles([]string{path}, testutil.NewBuildContext(t))
+		require.Len(t, errs, 1)
+		requireErrorDetails(t, errs[0], "failed to read config file as yaml", map[string]string{"file": path})
+	})
+}
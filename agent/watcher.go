@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// startConfigWatcher kicks off a goroutine that will poll the agent's config files
+// periodically, reloading the agent when any of them are added, removed or modified
+func (a *LogAgent) startConfigWatcher(ctx context.Context) {
+	lastState, err := configFilesState(a.configFiles)
+	if err != nil {
+		a.Warnw("Failed to read config files state", zap.Error(err))
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.reloadInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			state, err := configFilesState(a.configFiles)
+			if err != nil {
+				a.Warnw("Failed to read config files state", zap.Error(err))
+				continue
+			}
+
+			if state == lastState {
+				continue
+			}
+			lastState = state
+
+			a.Info("Detected config change, reloading agent")
+			if err := a.Reload(); err != nil {
+				a.Errorw("Failed to reload agent, keeping the running pipeline", zap.Any("error", err))
+				continue
+			}
+			a.Info("Reloaded agent")
+		}
+	}()
+}
+
+// configFilesState returns a summary of the paths, sizes and modification times
+// of the files matching the globs, which changes whenever one of the files does
+func configFilesState(globs []string) (string, error) {
+	paths := make([]string, 0, len(globs))
+	for _, glob := range globs {
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return "", err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var state strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&state, "%s:%d:%d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return state.String(), nil
+}
@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFilesState(t *testing.T) {
+	tempDir := testutil.NewTempDir(t)
+	globs := []string{filepath.Join(tempDir, "*.yaml")}
+
+	empty, err := configFilesState(globs)
+	require.NoError(t, err)
+	require.Equal(t, "", empty)
+
+	err = ioutil.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte("pipeline:\n"), 0600)
+	require.NoError(t, err)
+	added, err := configFilesState(globs)
+	require.NoError(t, err)
+	require.NotEqual(t, empty, added)
+
+	unchanged, err := configFilesState(globs)
+	require.NoError(t, err)
+	require.Equal(t, added, unchanged)
+
+	err = ioutil.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte("pipeline: []\n"), 0600)
+	require.NoError(t, err)
+	modified, err := configFilesState(globs)
+	require.NoError(t, err)
+	require.NotEqual(t, added, modified)
+}
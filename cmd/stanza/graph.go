@@ -6,7 +6,9 @@ import (
 	"github.com/observiq/stanza/agent"
 	"github.com/observiq/stanza/database"
 	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/pipeline"
 	"github.com/observiq/stanza/plugin"
+	"github.com/observiq/stanza/secret"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -42,20 +44,27 @@ func runGraph(_ *cobra.Command, _ []string, flags *RootFlags) {
 		logger.Errorw("Got errors parsing parsing", "errors", err)
 	}
 
+	// The default pipeline and the named pipelines are rendered as a single graph
 	buildContext := operator.NewBuildContext(database.NewStubDatabase(), logger)
-	pipeline, err := cfg.Pipeline.BuildPipeline(buildContext, nil)
+	operators, err := cfg.BuildOperators(buildContext)
+	if err != nil {
+		logger.Errorw("Failed to build operators", zap.Any("error", err))
+		os.Exit(1)
+	}
+
+	directedPipeline, err := pipeline.NewDirectedPipeline(operators)
 	if err != nil {
 		logger.Errorw("Failed to build operator pipeline", zap.Any("error", err))
 		os.Exit(1)
 	}
 
-	dotGraph, err := pipeline.Render()
+	dotGraph, err := directedPipeline.Render()
 	if err != nil {
 		logger.Errorw("Failed to marshal dot graph", zap.Any("error", err))
 		os.Exit(1)
 	}
 
-	dotGraph = append(dotGraph, '\n')
+	dotGraph = append([]byte(secret.Redact(string(dotGraph))), '\n')
 	_, err = stdout.Write(dotGraph)
 	if err != nil {
 		logger.Errorw("Failed to write dot graph to stdout", zap.Any("error", err))
@@ -191,3 +191,35 @@ pipeline:
 
 	graphTest(config, expected)(t)
 }
+
+func TestGraphNamedPipelines(t *testing.T) {
+	config := `
+pipeline:
+  - id: generate
+    type: generate_input
+    output: stdout
+  - id: stdout
+    type: stdout
+pipelines:
+  team_a:
+    - id: generate
+      type: generate_input
+    - id: drop
+      type: drop_output
+`
+
+	expected := `
+    strict digraph G {
+      // Node definitions.
+      "$.generate";
+      "$.team_a.drop";
+      "$.team_a.generate";
+      "$.stdout";
+
+      // Edge definitions.
+      "$.generate" -> "$.stdout";
+      "$.team_a.generate" -> "$.team_a.drop";
+    }`
+
+	graphTest(config, expected)(t)
+}
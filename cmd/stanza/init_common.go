@@ -23,24 +23,31 @@ import (
 	_ "github.com/observiq/stanza/operator/builtin/parser/severity"
 	_ "github.com/observiq/stanza/operator/builtin/parser/syslog"
 	_ "github.com/observiq/stanza/operator/builtin/parser/time"
+	_ "github.com/observiq/stanza/operator/builtin/parser/trace"
 	_ "github.com/observiq/stanza/operator/builtin/parser/uri"
 	_ "github.com/observiq/stanza/operator/builtin/parser/xml"
 
 	_ "github.com/observiq/stanza/operator/builtin/transformer/add"
+	_ "github.com/observiq/stanza/operator/builtin/transformer/aggregate"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/copy"
+	_ "github.com/observiq/stanza/operator/builtin/transformer/dedup"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/filter"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/flatten"
+	_ "github.com/observiq/stanza/operator/builtin/transformer/geoip"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/hostmetadata"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/k8smetadata"
+	_ "github.com/observiq/stanza/operator/builtin/transformer/lookup"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/metadata"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/move"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/noop"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/ratelimit"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/recombine"
+	_ "github.com/observiq/stanza/operator/builtin/transformer/redact"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/remove"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/restructure"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/retain"
 	_ "github.com/observiq/stanza/operator/builtin/transformer/router"
+	_ "github.com/observiq/stanza/operator/builtin/transformer/sample"
 
 	_ "github.com/observiq/stanza/operator/builtin/output/count"
 	_ "github.com/observiq/stanza/operator/builtin/output/drop"
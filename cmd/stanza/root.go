@@ -15,6 +15,7 @@ import (
 	"time"
 
 	agent "github.com/observiq/stanza/agent"
+	"github.com/observiq/stanza/metrics"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -29,6 +30,8 @@ type RootFlags struct {
 	DatabaseFile       string
 	ConfigFiles        []string
 	PluginDir          string
+	ReloadInterval     time.Duration
+	MetricsPort        int
 	PprofPort          int
 	CPUProfile         string
 	CPUProfileDuration time.Duration
@@ -66,6 +69,9 @@ func NewRootCmd() *cobra.Command {
 	rootFlagSet.StringSliceVarP(&rootFlags.ConfigFiles, "config", "c", []string{defaultConfig()}, "path to a config file")
 	rootFlagSet.StringVar(&rootFlags.PluginDir, "plugin_dir", defaultPluginDir(), "path to the plugin directory")
 	rootFlagSet.StringVar(&rootFlags.DatabaseFile, "database", "", "path to the stanza offset database")
+	rootFlagSet.DurationVar(&rootFlags.ReloadInterval, "reload_interval", 0, "how often to check config files for changes and reload the agent (0 disables)")
+
+	rootFlagSet.IntVar(&rootFlags.MetricsPort, "metrics_port", 0, "listen port for serving prometheus metrics on /metrics (0 disables)")
 
 	// Profiling flags
 	rootFlagSet.IntVar(&rootFlags.PprofPort, "pprof_port", 0, "listen port for pprof profiling")
@@ -87,6 +93,9 @@ func NewRootCmd() *cobra.Command {
 	root.AddCommand(NewGraphCommand(rootFlags))
 	root.AddCommand(NewVersionCommand())
 	root.AddCommand(NewOffsetsCmd(rootFlags))
+	root.AddCommand(NewValidateCommand(rootFlags))
+	root.AddCommand(NewTestCommand(rootFlags))
+	root.AddCommand(NewTapCommand(rootFlags))
 
 	return root
 }
@@ -111,6 +120,7 @@ func runRoot(command *cobra.Command, _ []string, flags *RootFlags) {
 		WithConfigFiles(flags.ConfigFiles).
 		WithPluginDir(flags.PluginDir).
 		WithDatabaseFile(flags.DatabaseFile).
+		WithReloadInterval(flags.ReloadInterval).
 		Build()
 	if err != nil {
 		logger.Errorw("Failed to build agent", zap.Any("error", err))
@@ -125,6 +135,7 @@ func runRoot(command *cobra.Command, _ []string, flags *RootFlags) {
 	}
 
 	profilingWg := startProfiling(ctx, flags, logger)
+	metricsWg := startMetrics(ctx, flags, logger)
 
 	err = service.Run()
 	if err != nil {
@@ -133,6 +144,43 @@ func runRoot(command *cobra.Command, _ []string, flags *RootFlags) {
 	}
 
 	profilingWg.Wait()
+	metricsWg.Wait()
+}
+
+func startMetrics(ctx context.Context, flags *RootFlags, logger *zap.SugaredLogger) *sync.WaitGroup {
+	wg := &sync.WaitGroup{}
+	if flags.MetricsPort == 0 {
+		return wg
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	srv := http.Server{
+		Addr:              fmt.Sprintf(":%d", flags.MetricsPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Errorw("Metrics server failed", zap.Error(err))
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Warnw("Errored shutting down metrics server", zap.Error(err))
+		}
+	}()
+
+	return wg
 }
 
 func startProfiling(ctx context.Context, flags *RootFlags, logger *zap.SugaredLogger) *sync.WaitGroup {
@@ -44,6 +44,16 @@ func (a *AgentService) Stop(_ service.Service) error {
 	return nil
 }
 
+// reloadAgent will reload the stanza agent, keeping the running pipeline if it fails.
+func reloadAgent(agent *agent.LogAgent) {
+	agent.Info("Reloading stanza agent")
+	if err := agent.Reload(); err != nil {
+		agent.Errorw("Failed to reload stanza agent, keeping the running pipeline", zap.Any("error", err))
+		return
+	}
+	agent.Info("Stanza agent reloaded")
+}
+
 // newAgentService creates a new agent service with the provided agent.
 func newAgentService(ctx context.Context, agent *agent.LogAgent, cancel context.CancelFunc) (service.Service, error) {
 	agentService := &AgentService{cancel, agent}
@@ -54,10 +64,17 @@ func newAgentService(ctx context.Context, agent *agent.LogAgent, cancel context.
 		Option: service.KeyValue{
 			"RunWait": func() {
 				var sigChan = make(chan os.Signal, 3)
-				signal.Notify(sigChan, syscall.SIGTERM, os.Interrupt)
-				select {
-				case <-sigChan:
-				case <-ctx.Done():
+				signal.Notify(sigChan, syscall.SIGTERM, os.Interrupt, syscall.SIGHUP)
+				for {
+					select {
+					case sig := <-sigChan:
+						if sig == syscall.SIGHUP {
+							reloadAgent(agent)
+							continue
+						}
+					case <-ctx.Done():
+					}
+					return
 				}
 			},
 		},
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/observiq/stanza/agent"
+	"github.com/spf13/cobra"
+)
+
+// TapFlags are the flags that can be supplied when running the tap command
+type TapFlags struct {
+	*RootFlags
+
+	Operator string
+	Address  string
+	Expr     string
+	Sample   float64
+}
+
+// NewTapCommand creates a command for streaming the entries written by an operator of a running agent
+func NewTapCommand(rootFlags *RootFlags) *cobra.Command {
+	tapFlags := &TapFlags{
+		RootFlags: rootFlags,
+	}
+
+	tap := &cobra.Command{
+		Use:           "tap --operator <id>",
+		Args:          cobra.NoArgs,
+		Short:         "Stream the entries written by an operator of a running agent",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          func(command *cobra.Command, args []string) error { return runTap(command, args, tapFlags) },
+	}
+
+	tapFlagSet := tap.Flags()
+	tapFlagSet.StringVar(&tapFlags.Operator, "operator", "", "the id of the operator whose entries are streamed")
+	tapFlagSet.StringVar(&tapFlags.Address, "address", "", "the address of the agent's admin listener (defaults to the admin listen_address of the config)")
+	tapFlagSet.StringVar(&tapFlags.Expr, "expr", "", "an expression that entries must match to be streamed")
+	tapFlagSet.Float64Var(&tapFlags.Sample, "sample", 1, "the fraction of entries to stream, between 0 and 1")
+
+	if err := tap.MarkFlagRequired("operator"); err != nil {
+		// MarkFlagRequired only fails if the flag does not exist
+		panic(err)
+	}
+
+	return tap
+}
+
+func runTap(command *cobra.Command, _ []string, flags *TapFlags) error {
+	address := flags.Address
+	if address == "" {
+		var err error
+		if address, err = configAdminAddress(flags.ConfigFiles); err != nil {
+			return err
+		}
+	}
+
+	operatorID := flags.Operator
+	if !strings.HasPrefix(operatorID, "$.") {
+		operatorID = "$." + operatorID
+	}
+
+	query := url.Values{}
+	if flags.Expr != "" {
+		query.Set("expr", flags.Expr)
+	}
+	if flags.Sample != 1 {
+		query.Set("sample", strconv.FormatFloat(flags.Sample, 'f', -1, 64))
+	}
+	tapURL := url.URL{
+		Scheme:   "http",
+		Host:     address,
+		Path:     fmt.Sprintf("/operators/%s/tap", operatorID),
+		RawQuery: query.Encode(),
+	}
+
+	ctx, cancel := signal.NotifyContext(command.Context(), os.Interrupt)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, tapURL.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("connect to admin listener: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+			return fmt.Errorf("tap %s: %s", operatorID, resp.Status)
+		}
+		return fmt.Errorf("tap %s: %s", operatorID, body.Error)
+	}
+
+	if _, err := io.Copy(stdout, resp.Body); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("stream entries: %s", err)
+	}
+	return nil
+}
+
+// configAdminAddress returns the address of the admin listener configured in the config files
+func configAdminAddress(configFiles []string) (string, error) {
+	cfg, err := agent.NewConfigFromGlobs(configFiles)
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.Admin == nil || cfg.Admin.ListenAddress == "" {
+		return "", fmt.Errorf("the config has no admin listen_address, so the --address flag is required")
+	}
+
+	host, port, err := net.SplitHostPort(cfg.Admin.ListenAddress)
+	if err != nil {
+		return "", fmt.Errorf("invalid admin listen_address: %s", err)
+	}
+
+	// A listener on every interface can be reached on localhost
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port), nil
+}
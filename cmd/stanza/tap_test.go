@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func runTapCommand(t *testing.T, rootFlags *RootFlags, args ...string) (string, error) {
+	tapCmd := NewTapCommand(rootFlags)
+	tapCmd.SetArgs(args)
+
+	// replace stdout
+	buf := bytes.NewBuffer([]byte{})
+	stdout = buf
+
+	err := tapCmd.Execute()
+	return buf.String(), err
+}
+
+func TestTapCommand(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.String()
+		if r.URL.Path != "/operators/$.parser/tap" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"operator does not exist"}`))
+			return
+		}
+		_, _ = w.Write([]byte("{\"record\":\"first\"}\n{\"record\":\"second\"}\n"))
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	t.Run("Stream", func(t *testing.T) {
+		output, err := runTapCommand(t, &RootFlags{}, "--address", address, "--operator", "parser", "--expr", `$record != ""`, "--sample", "0.5")
+		require.NoError(t, err)
+		require.Equal(t, "{\"record\":\"first\"}\n{\"record\":\"second\"}\n", output)
+		require.Equal(t, "/operators/$.parser/tap?expr=%24record+%21%3D+%22%22&sample=0.5", requested)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		_, err := runTapCommand(t, &RootFlags{}, "--address", address, "--operator", "$.missing")
+		require.Error(t, err)
+		require.Equal(t, "tap $.missing: operator does not exist", err.Error())
+	})
+}
+
+func TestConfigAdminAddress(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{"Host", "admin:\n  listen_address: 10.0.0.1:8081\n", "10.0.0.1:8081"},
+		{"EmptyHost", "admin:\n  listen_address: :8081\n", "localhost:8081"},
+		{"Unspecified", "admin:\n  listen_address: 0.0.0.0:8081\n", "localhost:8081"},
+		{"Missing", "pipeline: []\n", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			configPath := filepath.Join(testutil.NewTempDir(t), "config.yaml")
+			require.NoError(t, ioutil.WriteFile(configPath, []byte(tc.config), 0666))
+
+			address, err := configAdminAddress([]string{configPath})
+			if tc.expected == "" {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, address)
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/observiq/stanza/agent"
+	"github.com/observiq/stanza/database"
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/pipeline"
+	"github.com/observiq/stanza/plugin"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const envStanzaDefaultTimestamp = "STANZA_DEFAULT_TIMESTAMP"
+
+// defaultTestTimestamp is the timestamp of the fixture entries when STANZA_DEFAULT_TIMESTAMP is not set
+var defaultTestTimestamp = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// TestFlags are the flags that can be supplied when running the test command
+type TestFlags struct {
+	*RootFlags
+
+	Fixture string
+	Golden  string
+	Update  bool
+	Timeout time.Duration
+}
+
+// NewTestCommand creates a command for running fixture lines through the pipeline and
+// comparing the entries it outputs to a golden file
+func NewTestCommand(rootFlags *RootFlags) *cobra.Command {
+	testFlags := &TestFlags{
+		RootFlags: rootFlags,
+	}
+
+	test := &cobra.Command{
+		Use:           "test --fixture ./lines.log --golden ./expected.yaml",
+		Args:          cobra.NoArgs,
+		Short:         "Run fixture lines through the pipeline and compare the output to a golden file",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          func(command *cobra.Command, args []string) error { return runTest(command, args, testFlags) },
+	}
+
+	testFlagSet := test.Flags()
+	testFlagSet.StringVar(&testFlags.Fixture, "fixture", "", "path to a file with the lines written by each input operator")
+	testFlagSet.StringVar(&testFlags.Golden, "golden", "", "path to a json or yaml file with the expected entries of each output operator")
+	testFlagSet.BoolVar(&testFlags.Update, "update", false, "rewrite the golden file with the entries output by the pipeline")
+	testFlagSet.DurationVar(&testFlags.Timeout, "timeout", 10*time.Second, "how long to wait for the pipeline to process the fixture lines")
+
+	for _, flag := range []string{"fixture", "golden"} {
+		if err := test.MarkFlagRequired(flag); err != nil {
+			// MarkFlagRequired only fails if the flag does not exist
+			panic(err)
+		}
+	}
+
+	return test
+}
+
+func runTest(command *cobra.Command, _ []string, flags *TestFlags) error {
+	logger := newLogger(*flags.RootFlags).Sugar()
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	lines, err := readFixture(flags.Fixture)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := agent.NewConfigFromGlobs(flags.ConfigFiles)
+	if err != nil {
+		return err
+	}
+
+	if errs := plugin.RegisterPlugins(flags.PluginDir, operator.DefaultRegistry); len(errs) != 0 {
+		logger.Errorw("Got errors parsing plugins", "errors", errs)
+	}
+
+	buildContext := operator.NewBuildContext(database.NewStubDatabase(), logger)
+	operators, err := cfg.BuildOperators(buildContext)
+	if err != nil {
+		return err
+	}
+
+	inputs, outputs, err := replaceTestOperators(operators, buildContext)
+	if err != nil {
+		return err
+	}
+
+	testPipeline, err := pipeline.NewDirectedPipeline(operators)
+	if err != nil {
+		return err
+	}
+
+	if err := testPipeline.Start(); err != nil {
+		_ = testPipeline.Stop()
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(command.Context(), flags.Timeout)
+	defer cancel()
+
+	for _, input := range inputs {
+		if err := input.writeLines(ctx, lines); err != nil {
+			_ = testPipeline.Stop()
+			return err
+		}
+	}
+
+	results, err := testPipeline.Drain(ctx)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Remaining > 0 {
+			return fmt.Errorf("operator %s did not finish processing %d entries within %s", result.OperatorID, result.Remaining, flags.Timeout)
+		}
+	}
+
+	actual, err := normalizeEntries(captured(outputs))
+	if err != nil {
+		return err
+	}
+
+	if flags.Update {
+		if err := writeGolden(flags.Golden, actual); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(stdout, "Updated golden file %s\n", flags.Golden)
+		return err
+	}
+
+	expected, err := readGolden(flags.Golden)
+	if err != nil {
+		return err
+	}
+
+	diff, err := diffEntries(expected, actual, flags.Golden)
+	if err != nil {
+		return err
+	}
+
+	if diff != "" {
+		if _, err := fmt.Fprint(stdout, diff); err != nil {
+			return err
+		}
+		return fmt.Errorf("output does not match golden file %s", flags.Golden)
+	}
+
+	_, err = fmt.Fprintln(stdout, "Output matches golden file")
+	return err
+}
+
+// readFixture reads the lines of a fixture file
+func readFixture(path string) ([]string, error) {
+	file, err := os.Open(path) // #nosec - fixtures load based on user specified path
+	if err != nil {
+		return nil, fmt.Errorf("read fixture file: %s", err)
+	}
+	defer file.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read fixture file: %s", err)
+	}
+	return lines, nil
+}
+
+// replaceTestOperators replaces the inputs of a pipeline with inputs that write fixture
+// lines and replaces the outputs of a pipeline with outputs that capture the entries they receive
+func replaceTestOperators(operators []operator.Operator, bc operator.BuildContext) ([]*fixtureInput, []*captureOutput, error) {
+	inputs := make([]*fixtureInput, 0)
+	outputs := make([]*captureOutput, 0)
+	for i, op := range operators {
+		switch {
+		case !op.CanProcess():
+			input := &fixtureInput{Operator: op}
+			operators[i] = input
+			inputs = append(inputs, input)
+		case isOutput(op):
+			output, err := newCaptureOutput(op.ID(), bc)
+			if err != nil {
+				return nil, nil, err
+			}
+			operators[i] = output
+			outputs = append(outputs, output)
+		}
+	}
+
+	if len(inputs) == 0 {
+		return nil, nil, fmt.Errorf("the pipeline has no input operators to write the fixture lines")
+	}
+	return inputs, outputs, nil
+}
+
+// isOutput returns true if the operator is an output, including outputs with a dead letter output
+func isOutput(op operator.Operator) bool {
+	op = operator.Unwrap(op)
+	if _, ok := op.(interface {
+		Reject(context.Context, *entry.Entry, error)
+	}); ok {
+		return true
+	}
+	return !op.CanOutput()
+}
+
+// fixtureInput is an input that is never started, and instead writes the lines of the fixture
+type fixtureInput struct {
+	operator.Operator
+}
+
+// entryWriter is an input that creates entries with its `write_to`, `labels` and `resource` configuration
+type entryWriter interface {
+	NewEntry(interface{}) (*entry.Entry, error)
+	Write(context.Context, *entry.Entry)
+}
+
+// Start does not start the replaced input
+func (f *fixtureInput) Start() error { return nil }
+
+// Stop does not stop the replaced input
+func (f *fixtureInput) Stop() error { return nil }
+
+// writeLines will write each line as an entry to the outputs of the input
+func (f *fixtureInput) writeLines(ctx context.Context, lines []string) error {
+	for _, line := range lines {
+		e, err := f.newEntry(line)
+		if err != nil {
+			return fmt.Errorf("create entry for %s: %s", f.ID(), err)
+		}
+
+		if os.Getenv(envStanzaDefaultTimestamp) == "" {
+			e.Timestamp = defaultTestTimestamp
+		}
+
+		if writer, ok := f.Operator.(entryWriter); ok {
+			writer.Write(ctx, e)
+			continue
+		}
+		for _, output := range f.Outputs() {
+			_ = output.Process(ctx, e.Copy())
+		}
+	}
+	return nil
+}
+
+// newEntry will create an entry for a line, with the configuration of the input if it has one
+func (f *fixtureInput) newEntry(line string) (*entry.Entry, error) {
+	if writer, ok := f.Operator.(entryWriter); ok {
+		return writer.NewEntry(line)
+	}
+
+	e := entry.New()
+	e.Record = line
+	return e, nil
+}
+
+// captureOutput is an output that captures the entries it receives
+type captureOutput struct {
+	helper.OutputOperator
+
+	mux     sync.Mutex
+	entries []*entry.Entry
+}
+
+// newCaptureOutput creates an output that captures entries in place of the output with the supplied ID
+func newCaptureOutput(operatorID string, bc operator.BuildContext) (*captureOutput, error) {
+	outputOperator, err := helper.NewOutputConfig(operatorID, "capture_output").Build(bc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &captureOutput{
+		OutputOperator: outputOperator,
+		entries:        make([]*entry.Entry, 0),
+	}, nil
+}
+
+// Process will capture an entry
+func (c *captureOutput) Process(_ context.Context, e *entry.Entry) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.entries = append(c.entries, e.Copy())
+	return nil
+}
+
+// captured returns the entries captured by each output, keyed by the ID of the output
+func captured(outputs []*captureOutput) map[string][]*entry.Entry {
+	entries := make(map[string][]*entry.Entry, len(outputs))
+	for _, output := range outputs {
+		output.mux.Lock()
+		entries[output.ID()] = output.entries
+		output.mux.Unlock()
+	}
+	return entries
+}
+
+// normalizeEntries converts entries into the generic values they are read as from a json file
+func normalizeEntries(entries map[string][]*entry.Entry) (interface{}, error) {
+	contents, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(contents, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// isYAML returns true if the path has a yaml extension
+func isYAML(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// marshalGolden marshals entries in the format of a golden file
+func marshalGolden(path string, entries interface{}) ([]byte, error) {
+	if isYAML(path) {
+		return yaml.Marshal(entries)
+	}
+
+	contents, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(contents, '\n'), nil
+}
+
+// writeGolden will write entries to a golden file
+func writeGolden(path string, entries interface{}) error {
+	contents, err := marshalGolden(path, entries)
+	if err != nil {
+		return fmt.Errorf("marshal golden file: %s", err)
+	}
+
+	if err := ioutil.WriteFile(path, contents, 0600); err != nil {
+		return fmt.Errorf("write golden file: %s", err)
+	}
+	return nil
+}
+
+// readGolden will read the entries of a golden file as generic json values
+func readGolden(path string) (interface{}, error) {
+	contents, err := ioutil.ReadFile(path) // #nosec - golden files load based on user specified path
+	if err != nil {
+		return nil, fmt.Errorf("read golden file: %s", err)
+	}
+
+	if !isYAML(path) {
+		var expected interface{}
+		if err := json.Unmarshal(contents, &expected); err != nil {
+			return nil, fmt.Errorf("read golden file as json: %s", err)
+		}
+		return expected, nil
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(contents, &raw); err != nil {
+		return nil, fmt.Errorf("read golden file as yaml: %s", err)
+	}
+
+	contents, err = json.Marshal(jsonCompatible(raw))
+	if err != nil {
+		return nil, fmt.Errorf("read golden file as yaml: %s", err)
+	}
+
+	var expected interface{}
+	if err := json.Unmarshal(contents, &expected); err != nil {
+		return nil, err
+	}
+	return expected, nil
+}
+
+// jsonCompatible converts the maps decoded from yaml into maps that can be marshalled as json
+func jsonCompatible(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			converted[fmt.Sprint(k)] = jsonCompatible(v)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(value))
+		for i, v := range value {
+			converted[i] = jsonCompatible(v)
+		}
+		return converted
+	default:
+		return value
+	}
+}
+
+// diffEntries returns a unified diff of the expected and actual entries, or an empty string if they match
+func diffEntries(expected, actual interface{}, path string) (string, error) {
+	expectedContents, err := marshalGolden(path, expected)
+	if err != nil {
+		return "", err
+	}
+
+	actualContents, err := marshalGolden(path, actual)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expectedContents)),
+		B:        difflib.SplitLines(string(actualContents)),
+		FromFile: path,
+		ToFile:   "actual",
+		Context:  3,
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+pipeline:
+  - type: generate_input
+    labels:
+      source: fixture
+  - type: regex_parser
+    regex: '^(?P<level>\w+) (?P<message>.*)$'
+  - type: stdout
+`
+
+func runTestCommand(t *testing.T, dir string, args ...string) (string, error) {
+	rootFlags := &RootFlags{
+		ConfigFiles: []string{filepath.Join(dir, "config.yaml")},
+		PluginDir:   filepath.Join(dir, "plugins"),
+		LogLevel:    "error",
+	}
+	testCmd := NewTestCommand(rootFlags)
+	testCmd.SetArgs(append([]string{
+		"--fixture", filepath.Join(dir, "fixture.log"),
+	}, args...))
+
+	// replace stdout
+	buf := bytes.NewBuffer([]byte{})
+	stdout = buf
+
+	err := testCmd.Execute()
+	return buf.String(), err
+}
+
+func writeTestFiles(t *testing.T, dir, fixture string) {
+	err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(testConfig), 0666)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "fixture.log"), []byte(fixture), 0666)
+	require.NoError(t, err)
+}
+
+func TestTestCommand(t *testing.T) {
+	for _, golden := range []string{"expected.yaml", "expected.json"} {
+		t.Run(golden, func(t *testing.T) {
+			dir := testutil.NewTempDir(t)
+			goldenPath := filepath.Join(dir, golden)
+			writeTestFiles(t, dir, "INFO started\nERROR failed\n")
+
+			output, err := runTestCommand(t, dir, "--golden", goldenPath, "--update")
+			require.NoError(t, err)
+			require.Equal(t, "Updated golden file "+goldenPath+"\n", output)
+
+			contents, err := ioutil.ReadFile(goldenPath)
+			require.NoError(t, err)
+			require.Contains(t, string(contents), "2020-01-01T00:00:00Z")
+			require.Contains(t, string(contents), "started")
+			require.Contains(t, string(contents), "fixture")
+
+			output, err = runTestCommand(t, dir, "--golden", goldenPath)
+			require.NoError(t, err)
+			require.Equal(t, "Output matches golden file\n", output)
+
+			writeTestFiles(t, dir, "INFO started\nERROR crashed\n")
+			output, err = runTestCommand(t, dir, "--golden", goldenPath)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "output does not match golden file")
+			require.Contains(t, output, "--- "+goldenPath)
+			require.Contains(t, output, "failed")
+			require.Contains(t, output, "crashed")
+		})
+	}
+}
+
+func TestTestCommandMissingGolden(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	writeTestFiles(t, dir, "INFO started\n")
+
+	_, err := runTestCommand(t, dir, "--golden", filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "read golden file")
+}
+
+func TestTestCommandNamedPipelines(t *testing.T) {
+	dir := testutil.NewTempDir(t)
+	writeTestFiles(t, dir, "INFO started\n")
+	err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`
+pipelines:
+  team_a:
+    - type: generate_input
+    - type: stdout
+`), 0666)
+	require.NoError(t, err)
+
+	goldenPath := filepath.Join(dir, "expected.json")
+	_, err = runTestCommand(t, dir, "--golden", goldenPath, "--update")
+	require.NoError(t, err)
+
+	contents, err := ioutil.ReadFile(goldenPath)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "$.team_a.stdout")
+	require.Contains(t, string(contents), "INFO started")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/observiq/stanza/agent"
+	"github.com/observiq/stanza/database"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/plugin"
+	"github.com/observiq/stanza/secret"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewValidateCommand creates a command for validating the config without starting the agent
+func NewValidateCommand(rootFlags *RootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:           "validate",
+		Args:          cobra.NoArgs,
+		Short:         "Validate the config files and plugins without starting the agent",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          func(command *cobra.Command, args []string) error { return runValidate(command, args, rootFlags) },
+	}
+}
+
+func runValidate(_ *cobra.Command, _ []string, flags *RootFlags) error {
+	errs := plugin.RegisterPlugins(flags.PluginDir, operator.DefaultRegistry)
+
+	buildContext := operator.NewBuildContext(database.NewStubDatabase(), zap.NewNop().Sugar())
+	errs = append(errs, agent.ValidateConfigFiles(flags.ConfigFiles, buildContext)...)
+
+	for _, err := range errs {
+		if _, writeErr := fmt.Fprintln(stdout, formatValidationError(err)); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	if len(errs) != 0 {
+		return fmt.Errorf("found %d errors in config", len(errs))
+	}
+
+	_, err := fmt.Fprintln(stdout, "Config is valid")
+	return err
+}
+
+// formatValidationError formats an error as a single line, with its details and suggestion
+func formatValidationError(err error) string {
+	message := err.Error()
+	if agentErr, ok := err.(errors.AgentError); ok && agentErr.Suggestion != "" {
+		message = fmt.Sprintf("%s (%s)", message, agentErr.Suggestion)
+	}
+	return "error: " + strings.Join(strings.Fields(secret.Redact(message)), " ")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func validateTest(t *testing.T, config string) (string, error) {
+	tempDir := testutil.NewTempDir(t)
+	configPath := filepath.Join(tempDir, "config.yaml")
+	err := ioutil.WriteFile(configPath, []byte(config), 0666)
+	require.NoError(t, err)
+
+	rootFlags := &RootFlags{
+		ConfigFiles: []string{configPath},
+		PluginDir:   tempDir,
+	}
+	validateCmd := NewValidateCommand(rootFlags)
+
+	// replace stdout
+	buf := bytes.NewBuffer([]byte{})
+	stdout = buf
+
+	err = validateCmd.Execute()
+	return buf.String(), err
+}
+
+func TestValidateValid(t *testing.T) {
+	config := `
+pipeline:
+  - type: generate_input
+    entry:
+      record:
+        test: value
+  - type: json_parser
+  - type: stdout
+`
+
+	output, err := validateTest(t, config)
+	require.NoError(t, err)
+	require.Equal(t, "Config is valid\n", output)
+}
+
+func TestValidateInvalid(t *testing.T) {
+	config := `
+pipeline:
+  - type: generate_input
+    output: missing
+  - id: parser
+    type: regex_parser
+    regex: '('
+  - type: stdout
+    unknown_field: true
+`
+
+	output, err := validateTest(t, config)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "found 3 errors")
+
+	lines := bytes.Split(bytes.TrimSpace([]byte(output)), []byte("\n"))
+	require.Len(t, lines, 3, output)
+	require.Contains(t, string(lines[0]), `"operator_id":"$.stdout"`)
+	require.Contains(t, string(lines[1]), `"operator_id":"$.parser"`)
+	require.Contains(t, string(lines[2]), `"operator_id":"$.generate_input"`)
+	require.Contains(t, string(lines[2]), "operator '$.missing' does not exist")
+}
@@ -0,0 +1,90 @@
+package entry
+
+import "sync/atomic"
+
+// Ack tracks the delivery of an entry and the copies made of it. It counts the
+// references held on the entry, and calls its callback once all of them have been
+// released, which signals that the entry will not be lost if the agent crashes.
+type Ack struct {
+	refs     int64
+	callback func()
+}
+
+// NewAck creates an acknowledgement holding a single reference, which belongs to the
+// caller. The callback is called once that reference and any others have been released.
+func NewAck(callback func()) *Ack {
+	return &Ack{
+		refs:     1,
+		callback: callback,
+	}
+}
+
+// retain adds a reference to the acknowledgement
+func (a *Ack) retain() {
+	atomic.AddInt64(&a.refs, 1)
+}
+
+// release removes a reference from the acknowledgement, calling
+// its callback if it was the last one
+func (a *Ack) release() {
+	if atomic.AddInt64(&a.refs, -1) == 0 && a.callback != nil {
+		a.callback()
+	}
+}
+
+// SetAck attaches an acknowledgement to the entry. It is shared with every copy made of the entry.
+func (entry *Entry) SetAck(ack *Ack) {
+	entry.ack = ack
+}
+
+// Retain adds a reference to the entry's acknowledgement, if it has one. Operators
+// that hold on to an entry after returning from Process, like buffered outputs, must
+// retain it, then release it once it has been delivered.
+func (entry *Entry) Retain() {
+	if entry.ack != nil {
+		entry.ack.retain()
+	}
+}
+
+// Release removes a reference from the entry's acknowledgement, if it has one.
+func (entry *Entry) Release() {
+	if entry.ack != nil {
+		entry.ack.release()
+	}
+}
+
+// Combine replaces the acknowledgement of the entry with one that releases each of the
+// sources once the entry has been delivered. It is used by operators that combine several
+// retained entries into one. The caller holds the only reference to the new acknowledgement,
+// and must release it once the entry has been written.
+func (entry *Entry) Combine(sources []*Entry) {
+	acks := make([]*Ack, 0, len(sources))
+	for _, source := range sources {
+		if source.ack != nil {
+			acks = append(acks, source.ack)
+		}
+	}
+	entry.CombineAcks(acks)
+}
+
+// RetainAck adds a reference to the entry's acknowledgement and returns it, or returns nil if
+// the entry has no acknowledgement. It is used by operators that summarize entries without
+// holding on to them, which pass the acknowledgements to CombineAcks once they write the summary.
+func (entry *Entry) RetainAck() *Ack {
+	entry.Retain()
+	return entry.ack
+}
+
+// CombineAcks is like Combine, for acknowledgements that were retained with RetainAck.
+func (entry *Entry) CombineAcks(acks []*Ack) {
+	if len(acks) == 0 {
+		entry.ack = nil
+		return
+	}
+
+	entry.ack = NewAck(func() {
+		for _, ack := range acks {
+			ack.release()
+		}
+	})
+}
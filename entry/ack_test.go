@@ -0,0 +1,82 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAckRelease(t *testing.T) {
+	acked := 0
+	e := New()
+	e.SetAck(NewAck(func() { acked++ }))
+
+	e.Retain()
+	e.Release()
+	require.Equal(t, 0, acked)
+
+	e.Release()
+	require.Equal(t, 1, acked)
+}
+
+func TestAckSharedWithCopies(t *testing.T) {
+	acked := 0
+	e := New()
+	e.SetAck(NewAck(func() { acked++ }))
+
+	copied := e.Copy()
+	copied.Retain()
+	e.Release()
+	require.Equal(t, 0, acked)
+
+	copied.Release()
+	require.Equal(t, 1, acked)
+}
+
+func TestAckWithoutAck(t *testing.T) {
+	e := New()
+	require.NotPanics(t, func() {
+		e.Retain()
+		e.Release()
+	})
+}
+
+func TestAckCombine(t *testing.T) {
+	acked := 0
+	sources := make([]*Entry, 3)
+	for i := range sources {
+		sources[i] = New()
+		sources[i].SetAck(NewAck(func() { acked++ }))
+		sources[i].Retain()
+		sources[i].Release()
+	}
+
+	combined := sources[0]
+	combined.Combine(sources)
+	combined.Retain()
+	combined.Release()
+	require.Equal(t, 0, acked)
+
+	combined.Release()
+	require.Equal(t, 3, acked)
+}
+
+func TestAckCombineAcks(t *testing.T) {
+	acked := 0
+	acks := make([]*Ack, 0, 3)
+	for i := 0; i < 3; i++ {
+		source := New()
+		source.SetAck(NewAck(func() { acked++ }))
+		acks = append(acks, source.RetainAck())
+		source.Release()
+	}
+	require.Nil(t, New().RetainAck())
+	require.Equal(t, 0, acked)
+
+	combined := New()
+	combined.CombineAcks(acks)
+	require.Equal(t, 0, acked)
+
+	combined.Release()
+	require.Equal(t, 3, acked)
+}
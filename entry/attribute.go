@@ -0,0 +1,88 @@
+package entry
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// attributeValue checks that a value can be stored in the labels or resource of an entry, which
+// hold strings, integers, floats, booleans and lists of these. Byte slices are stored as strings,
+// and lists of any type are stored as a []interface{}, so that outputs only need to handle one.
+func attributeValue(val interface{}) (interface{}, error) {
+	switch typed := val.(type) {
+	case []byte:
+		return string(typed), nil
+	case []interface{}:
+		list := make([]interface{}, 0, len(typed))
+		for _, item := range typed {
+			if !isAttributeScalar(item) {
+				return nil, fmt.Errorf("list item of type '%T' is not a string, number or boolean", item)
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	}
+
+	if isAttributeScalar(val) {
+		return val, nil
+	}
+
+	value := reflect.ValueOf(val)
+	if value.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("value of type '%T' is not a string, number, boolean or list of these", val)
+	}
+
+	list := make([]interface{}, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		item := value.Index(i).Interface()
+		if !isAttributeScalar(item) {
+			return nil, fmt.Errorf("list item of type '%T' is not a string, number or boolean", item)
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// isAttributeScalar returns true if the value is a string, number or boolean
+func isAttributeScalar(val interface{}) bool {
+	switch val.(type) {
+	case string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// AttributeString formats the value of a label or resource key as a string, for
+// destinations that only support string values. Lists are formatted as JSON arrays.
+func AttributeString(val interface{}) string {
+	switch typed := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case []byte:
+		return string(typed)
+	case bool:
+		return strconv.FormatBool(typed)
+	case float32:
+		return strconv.FormatFloat(float64(typed), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	}
+
+	if isAttributeScalar(val) {
+		return fmt.Sprint(val)
+	}
+
+	bytes, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	return string(bytes)
+}
@@ -0,0 +1,73 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeValue(t *testing.T) {
+	cases := []struct {
+		name     string
+		val      interface{}
+		expected interface{}
+	}{
+		{"String", "val", "val"},
+		{"Bytes", []byte("val"), "val"},
+		{"Int", 1, 1},
+		{"Int64", int64(1), int64(1)},
+		{"Uint", uint(1), uint(1)},
+		{"Float", 1.5, 1.5},
+		{"Bool", true, true},
+		{"InterfaceList", []interface{}{"a", 1, true}, []interface{}{"a", 1, true}},
+		{"StringList", []string{"a", "b"}, []interface{}{"a", "b"}},
+		{"FloatList", []float64{1.5}, []interface{}{1.5}},
+		{"EmptyList", []string{}, []interface{}{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			val, err := attributeValue(tc.val)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, val)
+		})
+	}
+
+	for _, val := range []interface{}{
+		nil,
+		map[string]interface{}{"key": "val"},
+		map[string]string{"key": "val"},
+		[]interface{}{[]interface{}{"a"}},
+		[]map[string]interface{}{{"key": "val"}},
+		struct{}{},
+	} {
+		_, err := attributeValue(val)
+		require.Error(t, err, "%#v", val)
+	}
+}
+
+func TestAttributeString(t *testing.T) {
+	cases := []struct {
+		name     string
+		val      interface{}
+		expected string
+	}{
+		{"Nil", nil, ""},
+		{"String", "val", "val"},
+		{"Bytes", []byte("val"), "val"},
+		{"Int", 10, "10"},
+		{"NegativeInt64", int64(-10), "-10"},
+		{"Uint", uint8(10), "10"},
+		{"Float", 1.5, "1.5"},
+		{"WholeFloat", float64(10), "10"},
+		{"Float32", float32(0.1), "0.1"},
+		{"Bool", false, "false"},
+		{"List", []interface{}{"a", 1, true}, `["a",1,true]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, AttributeString(tc.val))
+		})
+	}
+}
@@ -5,7 +5,10 @@ import "encoding/json"
 // copyValue will deep copy a value based on its type.
 func copyValue(v interface{}) interface{} {
 	switch value := v.(type) {
-	case string, int, bool, byte, nil:
+	case string, bool, nil,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
 		return value
 	case map[string]string:
 		return copyStringMap(value)
@@ -56,6 +59,14 @@ func copyByteArray(a []byte) []byte {
 	return arrayCopy
 }
 
+// copyBytes will deep copy an array of bytes, keeping a nil array nil.
+func copyBytes(a []byte) []byte {
+	if a == nil {
+		return nil
+	}
+	return copyByteArray(a)
+}
+
 // copyIntArray will deep copy an array of ints.
 func copyIntArray(a []int) []int {
 	arrayCopy := make([]int, len(a))
@@ -1,6 +1,9 @@
 package entry
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"time"
@@ -28,12 +31,18 @@ var now = getNow()
 
 // Entry is a flexible representation of log data associated with a timestamp.
 type Entry struct {
-	Timestamp    time.Time         `json:"timestamp"               yaml:"timestamp"`
-	Severity     Severity          `json:"severity"                yaml:"severity"`
-	SeverityText string            `json:"severity_text,omitempty" yaml:"severity_text,omitempty"`
-	Labels       map[string]string `json:"labels,omitempty"        yaml:"labels,omitempty"`
-	Resource     map[string]string `json:"resource,omitempty"      yaml:"resource,omitempty"`
-	Record       interface{}       `json:"record"                  yaml:"record"`
+	Timestamp    time.Time              `json:"timestamp"               yaml:"timestamp"`
+	Severity     Severity               `json:"severity"                yaml:"severity"`
+	SeverityText string                 `json:"severity_text,omitempty" yaml:"severity_text,omitempty"`
+	Labels       map[string]interface{} `json:"labels,omitempty"        yaml:"labels,omitempty"`
+	Resource     map[string]interface{} `json:"resource,omitempty"      yaml:"resource,omitempty"`
+	TraceID      []byte                 `json:"trace_id,omitempty"      yaml:"trace_id,omitempty"`
+	SpanID       []byte                 `json:"span_id,omitempty"       yaml:"span_id,omitempty"`
+	TraceFlags   []byte                 `json:"trace_flags,omitempty"   yaml:"trace_flags,omitempty"`
+	Record       interface{}            `json:"record"                  yaml:"record"`
+
+	// ack tracks the delivery of the entry, if the operator that created it cares
+	ack *Ack
 }
 
 // New will create a new log entry with current timestamp and an empty record.
@@ -43,18 +52,20 @@ func New() *Entry {
 	}
 }
 
-// AddLabel will add a key/value pair to the entry's labels.
-func (entry *Entry) AddLabel(key, value string) {
+// AddLabel will add a key/value pair to the entry's labels. The value should be a
+// string, integer, float, boolean, or a list of these.
+func (entry *Entry) AddLabel(key string, value interface{}) {
 	if entry.Labels == nil {
-		entry.Labels = make(map[string]string)
+		entry.Labels = make(map[string]interface{})
 	}
 	entry.Labels[key] = value
 }
 
-// AddResourceKey wil add a key/value pair to the entry's resource.
-func (entry *Entry) AddResourceKey(key, value string) {
+// AddResourceKey wil add a key/value pair to the entry's resource. The value should be a
+// string, integer, float, boolean, or a list of these.
+func (entry *Entry) AddResourceKey(key string, value interface{}) {
 	if entry.Resource == nil {
-		entry.Resource = make(map[string]string)
+		entry.Resource = make(map[string]interface{})
 	}
 	entry.Resource[key] = value
 }
@@ -179,8 +190,114 @@ func (entry *Entry) Copy() *Entry {
 		Timestamp:    entry.Timestamp,
 		Severity:     entry.Severity,
 		SeverityText: entry.SeverityText,
-		Labels:       copyStringMap(entry.Labels),
-		Resource:     copyStringMap(entry.Resource),
+		Labels:       copyInterfaceMap(entry.Labels),
+		Resource:     copyInterfaceMap(entry.Resource),
+		TraceID:      copyBytes(entry.TraceID),
+		SpanID:       copyBytes(entry.SpanID),
+		TraceFlags:   copyBytes(entry.TraceFlags),
 		Record:       copyValue(entry.Record),
+		ack:          entry.ack,
+	}
+}
+
+/****************
+  Serialization
+****************/
+
+// encodedEntry is an entry as it is serialized, with its trace context as hex strings
+// like in the W3C trace context, rather than as base64 or lists of bytes
+type encodedEntry struct {
+	Timestamp    time.Time              `json:"timestamp"               yaml:"timestamp"`
+	Severity     Severity               `json:"severity"                yaml:"severity"`
+	SeverityText string                 `json:"severity_text,omitempty" yaml:"severity_text,omitempty"`
+	Labels       map[string]interface{} `json:"labels,omitempty"        yaml:"labels,omitempty"`
+	Resource     map[string]interface{} `json:"resource,omitempty"      yaml:"resource,omitempty"`
+	TraceID      string                 `json:"trace_id,omitempty"      yaml:"trace_id,omitempty"`
+	SpanID       string                 `json:"span_id,omitempty"       yaml:"span_id,omitempty"`
+	TraceFlags   string                 `json:"trace_flags,omitempty"   yaml:"trace_flags,omitempty"`
+	Record       interface{}            `json:"record"                  yaml:"record"`
+}
+
+// encode returns the entry as it is serialized
+func (entry *Entry) encode() encodedEntry {
+	return encodedEntry{
+		Timestamp:    entry.Timestamp,
+		Severity:     entry.Severity,
+		SeverityText: entry.SeverityText,
+		Labels:       entry.Labels,
+		Resource:     entry.Resource,
+		TraceID:      hex.EncodeToString(entry.TraceID),
+		SpanID:       hex.EncodeToString(entry.SpanID),
+		TraceFlags:   hex.EncodeToString(entry.TraceFlags),
+		Record:       entry.Record,
+	}
+}
+
+// decode sets the entry from its serialized form
+func (entry *Entry) decode(encoded encodedEntry) error {
+	traceID, err := decodeTraceBytes("trace_id", encoded.TraceID)
+	if err != nil {
+		return err
+	}
+	spanID, err := decodeTraceBytes("span_id", encoded.SpanID)
+	if err != nil {
+		return err
+	}
+	traceFlags, err := decodeTraceBytes("trace_flags", encoded.TraceFlags)
+	if err != nil {
+		return err
+	}
+
+	entry.Timestamp = encoded.Timestamp
+	entry.Severity = encoded.Severity
+	entry.SeverityText = encoded.SeverityText
+	entry.Labels = encoded.Labels
+	entry.Resource = encoded.Resource
+	entry.TraceID, entry.SpanID, entry.TraceFlags = traceID, spanID, traceFlags
+	entry.Record = encoded.Record
+	return nil
+}
+
+// decodeTraceBytes decodes a hex trace context field. Base64 is accepted as well, since entries
+// buffered to disk by earlier versions have it. The base64 encodings of trace ids, span ids and
+// trace flags are padded, so they are never valid hex.
+func decodeTraceBytes(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if decoded, err := hex.DecodeString(value); err == nil {
+		return decoded, nil
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("%s '%s' is not a hex string", name, value)
+}
+
+// MarshalJSON will marshal the entry for JSON, with its trace context as hex strings.
+func (entry Entry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(entry.encode())
+}
+
+// UnmarshalJSON will unmarshal an entry from JSON, with its trace context as hex strings.
+func (entry *Entry) UnmarshalJSON(raw []byte) error {
+	var encoded encodedEntry
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return err
+	}
+	return entry.decode(encoded)
+}
+
+// MarshalYAML will marshal the entry for YAML, with its trace context as hex strings.
+func (entry Entry) MarshalYAML() (interface{}, error) {
+	return entry.encode(), nil
+}
+
+// UnmarshalYAML will unmarshal an entry from YAML, with its trace context as hex strings.
+func (entry *Entry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var encoded encodedEntry
+	if err := unmarshal(&encoded); err != nil {
+		return err
 	}
+	return entry.decode(encoded)
 }
@@ -1,11 +1,13 @@
 package entry
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
 )
 
 func TestRead(t *testing.T) {
@@ -125,25 +127,58 @@ func TestCopy(t *testing.T) {
 	entry.SeverityText = "ok"
 	entry.Timestamp = time.Time{}
 	entry.Record = "test"
-	entry.Labels = map[string]string{"label": "value"}
-	entry.Resource = map[string]string{"resource": "value"}
+	entry.Labels = map[string]interface{}{"label": "value"}
+	entry.Resource = map[string]interface{}{"resource": "value"}
 	entryCopy := entry.Copy()
 
 	entry.Severity = Severity(1)
 	entry.SeverityText = "1"
 	entry.Timestamp = time.Now()
 	entry.Record = "new"
-	entry.Labels = map[string]string{"label": "new value"}
-	entry.Resource = map[string]string{"resource": "new value"}
+	entry.Labels = map[string]interface{}{"label": "new value"}
+	entry.Resource = map[string]interface{}{"resource": "new value"}
 
 	require.Equal(t, time.Time{}, entryCopy.Timestamp)
 	require.Equal(t, Severity(0), entryCopy.Severity)
 	require.Equal(t, "ok", entryCopy.SeverityText)
-	require.Equal(t, map[string]string{"label": "value"}, entryCopy.Labels)
-	require.Equal(t, map[string]string{"resource": "value"}, entryCopy.Resource)
+	require.Equal(t, map[string]interface{}{"label": "value"}, entryCopy.Labels)
+	require.Equal(t, map[string]interface{}{"resource": "value"}, entryCopy.Resource)
 	require.Equal(t, "test", entryCopy.Record)
 }
 
+func TestCopyTypedLabels(t *testing.T) {
+	entry := New()
+	entry.Labels = map[string]interface{}{
+		"int":   int64(1),
+		"float": float32(1.5),
+		"bool":  true,
+		"list":  []interface{}{"a", 1},
+	}
+	entryCopy := entry.Copy()
+	require.Equal(t, entry.Labels, entryCopy.Labels)
+
+	entry.Labels["list"].([]interface{})[0] = "b"
+	require.Equal(t, []interface{}{"a", 1}, entryCopy.Labels["list"])
+}
+
+func TestCopyTraceContext(t *testing.T) {
+	entry := New()
+	entryCopy := entry.Copy()
+	require.Nil(t, entryCopy.TraceID)
+	require.Nil(t, entryCopy.SpanID)
+	require.Nil(t, entryCopy.TraceFlags)
+
+	entry.TraceID = []byte{0x01, 0x02}
+	entry.SpanID = []byte{0x03}
+	entry.TraceFlags = []byte{0x01}
+	entryCopy = entry.Copy()
+
+	entry.TraceID[0] = 0xff
+	require.Equal(t, []byte{0x01, 0x02}, entryCopy.TraceID)
+	require.Equal(t, []byte{0x03}, entryCopy.SpanID)
+	require.Equal(t, []byte{0x01}, entryCopy.TraceFlags)
+}
+
 func TestFieldFromString(t *testing.T) {
 	cases := []struct {
 		name          string
@@ -181,6 +216,42 @@ func TestFieldFromString(t *testing.T) {
 			Field{},
 			true,
 		},
+		{
+			"ListIndex",
+			"$record.items[-1].name",
+			Field{RecordField{[]string{"items", "-1", "name"}}},
+			false,
+		},
+		{
+			"QuotedLabel",
+			`$labels["app.kubernetes.io/name"]`,
+			Field{LabelField{"app.kubernetes.io/name"}},
+			false,
+		},
+		{
+			"TraceID",
+			"$trace_id",
+			Field{TraceField{"$trace_id"}},
+			false,
+		},
+		{
+			"SpanID",
+			"$span_id",
+			Field{TraceField{"$span_id"}},
+			false,
+		},
+		{
+			"TraceFlags",
+			"$trace_flags",
+			Field{TraceField{"$trace_flags"}},
+			false,
+		},
+		{
+			"TraceIDNested",
+			"$trace_id.test",
+			Field{},
+			true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -199,14 +270,14 @@ func TestFieldFromString(t *testing.T) {
 func TestAddLabel(t *testing.T) {
 	entry := Entry{}
 	entry.AddLabel("label", "value")
-	expected := map[string]string{"label": "value"}
+	expected := map[string]interface{}{"label": "value"}
 	require.Equal(t, expected, entry.Labels)
 }
 
 func TestAddResourceKey(t *testing.T) {
 	entry := Entry{}
 	entry.AddResourceKey("key", "value")
-	expected := map[string]string{"key": "value"}
+	expected := map[string]interface{}{"key": "value"}
 	require.Equal(t, expected, entry.Resource)
 }
 
@@ -248,3 +319,56 @@ func TestDefaultTimestamp(t *testing.T) {
 	require.Equal(t, expected, e.Timestamp)
 	require.True(t, e.Timestamp.Equal(expected))
 }
+
+func newTraceEntry() *Entry {
+	return &Entry{
+		Timestamp:  time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Severity:   Info,
+		TraceID:    []byte{0x48, 0x01, 0x40, 0xf3, 0xd7, 0x70, 0xa5, 0xae, 0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+		SpanID:     []byte{0x32, 0xf0, 0xa2, 0x2b, 0x6a, 0x81, 0x2c, 0xff},
+		TraceFlags: []byte{0x01},
+		Record:     "message",
+	}
+}
+
+func TestMarshalJSONTraceContext(t *testing.T) {
+	marshalled, err := json.Marshal(newTraceEntry())
+	require.NoError(t, err)
+	require.Contains(t, string(marshalled), `"trace_id":"480140f3d770a5ae32f0a22b6a812cff"`)
+	require.Contains(t, string(marshalled), `"span_id":"32f0a22b6a812cff"`)
+	require.Contains(t, string(marshalled), `"trace_flags":"01"`)
+
+	var unmarshalled Entry
+	require.NoError(t, json.Unmarshal(marshalled, &unmarshalled))
+	require.Equal(t, newTraceEntry(), &unmarshalled)
+
+	marshalled, err = json.Marshal(New())
+	require.NoError(t, err)
+	require.NotContains(t, string(marshalled), "trace_id")
+}
+
+func TestMarshalYAMLTraceContext(t *testing.T) {
+	marshalled, err := yaml.Marshal(newTraceEntry())
+	require.NoError(t, err)
+	require.Contains(t, string(marshalled), "trace_id: 480140f3d770a5ae32f0a22b6a812cff\n")
+	require.Contains(t, string(marshalled), `trace_flags: "01"`)
+
+	var unmarshalled Entry
+	require.NoError(t, yaml.Unmarshal(marshalled, &unmarshalled))
+	require.Equal(t, newTraceEntry(), &unmarshalled)
+}
+
+func TestUnmarshalJSONBase64TraceContext(t *testing.T) {
+	// Entries buffered by earlier versions have their trace context in base64
+	raw := `{"timestamp":"2020-01-01T00:00:00Z","severity":30,"trace_id":"SAFA89dwpa4y8KIraoEs/w==","span_id":"MvCiK2qBLP8=","trace_flags":"AQ==","record":"message"}`
+	var unmarshalled Entry
+	require.NoError(t, json.Unmarshal([]byte(raw), &unmarshalled))
+	require.Equal(t, newTraceEntry(), &unmarshalled)
+}
+
+func TestUnmarshalJSONInvalidTraceContext(t *testing.T) {
+	var unmarshalled Entry
+	err := json.Unmarshal([]byte(`{"trace_id":"not hex"}`), &unmarshalled)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "trace_id 'not hex' is not a hex string")
+}
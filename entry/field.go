@@ -54,6 +54,21 @@ func fieldFromString(s string) (Field, error) {
 		return Field{}, fmt.Errorf("splitting field: %s", err)
 	}
 
+	if isSelector(split) {
+		switch split[0] {
+		case labelsPrefix, resourcePrefix:
+			selector, err := newSelectorField(split[0], split[1:])
+			if err != nil {
+				return Field{}, err
+			}
+			return Field{selector}, nil
+		case recordPrefix, "$":
+			return Field{SelectorField{recordPrefix, split[1:]}}, nil
+		default:
+			return Field{SelectorField{recordPrefix, split}}, nil
+		}
+	}
+
 	switch split[0] {
 	case labelsPrefix:
 		if len(split) != 2 {
@@ -65,6 +80,11 @@ func fieldFromString(s string) (Field, error) {
 			return Field{}, fmt.Errorf("resource fields cannot be nested")
 		}
 		return Field{ResourceField{split[1]}}, nil
+	case traceIDName, spanIDName, traceFlagsName:
+		if len(split) != 1 {
+			return Field{}, fmt.Errorf("trace fields cannot be nested")
+		}
+		return Field{TraceField{split[0]}}, nil
 	case recordPrefix, "$":
 		return Field{RecordField{split[1:]}}, nil
 	default:
@@ -97,6 +117,8 @@ const (
 	OutBracket
 	// InUnbracketedToken is the state field split on any token outside brackets
 	InUnbracketedToken
+	// InIndex is the state of a field split inside a bracketed list index
+	InIndex
 )
 
 func splitField(s string) ([]string, error) {
@@ -116,12 +138,27 @@ func splitField(s string) ([]string, error) {
 			tokenStart = i
 			state = InUnbracketedToken
 		case InBracket:
+			if c == '-' || (c >= '0' && c <= '9') {
+				state = InIndex
+				tokenStart = i
+				continue
+			}
 			if !(c == '\'' || c == '"') {
 				return nil, fmt.Errorf("strings in brackets must be surrounded by quotes")
 			}
 			state = InQuote
 			quoteChar = c
 			tokenStart = i + 1
+		case InIndex:
+			if c != ']' {
+				continue
+			}
+			index := s[tokenStart:i]
+			if !isIndex(index) {
+				return nil, fmt.Errorf("list index %s in brackets must be an integer", index)
+			}
+			fields = append(fields, index)
+			state = OutBracket
 		case InQuote:
 			if c == quoteChar {
 				fields = append(fields, s[tokenStart:i])
@@ -154,7 +191,7 @@ func splitField(s string) ([]string, error) {
 	}
 
 	switch state {
-	case InBracket, OutQuote:
+	case InBracket, OutQuote, InIndex:
 		return nil, fmt.Errorf("found unclosed left bracket")
 	case InQuote:
 		if quoteChar == '"' {
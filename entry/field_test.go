@@ -226,6 +226,18 @@ func TestSplitField(t *testing.T) {
 		{"BracketMissingQuotes", `$record[test]`, nil, true},
 		{"CharacterBetweenBracketAndQuote", `$record["test"a]`, nil, true},
 		{"CharacterOutsideBracket", `$record["test"]a`, nil, true},
+		{"Index", `$record.items[0]`, []string{"$record", "items", "0"}, false},
+		{"IndexThenDot", `$record.items[10].name`, []string{"$record", "items", "10", "name"}, false},
+		{"NegativeIndex", `$record.items[-1]`, []string{"$record", "items", "-1"}, false},
+		{"IndexThenIndex", `$record.items[0][1]`, []string{"$record", "items", "0", "1"}, false},
+		{"IndexThenBracket", `$record.items[0]["a.b"]`, []string{"$record", "items", "0", "a.b"}, false},
+		{"RootIndex", `$record[0]`, []string{"$record", "0"}, false},
+		{"IndexAtStart", `[0]`, []string{"0"}, false},
+		{"QuotedKeyWithDashes", `$record["app.kubernetes.io/name"]`, []string{"$record", "app.kubernetes.io/name"}, false},
+		{"UnclosedIndex", `$record.items[0`, nil, true},
+		{"IndexNotInteger", `$record.items[0a]`, nil, true},
+		{"IndexOnlyDash", `$record.items[-]`, nil, true},
+		{"IndexFollowedByCharacter", `$record.items[0]a`, nil, true},
 	}
 
 	for _, tc := range cases {
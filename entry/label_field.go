@@ -16,20 +16,23 @@ func (l LabelField) Get(entry *Entry) (interface{}, bool) {
 		return "", false
 	}
 	val, ok := entry.Labels[l.key]
+	if !ok {
+		return "", false
+	}
 	return val, ok
 }
 
 // Set will set the label value on an entry
 func (l LabelField) Set(entry *Entry, val interface{}) error {
 	if entry.Labels == nil {
-		entry.Labels = make(map[string]string, 1)
+		entry.Labels = make(map[string]interface{}, 1)
 	}
 
-	str, ok := val.(string)
-	if !ok {
-		return fmt.Errorf("cannot set a label to a non-string value")
+	value, err := attributeValue(val)
+	if err != nil {
+		return fmt.Errorf("cannot set label: %s", err)
 	}
-	entry.Labels[l.key] = str
+	entry.Labels[l.key] = value
 	return nil
 }
 
@@ -40,6 +43,9 @@ func (l LabelField) Delete(entry *Entry) (interface{}, bool) {
 	}
 
 	val, ok := entry.Labels[l.key]
+	if !ok {
+		return "", false
+	}
 	delete(entry.Labels, l.key)
 	return val, ok
 }
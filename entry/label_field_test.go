@@ -9,14 +9,14 @@ import (
 func TestLabelFieldGet(t *testing.T) {
 	cases := []struct {
 		name       string
-		labels     map[string]string
+		labels     map[string]interface{}
 		field      Field
 		expected   interface{}
 		expectedOK bool
 	}{
 		{
 			"Simple",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewLabelField("test"),
@@ -25,7 +25,7 @@ func TestLabelFieldGet(t *testing.T) {
 		},
 		{
 			"NonexistentKey",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewLabelField("nonexistent"),
@@ -55,31 +55,31 @@ func TestLabelFieldGet(t *testing.T) {
 func TestLabelFieldDelete(t *testing.T) {
 	cases := []struct {
 		name           string
-		labels         map[string]string
+		labels         map[string]interface{}
 		field          Field
 		expected       interface{}
 		expectedOK     bool
-		expectedLabels map[string]string
+		expectedLabels map[string]interface{}
 	}{
 		{
 			"Simple",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewLabelField("test"),
 			"val",
 			true,
-			map[string]string{},
+			map[string]interface{}{},
 		},
 		{
 			"NonexistentKey",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewLabelField("nonexistent"),
 			"",
 			false,
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 		},
@@ -107,30 +107,30 @@ func TestLabelFieldDelete(t *testing.T) {
 func TestLabelFieldSet(t *testing.T) {
 	cases := []struct {
 		name        string
-		labels      map[string]string
+		labels      map[string]interface{}
 		field       Field
 		val         interface{}
-		expected    map[string]string
+		expected    map[string]interface{}
 		expectedErr bool
 	}{
 		{
 			"Simple",
-			map[string]string{},
+			map[string]interface{}{},
 			NewLabelField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
 		},
 		{
 			"Overwrite",
-			map[string]string{
+			map[string]interface{}{
 				"test": "original",
 			},
 			NewLabelField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
@@ -140,19 +140,75 @@ func TestLabelFieldSet(t *testing.T) {
 			nil,
 			NewLabelField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
 		},
 		{
-			"NonString",
-			map[string]string{},
+			"Int",
+			map[string]interface{}{},
 			NewLabelField("test"),
 			123,
-			map[string]string{
+			map[string]interface{}{
+				"test": 123,
+			},
+			false,
+		},
+		{
+			"Float",
+			map[string]interface{}{},
+			NewLabelField("test"),
+			1.5,
+			map[string]interface{}{
+				"test": 1.5,
+			},
+			false,
+		},
+		{
+			"Bool",
+			map[string]interface{}{},
+			NewLabelField("test"),
+			true,
+			map[string]interface{}{
+				"test": true,
+			},
+			false,
+		},
+		{
+			"Bytes",
+			map[string]interface{}{},
+			NewLabelField("test"),
+			[]byte("val"),
+			map[string]interface{}{
 				"test": "val",
 			},
+			false,
+		},
+		{
+			"List",
+			map[string]interface{}{},
+			NewLabelField("test"),
+			[]int{1, 2},
+			map[string]interface{}{
+				"test": []interface{}{1, 2},
+			},
+			false,
+		},
+		{
+			"Map",
+			map[string]interface{}{},
+			NewLabelField("test"),
+			map[string]interface{}{"key": "val"},
+			nil,
+			true,
+		},
+		{
+			"NestedList",
+			map[string]interface{}{},
+			NewLabelField("test"),
+			[]interface{}{[]string{"val"}},
+			nil,
 			true,
 		},
 	}
@@ -3,6 +3,9 @@ package entry
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -22,12 +25,12 @@ func (f RecordField) Parent() RecordField {
 	return RecordField{keys}
 }
 
-// Child returns a child of the current field using the given key.
-func (f RecordField) Child(key string) RecordField {
-	child := make([]string, len(f.Keys), len(f.Keys)+1)
+// Child returns a child of the current field using the given keys.
+func (f RecordField) Child(keys ...string) RecordField {
+	child := make([]string, len(f.Keys), len(f.Keys)+len(keys))
 	copy(child, f.Keys)
-	keys := append(child, key)
-	return RecordField{keys}
+	child = append(child, keys...)
+	return RecordField{child}
 }
 
 // IsRoot returns a boolean indicating if this is a root level field.
@@ -46,12 +49,8 @@ func (f RecordField) Get(entry *Entry) (interface{}, bool) {
 	var currentValue interface{} = entry.Record
 
 	for _, key := range f.Keys {
-		currentRecord, ok := currentValue.(map[string]interface{})
-		if !ok {
-			return nil, false
-		}
-
-		currentValue, ok = currentRecord[key]
+		var ok bool
+		currentValue, ok = getChild(currentValue, key)
 		if !ok {
 			return nil, false
 		}
@@ -66,8 +65,7 @@ func (f RecordField) Get(entry *Entry) (interface{}, bool) {
 func (f RecordField) Set(entry *Entry, value interface{}) error {
 	mapValue, isMapValue := value.(map[string]interface{})
 	if isMapValue {
-		f.Merge(entry, mapValue)
-		return nil
+		return f.Merge(entry, mapValue)
 	}
 
 	if f.isRoot() {
@@ -75,38 +73,46 @@ func (f RecordField) Set(entry *Entry, value interface{}) error {
 		return nil
 	}
 
-	currentMap, ok := entry.Record.(map[string]interface{})
-	if !ok {
-		currentMap = map[string]interface{}{}
-		entry.Record = currentMap
-	}
-
-	for i, key := range f.Keys {
-		if i == len(f.Keys)-1 {
-			currentMap[key] = value
-			break
-		}
-		currentMap = f.getNestedMap(currentMap, key)
+	parent, err := f.parent(entry)
+	if err != nil {
+		return err
 	}
-	return nil
+	return setChild(parent, f.Keys[len(f.Keys)-1], value)
 }
 
 // Merge will attempt to merge the contents of a map into an entry's record.
 // It will overwrite any intermediate values as necessary.
-func (f RecordField) Merge(entry *Entry, mapValues map[string]interface{}) {
-	currentMap, ok := entry.Record.(map[string]interface{})
-	if !ok {
-		currentMap = map[string]interface{}{}
-		entry.Record = currentMap
-	}
+func (f RecordField) Merge(entry *Entry, mapValues map[string]interface{}) error {
+	var currentMap map[string]interface{}
+	if f.isRoot() {
+		var ok bool
+		currentMap, ok = entry.Record.(map[string]interface{})
+		if !ok {
+			currentMap = map[string]interface{}{}
+			entry.Record = currentMap
+		}
+	} else {
+		parent, err := f.parent(entry)
+		if err != nil {
+			return err
+		}
 
-	for _, key := range f.Keys {
-		currentMap = f.getNestedMap(currentMap, key)
+		key := f.Keys[len(f.Keys)-1]
+		currentValue, _ := getChild(parent, key)
+		var ok bool
+		currentMap, ok = currentValue.(map[string]interface{})
+		if !ok {
+			currentMap = map[string]interface{}{}
+			if err := setChild(parent, key, currentMap); err != nil {
+				return err
+			}
+		}
 	}
 
 	for key, value := range mapValues {
 		currentMap[key] = value
 	}
+	return nil
 }
 
 // Delete removes a value from an entry's record using the field.
@@ -118,42 +124,237 @@ func (f RecordField) Delete(entry *Entry) (interface{}, bool) {
 		return oldRecord, true
 	}
 
-	currentValue := entry.Record
+	record, deleted, ok := deleteChild(entry.Record, f.Keys)
+	if ok {
+		entry.Record = record
+	}
+	return deleted, ok
+}
+
+// Copy sets the value of the field in the record of source on the record of target.
+// The lists that the field passes through in source are rebuilt as lists in target,
+// holding the copied item at its index, rather than as maps keyed by the index.
+func (f RecordField) Copy(source, target *Entry) error {
+	value, ok := f.Get(source)
+	if !ok {
+		return nil
+	}
+
+	keys, lists := f.resolveKeys(source.Record)
+	record, err := setKeys(target.Record, keys, lists, value)
+	if err != nil {
+		return err
+	}
+	target.Record = record
+	return nil
+}
+
+// resolveKeys returns the keys of the field in a record, with negative list indices counted
+// from the start of their list, along with whether each key is the index of a list
+func (f RecordField) resolveKeys(record interface{}) ([]string, []bool) {
+	keys := make([]string, len(f.Keys))
+	lists := make([]bool, len(f.Keys))
+	currentValue := record
 	for i, key := range f.Keys {
-		currentMap, ok := currentValue.(map[string]interface{})
-		if !ok {
-			break
+		keys[i] = key
+		if index, err := strconv.Atoi(key); err == nil && isIndex(key) && isList(currentValue) {
+			if index < 0 {
+				keys[i] = strconv.Itoa(index + reflect.ValueOf(currentValue).Len())
+			}
+			lists[i] = true
 		}
+		currentValue, _ = getChild(currentValue, key)
+	}
+	return keys, lists
+}
 
-		currentValue, ok = currentMap[key]
+// setKeys sets the value at the keys below a container, and returns the container. Along the way,
+// a list is created for each key that is flagged as the index of a list, and a map for every other
+// key, where the container does not hold one already. Lists are extended with nil items up to the
+// index. Maps set at the last key are merged into an existing map, like Set.
+func setKeys(container interface{}, keys []string, lists []bool, value interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		currentMap, isMap := container.(map[string]interface{})
+		mapValue, isMapValue := value.(map[string]interface{})
+		if !isMap || !isMapValue {
+			return value, nil
+		}
+		for key, item := range mapValue {
+			currentMap[key] = item
+		}
+		return currentMap, nil
+	}
+
+	if !lists[0] {
+		currentMap, ok := container.(map[string]interface{})
 		if !ok {
-			break
+			currentMap = map[string]interface{}{}
 		}
+		child, err := setKeys(currentMap[keys[0]], keys[1:], lists[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		currentMap[keys[0]] = child
+		return currentMap, nil
+	}
 
-		if i == len(f.Keys)-1 {
-			delete(currentMap, key)
-			return currentValue, true
+	index, err := strconv.Atoi(keys[0])
+	if err != nil || index < 0 {
+		return nil, fmt.Errorf("list index %s is out of range", keys[0])
+	}
+
+	var list []interface{}
+	if isList(container) {
+		existing := reflect.ValueOf(container)
+		list = make([]interface{}, existing.Len())
+		for i := range list {
+			list[i] = existing.Index(i).Interface()
 		}
 	}
+	for len(list) <= index {
+		list = append(list, nil)
+	}
 
-	return nil, false
+	child, err := setKeys(list[index], keys[1:], lists[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	list[index] = child
+	return list, nil
 }
 
-// getNestedMap will get a nested map assigned to a key.
-// If the map does not exist, it will create and return it.
-func (f RecordField) getNestedMap(currentMap map[string]interface{}, key string) map[string]interface{} {
-	currentValue, ok := currentMap[key]
-	if !ok {
-		currentMap[key] = map[string]interface{}{}
+// parent returns the map or list that holds the last key of the field. Along the way,
+// maps are created for keys that do not hold a map, or a list indexed by the next key.
+func (f RecordField) parent(entry *Entry) (interface{}, error) {
+	if !isContainer(entry.Record, f.Keys[0]) {
+		entry.Record = map[string]interface{}{}
 	}
 
-	nextMap, ok := currentValue.(map[string]interface{})
+	currentValue := entry.Record
+	for i, key := range f.Keys[:len(f.Keys)-1] {
+		nextValue, _ := getChild(currentValue, key)
+		if !isContainer(nextValue, f.Keys[i+1]) {
+			nextValue = map[string]interface{}{}
+			if err := setChild(currentValue, key, nextValue); err != nil {
+				return nil, err
+			}
+		}
+		currentValue = nextValue
+	}
+
+	return currentValue, nil
+}
+
+// isContainer returns true if the value is a map, or a list that the key is an index of
+func isContainer(value interface{}, key string) bool {
+	if _, ok := value.(map[string]interface{}); ok {
+		return true
+	}
+	return isList(value) && isIndex(key)
+}
+
+// isList returns true if the value is a list of any type
+func isList(value interface{}) bool {
+	return value != nil && reflect.TypeOf(value).Kind() == reflect.Slice
+}
+
+// getChild returns the value of a key in a map, or of an index in a list
+func getChild(value interface{}, key string) (interface{}, bool) {
+	if currentMap, ok := value.(map[string]interface{}); ok {
+		child, ok := currentMap[key]
+		return child, ok
+	}
+
+	if !isList(value) {
+		return nil, false
+	}
+
+	list := reflect.ValueOf(value)
+	index, err := listIndex(list.Len(), key)
+	if err != nil {
+		return nil, false
+	}
+	return list.Index(index).Interface(), true
+}
+
+// setChild sets the value of a key in a map, or of an existing index in a list
+func setChild(container interface{}, key string, value interface{}) error {
+	if currentMap, ok := container.(map[string]interface{}); ok {
+		currentMap[key] = value
+		return nil
+	}
+
+	if !isList(container) {
+		return fmt.Errorf("cannot set key %s of value of type '%T'", key, container)
+	}
+
+	list := reflect.ValueOf(container)
+	index, err := listIndex(list.Len(), key)
+	if err != nil {
+		return err
+	}
+
+	item := list.Index(index)
+	newValue := reflect.ValueOf(value)
+	if value == nil {
+		newValue = reflect.Zero(item.Type())
+	}
+	if !newValue.Type().AssignableTo(item.Type()) {
+		return fmt.Errorf("cannot set item of list of type '%T' to value of type '%T'", container, value)
+	}
+	item.Set(newValue)
+	return nil
+}
+
+// deleteChild removes the value at the keys from a map or list. It returns the map or list
+// without the value, which is a new list when the value is removed from a list, along with
+// the removed value and whether it existed.
+func deleteChild(container interface{}, keys []string) (interface{}, interface{}, bool) {
+	child, ok := getChild(container, keys[0])
 	if !ok {
-		nextMap = map[string]interface{}{}
-		currentMap[key] = nextMap
+		return container, nil, false
+	}
+
+	if len(keys) > 1 {
+		newChild, deleted, ok := deleteChild(child, keys[1:])
+		if !ok {
+			return container, nil, false
+		}
+		if err := setChild(container, keys[0], newChild); err != nil {
+			return container, nil, false
+		}
+		return container, deleted, true
+	}
+
+	if currentMap, ok := container.(map[string]interface{}); ok {
+		delete(currentMap, keys[0])
+		return currentMap, child, true
 	}
 
-	return nextMap
+	list := reflect.ValueOf(container)
+	index, _ := listIndex(list.Len(), keys[0])
+	newList := reflect.MakeSlice(list.Type(), 0, list.Len()-1)
+	newList = reflect.AppendSlice(newList, list.Slice(0, index))
+	newList = reflect.AppendSlice(newList, list.Slice(index+1, list.Len()))
+	return newList.Interface(), child, true
+}
+
+// listIndex returns the index of a list referred to by a key. Negative
+// indices count back from the end of the list, so -1 is the last item.
+func listIndex(length int, key string) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil || !isIndex(key) {
+		return 0, fmt.Errorf("list index %s is not an integer", key)
+	}
+
+	if index < 0 {
+		index += length
+	}
+
+	if index < 0 || index >= length {
+		return 0, fmt.Errorf("list index %s is out of range for a list of length %d", key, length)
+	}
+	return index, nil
 }
 
 /****************
@@ -167,7 +368,11 @@ func (f *RecordField) UnmarshalJSON(raw []byte) error {
 		return fmt.Errorf("the field is not a string: %s", err)
 	}
 
-	*f = fromJSONDot(value)
+	field, err := fromJSONDot(value)
+	if err != nil {
+		return err
+	}
+	*f = field
 	return nil
 }
 
@@ -184,7 +389,11 @@ func (f *RecordField) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		return fmt.Errorf("the field is not a string: %s", err)
 	}
 
-	*f = fromJSONDot(value)
+	field, err := fromJSONDot(value)
+	if err != nil {
+		return err
+	}
+	*f = field
 	return nil
 }
 
@@ -194,17 +403,21 @@ func (f RecordField) MarshalYAML() (interface{}, error) {
 }
 
 // fromJSONDot creates a field from JSON dot notation.
-func fromJSONDot(value string) RecordField {
-	keys := strings.Split(value, ".")
+func fromJSONDot(value string) (RecordField, error) {
+	keys, err := splitField(value)
+	if err != nil {
+		return RecordField{}, fmt.Errorf("splitting field: %s", err)
+	}
 
-	if keys[0] == "$" || keys[0] == recordPrefix {
+	if len(keys) > 0 && (keys[0] == "$" || keys[0] == recordPrefix) {
 		keys = keys[1:]
 	}
 
-	return RecordField{keys}
+	return RecordField{keys}, nil
 }
 
 // toJSONDot returns the JSON dot notation for a field.
+// List indices are written in brackets, like `$record.items[0]`.
 func toJSONDot(field RecordField) string {
 	if field.isRoot() {
 		return recordPrefix
@@ -218,15 +431,21 @@ func toJSONDot(field RecordField) string {
 	}
 
 	var b strings.Builder
-	if containsDots {
+	if containsDots || isIndex(field.Keys[0]) {
 		b.WriteString(recordPrefix)
-		for _, key := range field.Keys {
+	}
+
+	for i, key := range field.Keys {
+		switch {
+		case isIndex(key):
+			b.WriteString("[")
+			b.WriteString(key)
+			b.WriteString("]")
+		case containsDots:
 			b.WriteString(`['`)
 			b.WriteString(key)
 			b.WriteString(`']`)
-		}
-	} else {
-		for i, key := range field.Keys {
+		default:
 			if i != 0 {
 				b.WriteString(".")
 			}
@@ -237,6 +456,15 @@ func toJSONDot(field RecordField) string {
 	return b.String()
 }
 
+// indexRegexp matches the keys that can be used as the index of a list. Other keys that
+// strconv.Atoi accepts, such as +1, are map keys, so that they are quoted when serialized.
+var indexRegexp = regexp.MustCompile(`^-?[0-9]+$`)
+
+// isIndex returns true if the key can be used as the index of a list
+func isIndex(key string) bool {
+	return indexRegexp.MatchString(key)
+}
+
 // NewRecordField creates a new field from an ordered array of keys.
 func NewRecordField(keys ...string) Field {
 	return Field{RecordField{
@@ -22,6 +22,16 @@ func nestedMap() map[string]interface{} {
 	}
 }
 
+func listRecord() map[string]interface{} {
+	return map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "first"},
+			map[string]interface{}{"name": "second"},
+		},
+		"tags": []string{"a", "b"},
+	}
+}
+
 func TestRecordFieldGet(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -79,6 +89,62 @@ func TestRecordFieldGet(t *testing.T) {
 			"raw string",
 			true,
 		},
+		{
+			"ListIndex",
+			NewRecordField("items", "0", "name"),
+			listRecord(),
+			"first",
+			true,
+		},
+		{
+			"NegativeListIndex",
+			NewRecordField("items", "-1", "name"),
+			listRecord(),
+			"second",
+			true,
+		},
+		{
+			"TypedListIndex",
+			NewRecordField("tags", "1"),
+			listRecord(),
+			"b",
+			true,
+		},
+		{
+			"ListIndexOutOfRange",
+			NewRecordField("items", "2"),
+			listRecord(),
+			nil,
+			false,
+		},
+		{
+			"NegativeListIndexOutOfRange",
+			NewRecordField("items", "-3"),
+			listRecord(),
+			nil,
+			false,
+		},
+		{
+			"ListKeyNotIndex",
+			NewRecordField("items", "name"),
+			listRecord(),
+			nil,
+			false,
+		},
+		{
+			"ListKeySigned",
+			NewRecordField("items", "+1"),
+			listRecord(),
+			nil,
+			false,
+		},
+		{
+			"RootList",
+			NewRecordField("1"),
+			[]interface{}{"a", "b"},
+			"b",
+			true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -169,6 +235,60 @@ func TestRecordFieldDelete(t *testing.T) {
 			nil,
 			false,
 		},
+		{
+			"ListItem",
+			NewRecordField("items", "0"),
+			listRecord(),
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "second"},
+				},
+				"tags": []string{"a", "b"},
+			},
+			map[string]interface{}{"name": "first"},
+			true,
+		},
+		{
+			"NegativeTypedListItem",
+			NewRecordField("tags", "-1"),
+			listRecord(),
+			map[string]interface{}{
+				"items": listRecord()["items"],
+				"tags":  []string{"a"},
+			},
+			"b",
+			true,
+		},
+		{
+			"KeyInListItem",
+			NewRecordField("items", "1", "name"),
+			listRecord(),
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "first"},
+					map[string]interface{}{},
+				},
+				"tags": []string{"a", "b"},
+			},
+			"second",
+			true,
+		},
+		{
+			"ListIndexOutOfRange",
+			NewRecordField("items", "5"),
+			listRecord(),
+			listRecord(),
+			nil,
+			false,
+		},
+		{
+			"RootListItem",
+			NewRecordField("0"),
+			[]interface{}{"a", "b"},
+			[]interface{}{"b"},
+			"a",
+			true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -176,8 +296,10 @@ func TestRecordFieldDelete(t *testing.T) {
 			entry := New()
 			entry.Record = tc.record
 
-			entry.Delete(tc.field)
+			deleted, ok := entry.Delete(tc.field)
 			assert.Equal(t, tc.expectedRecord, entry.Record)
+			assert.Equal(t, tc.expectedReturned, deleted)
+			assert.Equal(t, tc.expectedOk, ok)
 		})
 	}
 }
@@ -273,6 +395,116 @@ func TestRecordFieldSet(t *testing.T) {
 	}
 }
 
+func TestRecordFieldSetList(t *testing.T) {
+	cases := []struct {
+		name        string
+		field       Field
+		setTo       interface{}
+		expectedVal interface{}
+	}{
+		{
+			"ListItem",
+			NewRecordField("items", "1"),
+			"new_value",
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "first"},
+					"new_value",
+				},
+				"tags": []string{"a", "b"},
+			},
+		},
+		{
+			"KeyInListItem",
+			NewRecordField("items", "-2", "name"),
+			"new_value",
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "new_value"},
+					map[string]interface{}{"name": "second"},
+				},
+				"tags": []string{"a", "b"},
+			},
+		},
+		{
+			"NewKeyInListItem",
+			NewRecordField("items", "0", "new_key", "nested_key"),
+			"new_value",
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{
+						"name":    "first",
+						"new_key": map[string]interface{}{"nested_key": "new_value"},
+					},
+					map[string]interface{}{"name": "second"},
+				},
+				"tags": []string{"a", "b"},
+			},
+		},
+		{
+			"MergedListItem",
+			NewRecordField("items", "1"),
+			map[string]interface{}{"merged_key": "merged_value"},
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "first"},
+					map[string]interface{}{"name": "second", "merged_key": "merged_value"},
+				},
+				"tags": []string{"a", "b"},
+			},
+		},
+		{
+			"TypedListItem",
+			NewRecordField("tags", "0"),
+			"c",
+			map[string]interface{}{
+				"items": listRecord()["items"],
+				"tags":  []string{"c", "b"},
+			},
+		},
+		{
+			"ListKeyNotIndex",
+			NewRecordField("items", "name"),
+			"new_value",
+			map[string]interface{}{
+				"items": map[string]interface{}{"name": "new_value"},
+				"tags":  []string{"a", "b"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := New()
+			entry.Record = listRecord()
+			require.NoError(t, entry.Set(tc.field, tc.setTo))
+			assert.Equal(t, tc.expectedVal, entry.Record)
+		})
+	}
+}
+
+func TestRecordFieldSetListInvalid(t *testing.T) {
+	cases := []struct {
+		name  string
+		field Field
+		setTo interface{}
+	}{
+		{"IndexOutOfRange", NewRecordField("items", "2"), "new_value"},
+		{"NestedIndexOutOfRange", NewRecordField("items", "-3", "name"), "new_value"},
+		{"MergeIndexOutOfRange", NewRecordField("items", "2"), map[string]interface{}{"key": "value"}},
+		{"TypedListWrongType", NewRecordField("tags", "0"), 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := New()
+			entry.Record = listRecord()
+			require.Error(t, entry.Set(tc.field, tc.setTo))
+			assert.Equal(t, listRecord(), entry.Record)
+		})
+	}
+}
+
 func TestRecordFieldParent(t *testing.T) {
 	t.Run("Simple", func(t *testing.T) {
 		field := RecordField{[]string{"child"}}
@@ -346,9 +578,94 @@ func TestRecordFieldUnmarshalYAMLFailure(t *testing.T) {
 	require.Contains(t, err.Error(), "the field is not a string: yaml")
 }
 
+func TestRecordFieldToJSONDot(t *testing.T) {
+	cases := []struct {
+		name     string
+		field    RecordField
+		expected string
+	}{
+		{"Root", RecordField{[]string{}}, "$record"},
+		{"Simple", RecordField{[]string{"test"}}, "test"},
+		{"Nested", RecordField{[]string{"test", "nested"}}, "test.nested"},
+		{"Index", RecordField{[]string{"items", "0", "name"}}, "items[0].name"},
+		{"NegativeIndex", RecordField{[]string{"items", "-1"}}, "items[-1]"},
+		{"RootIndex", RecordField{[]string{"0", "name"}}, "$record[0].name"},
+		{"Dots", RecordField{[]string{"k8s.labels", "app"}}, "$record['k8s.labels']['app']"},
+		{"DotsAndIndex", RecordField{[]string{"k8s.labels", "0"}}, "$record['k8s.labels'][0]"},
+		{"SignedKey", RecordField{[]string{"items", "+1"}}, "items.+1"},
+		{"DotsAndSignedKey", RecordField{[]string{"k8s.labels", "+1"}}, "$record['k8s.labels']['+1']"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, toJSONDot(tc.field))
+
+			field, err := fromJSONDot(tc.expected)
+			require.NoError(t, err)
+			require.Equal(t, tc.field, field)
+		})
+	}
+}
+
+func TestRecordFieldFromJSONDotInvalid(t *testing.T) {
+	_, err := fromJSONDot("$record[test]")
+	require.Error(t, err)
+
+	_, err = fromJSONDot("$record[+1]")
+	require.Error(t, err)
+}
+
 func TestRecordFieldFromJSONDot(t *testing.T) {
 	jsonDot := "$.test"
-	recordField := fromJSONDot(jsonDot)
+	recordField, err := fromJSONDot(jsonDot)
+	require.NoError(t, err)
 	expectedField := RecordField{Keys: []string{"test"}}
 	require.Equal(t, expectedField, recordField)
 }
+
+func TestRecordFieldCopy(t *testing.T) {
+	cases := []struct {
+		name     string
+		fields   []RecordField
+		expected interface{}
+	}{
+		{
+			"Key",
+			[]RecordField{{[]string{"items"}}},
+			map[string]interface{}{"items": listRecord()["items"]},
+		},
+		{
+			"KeyInListItems",
+			[]RecordField{{[]string{"items", "1", "name"}}, {[]string{"items", "0", "name"}}},
+			map[string]interface{}{
+				"items": []interface{}{
+					map[string]interface{}{"name": "first"},
+					map[string]interface{}{"name": "second"},
+				},
+			},
+		},
+		{
+			"NegativeIndex",
+			[]RecordField{{[]string{"tags", "-1"}}},
+			map[string]interface{}{"tags": []interface{}{nil, "b"}},
+		},
+		{
+			"Missing",
+			[]RecordField{{[]string{"items", "5"}}},
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := New()
+			source.Record = listRecord()
+			target := New()
+			for _, field := range tc.fields {
+				require.NoError(t, field.Copy(source, target))
+			}
+			require.Equal(t, tc.expected, target.Record)
+			require.Equal(t, listRecord(), source.Record)
+		})
+	}
+}
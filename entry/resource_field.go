@@ -16,20 +16,23 @@ func (r ResourceField) Get(entry *Entry) (interface{}, bool) {
 		return "", false
 	}
 	val, ok := entry.Resource[r.key]
+	if !ok {
+		return "", false
+	}
 	return val, ok
 }
 
 // Set will set the resource value on an entry
 func (r ResourceField) Set(entry *Entry, val interface{}) error {
 	if entry.Resource == nil {
-		entry.Resource = make(map[string]string, 1)
+		entry.Resource = make(map[string]interface{}, 1)
 	}
 
-	str, ok := val.(string)
-	if !ok {
-		return fmt.Errorf("cannot set a resource to a non-string value")
+	value, err := attributeValue(val)
+	if err != nil {
+		return fmt.Errorf("cannot set resource: %s", err)
 	}
-	entry.Resource[r.key] = str
+	entry.Resource[r.key] = value
 	return nil
 }
 
@@ -40,6 +43,9 @@ func (r ResourceField) Delete(entry *Entry) (interface{}, bool) {
 	}
 
 	val, ok := entry.Resource[r.key]
+	if !ok {
+		return "", false
+	}
 	delete(entry.Resource, r.key)
 	return val, ok
 }
@@ -9,14 +9,14 @@ import (
 func TestResourceFieldGet(t *testing.T) {
 	cases := []struct {
 		name       string
-		resources  map[string]string
+		resources  map[string]interface{}
 		field      Field
 		expected   interface{}
 		expectedOK bool
 	}{
 		{
 			"Simple",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewResourceField("test"),
@@ -25,7 +25,7 @@ func TestResourceFieldGet(t *testing.T) {
 		},
 		{
 			"NonexistentKey",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewResourceField("nonexistent"),
@@ -55,31 +55,31 @@ func TestResourceFieldGet(t *testing.T) {
 func TestResourceFieldDelete(t *testing.T) {
 	cases := []struct {
 		name              string
-		resources         map[string]string
+		resources         map[string]interface{}
 		field             Field
 		expected          interface{}
 		expectedOK        bool
-		expectedResources map[string]string
+		expectedResources map[string]interface{}
 	}{
 		{
 			"Simple",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewResourceField("test"),
 			"val",
 			true,
-			map[string]string{},
+			map[string]interface{}{},
 		},
 		{
 			"NonexistentKey",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			NewResourceField("nonexistent"),
 			"",
 			false,
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 		},
@@ -107,30 +107,30 @@ func TestResourceFieldDelete(t *testing.T) {
 func TestResourceFieldSet(t *testing.T) {
 	cases := []struct {
 		name        string
-		resources   map[string]string
+		resources   map[string]interface{}
 		field       Field
 		val         interface{}
-		expected    map[string]string
+		expected    map[string]interface{}
 		expectedErr bool
 	}{
 		{
 			"Simple",
-			map[string]string{},
+			map[string]interface{}{},
 			NewResourceField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
 		},
 		{
 			"Overwrite",
-			map[string]string{
+			map[string]interface{}{
 				"test": "original",
 			},
 			NewResourceField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
@@ -140,19 +140,75 @@ func TestResourceFieldSet(t *testing.T) {
 			nil,
 			NewResourceField("test"),
 			"val",
-			map[string]string{
+			map[string]interface{}{
 				"test": "val",
 			},
 			false,
 		},
 		{
-			"NonString",
-			map[string]string{},
+			"Int",
+			map[string]interface{}{},
 			NewResourceField("test"),
 			123,
-			map[string]string{
+			map[string]interface{}{
+				"test": 123,
+			},
+			false,
+		},
+		{
+			"Float",
+			map[string]interface{}{},
+			NewResourceField("test"),
+			1.5,
+			map[string]interface{}{
+				"test": 1.5,
+			},
+			false,
+		},
+		{
+			"Bool",
+			map[string]interface{}{},
+			NewResourceField("test"),
+			true,
+			map[string]interface{}{
+				"test": true,
+			},
+			false,
+		},
+		{
+			"Bytes",
+			map[string]interface{}{},
+			NewResourceField("test"),
+			[]byte("val"),
+			map[string]interface{}{
 				"test": "val",
 			},
+			false,
+		},
+		{
+			"List",
+			map[string]interface{}{},
+			NewResourceField("test"),
+			[]int{1, 2},
+			map[string]interface{}{
+				"test": []interface{}{1, 2},
+			},
+			false,
+		},
+		{
+			"Map",
+			map[string]interface{}{},
+			NewResourceField("test"),
+			map[string]interface{}{"key": "val"},
+			nil,
+			true,
+		},
+		{
+			"NestedList",
+			map[string]interface{}{},
+			NewResourceField("test"),
+			[]interface{}{[]string{"val"}},
+			nil,
 			true,
 		},
 	}
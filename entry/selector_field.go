@@ -0,0 +1,269 @@
+package entry
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// anyKey matches any single key of a map, or index of a list
+	anyKey = "*"
+	// anyPath matches any number of nested keys, including none
+	anyPath = "**"
+)
+
+// SelectorField is a field that uses wildcards to select any number of fields of an entry.
+// A key of `*` matches any key of a map or index of a list, a key of `**` matches any
+// number of nested keys, and a `*` within a key matches any characters in a key.
+type SelectorField struct {
+	prefix string
+	keys   []string
+}
+
+// isSelector returns true if any of the keys of a field contain a wildcard
+func isSelector(keys []string) bool {
+	for _, key := range keys {
+		if strings.Contains(key, anyKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// newSelectorField creates a selector field for the record, labels or resource
+func newSelectorField(prefix string, keys []string) (SelectorField, error) {
+	if prefix != recordPrefix {
+		if len(keys) != 1 {
+			return SelectorField{}, fmt.Errorf("%s selectors cannot be nested", prefix)
+		}
+		if keys[0] == anyPath {
+			keys = []string{anyKey}
+		}
+	}
+	return SelectorField{prefix: prefix, keys: keys}, nil
+}
+
+// Select returns the fields of an entry that match the selector, in the order they appear
+// in the entry. Nested fields follow their parents, and list items follow their index, so
+// deleting the fields in reverse order never affects the fields that are left to delete.
+func (s SelectorField) Select(entry *Entry) []Field {
+	switch s.prefix {
+	case labelsPrefix:
+		return s.selectKeys(entry.Labels, func(key string) Field { return Field{LabelField{key}} })
+	case resourcePrefix:
+		return s.selectKeys(entry.Resource, func(key string) Field { return Field{ResourceField{key}} })
+	}
+
+	matches := make([]Field, 0)
+	seen := make(map[string]bool)
+	selectRecord(entry.Record, []string{}, s.keys, func(keys []string) {
+		field := RecordField{keys}
+		if !seen[field.String()] {
+			seen[field.String()] = true
+			matches = append(matches, Field{field})
+		}
+	})
+	return matches
+}
+
+// selectKeys returns the fields of the keys of the labels or resource that match the selector
+func (s SelectorField) selectKeys(values map[string]interface{}, newField func(string) Field) []Field {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if matchKey(s.keys[0], key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	matches := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		matches = append(matches, newField(key))
+	}
+	return matches
+}
+
+// selectRecord walks the value, calling found with the keys of each nested value that matches the patterns
+func selectRecord(value interface{}, keys []string, patterns []string, found func([]string)) {
+	if len(patterns) == 0 {
+		found(append([]string{}, keys...))
+		return
+	}
+
+	pattern := patterns[0]
+	if pattern == anyPath {
+		selectRecord(value, keys, patterns[1:], found)
+		for _, child := range childKeys(value) {
+			next, _ := getChild(value, child)
+			selectRecord(next, append(keys, child), patterns, found)
+		}
+		return
+	}
+
+	for _, child := range childKeys(value) {
+		if matchKey(pattern, child) {
+			next, _ := getChild(value, child)
+			selectRecord(next, append(keys, child), patterns[1:], found)
+		}
+	}
+}
+
+// childKeys returns the sorted keys of a map, or the indices of a list
+func childKeys(value interface{}) []string {
+	if currentMap, ok := value.(map[string]interface{}); ok {
+		keys := make([]string, 0, len(currentMap))
+		for key := range currentMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	if !isList(value) {
+		return nil
+	}
+
+	length := reflect.ValueOf(value).Len()
+	keys := make([]string, 0, length)
+	for i := 0; i < length; i++ {
+		keys = append(keys, strconv.Itoa(i))
+	}
+	return keys
+}
+
+// matchKey returns true if the key matches a pattern, where each `*` matches any characters
+func matchKey(pattern, key string) bool {
+	parts := strings.Split(pattern, anyKey)
+	if len(parts) == 1 {
+		return pattern == key
+	}
+
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+	key = key[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(key, part)
+		if index == -1 {
+			return false
+		}
+		key = key[index+len(part):]
+	}
+	return len(key) >= len(last) && strings.HasSuffix(key, last)
+}
+
+// Trim returns the keys of a selected field that follow the keys of the selector that come
+// before its first wildcard. For `$record.user.*.password`, the field `$record.user.a.password`
+// is trimmed to the keys `a` and `password`. Label and resource fields are trimmed to their key.
+func (s SelectorField) Trim(field Field) []string {
+	switch typed := field.FieldInterface.(type) {
+	case LabelField:
+		return []string{typed.key}
+	case ResourceField:
+		return []string{typed.key}
+	case RecordField:
+		fixed := 0
+		for _, key := range s.keys {
+			if strings.Contains(key, anyKey) {
+				break
+			}
+			fixed++
+		}
+		if fixed > len(typed.Keys) {
+			fixed = len(typed.Keys)
+		}
+		return typed.Keys[fixed:]
+	default:
+		return nil
+	}
+}
+
+// Place sets a value at the field `to`, followed by the keys that Trim returns for a field selected
+// from the entry. The lists that the selected field passes through are rebuilt as lists below `to`,
+// holding the value at its index, rather than as maps keyed by the index. Since only the parents of
+// the selected field are looked at, it may already have been deleted from the entry.
+func (s SelectorField) Place(entry *Entry, to RecordField, field Field, value interface{}) error {
+	keys := s.Trim(field)
+	lists := make([]bool, len(keys))
+	if recordField, ok := field.FieldInterface.(RecordField); ok {
+		allKeys, allLists := recordField.resolveKeys(entry.Record)
+		keys = allKeys[len(allKeys)-len(keys):]
+		lists = allLists[len(allLists)-len(keys):]
+	}
+
+	existing, _ := to.Get(entry)
+	newValue, err := setKeys(existing, keys, lists, value)
+	if err != nil {
+		return err
+	}
+	return to.Set(entry, newValue)
+}
+
+// Get will return the value of the first field of the entry that matches the selector
+func (s SelectorField) Get(entry *Entry) (interface{}, bool) {
+	matches := s.Select(entry)
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0].Get(entry)
+}
+
+// Set will set every field of the entry that matches the selector to the value
+func (s SelectorField) Set(entry *Entry, value interface{}) error {
+	for _, field := range s.Select(entry) {
+		if err := field.Set(entry, copyValue(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete will delete every field of the entry that matches the selector. It will
+// return the value of the first field deleted, and whether any field was deleted.
+func (s SelectorField) Delete(entry *Entry) (interface{}, bool) {
+	matches := s.Select(entry)
+	var first interface{}
+	for i := len(matches) - 1; i >= 0; i-- {
+		first, _ = matches[i].Delete(entry)
+	}
+	return first, len(matches) > 0
+}
+
+// String returns the string representation of the selector
+func (s SelectorField) String() string {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	for _, key := range s.keys {
+		if strings.Contains(key, ".") {
+			b.WriteString(`['`)
+			b.WriteString(key)
+			b.WriteString(`']`)
+			continue
+		}
+		b.WriteString(".")
+		b.WriteString(key)
+	}
+	return b.String()
+}
+
+// NewRecordSelector creates a new selector field from an ordered array of record keys and patterns.
+func NewRecordSelector(keys ...string) Field {
+	return Field{SelectorField{recordPrefix, keys}}
+}
+
+// NewLabelSelector creates a new selector field for the labels that match a pattern.
+func NewLabelSelector(pattern string) Field {
+	selector, _ := newSelectorField(labelsPrefix, []string{pattern})
+	return Field{selector}
+}
+
+// NewResourceSelector creates a new selector field for the resource keys that match a pattern.
+func NewResourceSelector(pattern string) Field {
+	selector, _ := newSelectorField(resourcePrefix, []string{pattern})
+	return Field{selector}
+}
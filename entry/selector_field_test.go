@@ -0,0 +1,270 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func selectorRecord() map[string]interface{} {
+	return map[string]interface{}{
+		"password": "root",
+		"user": map[string]interface{}{
+			"name":     "test",
+			"password": "secret",
+			"session": map[string]interface{}{
+				"token": "abc",
+			},
+		},
+		"services": []interface{}{
+			map[string]interface{}{"name": "a", "token": "def"},
+			map[string]interface{}{"name": "b"},
+		},
+	}
+}
+
+func TestSelectorFieldFromString(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected Field
+	}{
+		{"$record.*.password", NewRecordSelector("*", "password")},
+		{"*.password", NewRecordSelector("*", "password")},
+		{"$.**.token", NewRecordSelector("**", "token")},
+		{"$record.user.pass*", NewRecordSelector("user", "pass*")},
+		{"$labels.k8s_*", NewLabelSelector("k8s_*")},
+		{`$labels["app.kubernetes.io/*"]`, NewLabelSelector("app.kubernetes.io/*")},
+		{"$labels.**", NewLabelSelector("*")},
+		{"$resource.*", NewResourceSelector("*")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			field, err := fieldFromString(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, field)
+		})
+	}
+
+	for _, invalid := range []string{"$labels.k8s_*.name", "$resource.*.*"} {
+		_, err := fieldFromString(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestSelectorFieldString(t *testing.T) {
+	require.Equal(t, "$record.*.password", NewRecordSelector("*", "password").String())
+	require.Equal(t, "$record.**.token", NewRecordSelector("**", "token").String())
+	require.Equal(t, "$labels.k8s_*", NewLabelSelector("k8s_*").String())
+	require.Equal(t, "$resource['app.kubernetes.io/*']", NewResourceSelector("app.kubernetes.io/*").String())
+}
+
+func TestSelectorFieldSelect(t *testing.T) {
+	cases := []struct {
+		name     string
+		selector Field
+		expected []Field
+	}{
+		{
+			"AnyKey",
+			NewRecordSelector("*", "password"),
+			[]Field{NewRecordField("user", "password")},
+		},
+		{
+			"AnyPath",
+			NewRecordSelector("**", "token"),
+			[]Field{
+				NewRecordField("services", "0", "token"),
+				NewRecordField("user", "session", "token"),
+			},
+		},
+		{
+			"AnyPathIncludesRoot",
+			NewRecordSelector("**", "password"),
+			[]Field{
+				NewRecordField("password"),
+				NewRecordField("user", "password"),
+			},
+		},
+		{
+			"ListItems",
+			NewRecordSelector("services", "*", "name"),
+			[]Field{
+				NewRecordField("services", "0", "name"),
+				NewRecordField("services", "1", "name"),
+			},
+		},
+		{
+			"KeyPattern",
+			NewRecordSelector("user", "*s*"),
+			[]Field{
+				NewRecordField("user", "password"),
+				NewRecordField("user", "session"),
+			},
+		},
+		{
+			"AllKeys",
+			NewRecordSelector("user", "*"),
+			[]Field{
+				NewRecordField("user", "name"),
+				NewRecordField("user", "password"),
+				NewRecordField("user", "session"),
+			},
+		},
+		{
+			"NoMatches",
+			NewRecordSelector("**", "missing"),
+			[]Field{},
+		},
+		{
+			"Labels",
+			NewLabelSelector("k8s_*"),
+			[]Field{NewLabelField("k8s_namespace"), NewLabelField("k8s_pod")},
+		},
+		{
+			"Resource",
+			NewResourceSelector("*"),
+			[]Field{NewResourceField("host")},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := New()
+			entry.Record = selectorRecord()
+			entry.Labels = map[string]interface{}{
+				"k8s_pod":       "pod",
+				"k8s_namespace": "namespace",
+				"app":           "app",
+			}
+			entry.Resource = map[string]interface{}{"host": "host"}
+
+			selector := tc.selector.FieldInterface.(SelectorField)
+			require.Equal(t, tc.expected, selector.Select(entry))
+		})
+	}
+}
+
+func TestSelectorFieldTrim(t *testing.T) {
+	selector := NewRecordSelector("user", "*", "token").FieldInterface.(SelectorField)
+	require.Equal(t, []string{"session", "token"}, selector.Trim(NewRecordField("user", "session", "token")))
+
+	selector = NewLabelSelector("k8s_*").FieldInterface.(SelectorField)
+	require.Equal(t, []string{"k8s_pod"}, selector.Trim(NewLabelField("k8s_pod")))
+}
+
+func TestSelectorFieldGet(t *testing.T) {
+	entry := New()
+	entry.Record = selectorRecord()
+
+	val, ok := entry.Get(NewRecordSelector("**", "token"))
+	require.True(t, ok)
+	require.Equal(t, "def", val)
+
+	_, ok = entry.Get(NewRecordSelector("**", "missing"))
+	require.False(t, ok)
+}
+
+func TestSelectorFieldSet(t *testing.T) {
+	entry := New()
+	entry.Record = selectorRecord()
+
+	require.NoError(t, entry.Set(NewRecordSelector("**", "password"), "***"))
+
+	expected := selectorRecord()
+	expected["password"] = "***"
+	expected["user"].(map[string]interface{})["password"] = "***"
+	require.Equal(t, expected, entry.Record)
+}
+
+func TestSelectorFieldDelete(t *testing.T) {
+	entry := New()
+	entry.Record = selectorRecord()
+
+	val, ok := entry.Delete(NewRecordSelector("**", "token"))
+	require.True(t, ok)
+	require.Equal(t, "def", val)
+
+	expected := selectorRecord()
+	delete(expected["services"].([]interface{})[0].(map[string]interface{}), "token")
+	delete(expected["user"].(map[string]interface{})["session"].(map[string]interface{}), "token")
+	require.Equal(t, expected, entry.Record)
+
+	_, ok = entry.Delete(NewRecordSelector("**", "token"))
+	require.False(t, ok)
+}
+
+func TestSelectorFieldDeleteListItems(t *testing.T) {
+	entry := New()
+	entry.Record = map[string]interface{}{
+		"items": []interface{}{"a", "b", "c"},
+	}
+
+	_, ok := entry.Delete(NewRecordSelector("items", "*"))
+	require.True(t, ok)
+	require.Equal(t, map[string]interface{}{"items": []interface{}{}}, entry.Record)
+}
+
+func TestSelectorFieldPlace(t *testing.T) {
+	entry := New()
+	entry.Record = selectorRecord()
+
+	selector := NewRecordSelector("services", "*", "name").FieldInterface.(SelectorField)
+	to := NewRecordField("names").FieldInterface.(RecordField)
+	for _, field := range selector.Select(entry) {
+		val, _ := field.Get(entry)
+		require.NoError(t, selector.Place(entry, to, field, val))
+	}
+
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"name": "a"},
+		map[string]interface{}{"name": "b"},
+	}, entry.Record.(map[string]interface{})["names"])
+}
+
+func TestSelectorFieldPlaceDeleted(t *testing.T) {
+	entry := New()
+	entry.Record = map[string]interface{}{
+		"items": []string{"a", "b", "c"},
+	}
+
+	// List items are placed at their index, even after they are deleted in reverse order
+	selector := NewRecordSelector("items", "*").FieldInterface.(SelectorField)
+	to := NewRecordField("moved").FieldInterface.(RecordField)
+	matches := selector.Select(entry)
+	for i := len(matches) - 1; i >= 0; i-- {
+		val, _ := matches[i].Delete(entry)
+		require.NoError(t, selector.Place(entry, to, matches[i], val))
+	}
+
+	require.Equal(t, map[string]interface{}{
+		"items": []string{},
+		"moved": []interface{}{"a", "b", "c"},
+	}, entry.Record)
+}
+
+func TestMatchKey(t *testing.T) {
+	cases := []struct {
+		pattern string
+		key     string
+		matches bool
+	}{
+		{"*", "anything", true},
+		{"*", "", true},
+		{"k8s_*", "k8s_pod", true},
+		{"k8s_*", "k8s_", true},
+		{"k8s_*", "app", false},
+		{"*_id", "trace_id", true},
+		{"*_id", "trace_ids", false},
+		{"a*b*c", "aXbYc", true},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "acb", false},
+		{"ab*ba", "aba", false},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.matches, matchKey(tc.pattern, tc.key), "%s %s", tc.pattern, tc.key)
+	}
+}
@@ -0,0 +1,100 @@
+package entry
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	traceIDName    = "$trace_id"
+	spanIDName     = "$span_id"
+	traceFlagsName = "$trace_flags"
+)
+
+// TraceField is the path to the trace ID, span ID or trace flags of an entry
+type TraceField struct {
+	name string
+}
+
+// Get will return the bytes of the trace field and a boolean indicating if it is set
+func (t TraceField) Get(entry *Entry) (interface{}, bool) {
+	value := *t.bytes(entry)
+	if len(value) == 0 {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set will set the trace field on an entry from bytes or a hex encoded string
+func (t TraceField) Set(entry *Entry, val interface{}) error {
+	var value []byte
+	switch typed := val.(type) {
+	case []byte:
+		value = typed
+	case string:
+		decoded, err := hex.DecodeString(typed)
+		if err != nil {
+			return fmt.Errorf("cannot set %s: %s is not a hex encoded string", t.name, typed)
+		}
+		value = decoded
+	default:
+		return fmt.Errorf("cannot set %s to a value of type '%T'", t.name, val)
+	}
+
+	if len(value) != t.size() {
+		return fmt.Errorf("cannot set %s: expected %d bytes, but got %d", t.name, t.size(), len(value))
+	}
+
+	*t.bytes(entry) = value
+	return nil
+}
+
+// Delete will unset the trace field of an entry
+func (t TraceField) Delete(entry *Entry) (interface{}, bool) {
+	value, ok := t.Get(entry)
+	*t.bytes(entry) = nil
+	return value, ok
+}
+
+func (t TraceField) String() string {
+	return t.name
+}
+
+// bytes returns a pointer to the bytes of the entry that the trace field refers to
+func (t TraceField) bytes(entry *Entry) *[]byte {
+	switch t.name {
+	case traceIDName:
+		return &entry.TraceID
+	case spanIDName:
+		return &entry.SpanID
+	default:
+		return &entry.TraceFlags
+	}
+}
+
+// size returns the number of bytes in the value of the trace field
+func (t TraceField) size() int {
+	switch t.name {
+	case traceIDName:
+		return 16
+	case spanIDName:
+		return 8
+	default:
+		return 1
+	}
+}
+
+// NewTraceIDField will create a new field for the trace ID of an entry
+func NewTraceIDField() Field {
+	return Field{TraceField{traceIDName}}
+}
+
+// NewSpanIDField will create a new field for the span ID of an entry
+func NewSpanIDField() Field {
+	return Field{TraceField{spanIDName}}
+}
+
+// NewTraceFlagsField will create a new field for the trace flags of an entry
+func NewTraceFlagsField() Field {
+	return Field{TraceField{traceFlagsName}}
+}
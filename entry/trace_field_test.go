@@ -0,0 +1,114 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceFieldGet(t *testing.T) {
+	entry := New()
+	_, ok := entry.Get(NewTraceIDField())
+	require.False(t, ok)
+
+	entry.TraceID = []byte{0x01}
+	entry.SpanID = []byte{0x02}
+	entry.TraceFlags = []byte{0x03}
+
+	cases := []struct {
+		field    Field
+		expected []byte
+	}{
+		{NewTraceIDField(), []byte{0x01}},
+		{NewSpanIDField(), []byte{0x02}},
+		{NewTraceFlagsField(), []byte{0x03}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.field.String(), func(t *testing.T) {
+			val, ok := entry.Get(tc.field)
+			require.True(t, ok)
+			require.Equal(t, tc.expected, val)
+		})
+	}
+}
+
+func TestTraceFieldSet(t *testing.T) {
+	cases := []struct {
+		name     string
+		field    Field
+		val      interface{}
+		expected func(*Entry) []byte
+	}{
+		{
+			"TraceIDHex",
+			NewTraceIDField(),
+			"4bf92f3577b34da6a3ce929d0e0e4736",
+			func(e *Entry) []byte { return e.TraceID },
+		},
+		{
+			"SpanIDBytes",
+			NewSpanIDField(),
+			[]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			func(e *Entry) []byte { return e.SpanID },
+		},
+		{
+			"TraceFlagsHex",
+			NewTraceFlagsField(),
+			"01",
+			func(e *Entry) []byte { return e.TraceFlags },
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := New()
+			require.NoError(t, entry.Set(tc.field, tc.val))
+
+			val, ok := entry.Get(tc.field)
+			require.True(t, ok)
+			require.Equal(t, tc.expected(entry), val)
+		})
+	}
+}
+
+func TestTraceFieldSetInvalid(t *testing.T) {
+	cases := []struct {
+		name  string
+		field Field
+		val   interface{}
+	}{
+		{"NotHex", NewTraceIDField(), "not hex"},
+		{"TooShort", NewTraceIDField(), "4bf92f35"},
+		{"TooLong", NewTraceFlagsField(), []byte{0x01, 0x02}},
+		{"WrongType", NewSpanIDField(), 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := New()
+			require.Error(t, entry.Set(tc.field, tc.val))
+			_, ok := entry.Get(tc.field)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestTraceFieldDelete(t *testing.T) {
+	entry := New()
+	entry.SpanID = []byte{0x02}
+
+	val, ok := entry.Delete(NewSpanIDField())
+	require.True(t, ok)
+	require.Equal(t, []byte{0x02}, val)
+	require.Nil(t, entry.SpanID)
+
+	_, ok = entry.Delete(NewSpanIDField())
+	require.False(t, ok)
+}
+
+func TestTraceFieldString(t *testing.T) {
+	require.Equal(t, "$trace_id", NewTraceIDField().String())
+	require.Equal(t, "$span_id", NewSpanIDField().String())
+	require.Equal(t, "$trace_flags", NewTraceFlagsField().String())
+}
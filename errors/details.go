@@ -1,6 +1,9 @@
 package errors
 
-import "go.uber.org/zap/zapcore"
+import (
+	"github.com/observiq/stanza/secret"
+	"go.uber.org/zap/zapcore"
+)
 
 // ErrorDetails is a map of details for an agent error.
 type ErrorDetails map[string]string
@@ -8,11 +11,20 @@ type ErrorDetails map[string]string
 // MarshalLogObject will define the representation of details when logging.
 func (d ErrorDetails) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 	for key, value := range d {
-		encoder.AddString(key, value)
+		encoder.AddString(key, secret.Redact(value))
 	}
 	return nil
 }
 
+// redacted returns a copy of the details with any secrets from the config redacted.
+func (d ErrorDetails) redacted() ErrorDetails {
+	redacted := make(ErrorDetails, len(d))
+	for key, value := range d {
+		redacted[key] = secret.Redact(value)
+	}
+	return redacted
+}
+
 // createDetails will create details for an error from key/value pairs.
 func createDetails(keyValues []string) ErrorDetails {
 	details := make(ErrorDetails)
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/observiq/stanza/secret"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -14,21 +15,21 @@ type AgentError struct {
 	Details     ErrorDetails
 }
 
-// Error will return the error message.
+// Error will return the error message, with any secrets from the config redacted.
 func (e AgentError) Error() string {
 	if len(e.Details) == 0 {
-		return e.Description
+		return secret.Redact(e.Description)
 	}
-	marshalled, _ := json.Marshal(e.Details)
-	return fmt.Sprintf("%s: %s", e.Description, string(marshalled))
+	marshalled, _ := json.Marshal(e.Details.redacted())
+	return fmt.Sprintf("%s: %s", secret.Redact(e.Description), string(marshalled))
 }
 
 // MarshalLogObject will define the representation of this error when logging.
 func (e AgentError) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
-	encoder.AddString("description", e.Description)
+	encoder.AddString("description", secret.Redact(e.Description))
 
 	if e.Suggestion != "" {
-		encoder.AddString("suggestion", e.Suggestion)
+		encoder.AddString("suggestion", secret.Redact(e.Suggestion))
 	}
 
 	if len(e.Details) != 0 {
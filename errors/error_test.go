@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/observiq/stanza/secret"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -99,3 +100,17 @@ func TestMarshalLogObject(t *testing.T) {
 		require.Equal(t, expected, out.String())
 	})
 }
+
+func TestErrorRedactsSecrets(t *testing.T) {
+	t.Setenv("STANZA_ERRORS_TEST_SECRET", "errors_secret_value")
+	_, err := secret.Expand([]byte("api_key: ${env:STANZA_ERRORS_TEST_SECRET}"))
+	require.NoError(t, err)
+
+	agentErr := NewError("failed with errors_secret_value", "", "api_key", "errors_secret_value")
+	require.Equal(t, `failed with [REDACTED]: {"api_key":"[REDACTED]"}`, agentErr.Error())
+	require.Equal(t, "errors_secret_value", agentErr.Details["api_key"])
+
+	encoder := zapcore.NewMapObjectEncoder()
+	require.NoError(t, agentErr.MarshalLogObject(encoder))
+	require.Equal(t, "failed with [REDACTED]", encoder.Fields["description"])
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "stanza"
+
+var (
+	// EntriesIn counts the entries an operator has received from other operators.
+	EntriesIn = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "operator",
+		Name:      "entries_in_total",
+		Help:      "Number of entries received by an operator from other operators.",
+	}, []string{"operator_id"})
+
+	// EntriesOut counts the entries an operator has written to its outputs.
+	EntriesOut = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "operator",
+		Name:      "entries_out_total",
+		Help:      "Number of entries written by an operator to its outputs.",
+	}, []string{"operator_id"})
+
+	// Errors counts the entries an operator has failed to process.
+	Errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "operator",
+		Name:      "errors_total",
+		Help:      "Number of entries an operator failed to process.",
+	}, []string{"operator_id"})
+
+	// Dropped counts the entries an operator has dropped.
+	Dropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "operator",
+		Name:      "entries_dropped_total",
+		Help:      "Number of entries dropped by an operator.",
+	}, []string{"operator_id"})
+
+	// DeadLettered counts the entries an operator has sent to its dead letter output.
+	DeadLettered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "operator",
+		Name:      "entries_dead_lettered_total",
+		Help:      "Number of entries sent to a dead letter output by an operator.",
+	}, []string{"operator_id"})
+
+	// QueueEntries tracks the number of entries waiting in the queue in front of an operator.
+	QueueEntries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "queue",
+		Name:      "entries",
+		Help:      "Number of entries waiting in the queue in front of an operator.",
+	}, []string{"operator_id"})
+
+	// BufferEntries tracks the number of entries held in an output's buffer.
+	BufferEntries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "buffer",
+		Name:      "entries",
+		Help:      "Number of entries held in a buffer, including entries that are being flushed.",
+	}, []string{"operator_id", "buffer_type"})
+
+	// FlushDuration observes the duration of successful flushes.
+	FlushDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "flusher",
+		Name:      "flush_duration_seconds",
+		Help:      "Duration of successful flushes of a chunk of entries.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"operator_id"})
+
+	// FlushRetries counts the flushes that failed and were retried.
+	FlushRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "flusher",
+		Name:      "retries_total",
+		Help:      "Number of failed flushes that were retried.",
+	}, []string{"operator_id"})
+
+	// FlushDropped counts the chunks dropped after reaching the max retry time.
+	FlushDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "flusher",
+		Name:      "chunks_dropped_total",
+		Help:      "Number of chunks dropped after reaching the max retry time.",
+	}, []string{"operator_id"})
+
+	// Redactions counts the values replaced by a redact operator.
+	Redactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redact",
+		Name:      "redactions_total",
+		Help:      "Number of values replaced by a redact operator, by rule.",
+	}, []string{"operator_id", "rule"})
+)
+
+// Registry is the registry that holds the metrics of the agent.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		EntriesIn,
+		EntriesOut,
+		Errors,
+		Dropped,
+		DeadLettered,
+		QueueEntries,
+		BufferEntries,
+		FlushDuration,
+		FlushRetries,
+		FlushDropped,
+		Redactions,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler returns an HTTP handler that serves the metrics of the agent
+// in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -17,6 +17,7 @@ type Buffer interface {
 	ReadWait(context.Context, []*entry.Entry) (Clearer, int, error)
 	ReadChunk(context.Context) ([]*entry.Entry, Clearer, error)
 	Close() error
+	Len() int
 	MaxChunkDelay() time.Duration
 	MaxChunkSize() uint
 	SetMaxChunkDelay(time.Duration)
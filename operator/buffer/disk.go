@@ -12,8 +12,10 @@ import (
 	"time"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
 	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/operator/helper"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -48,7 +50,7 @@ func NewDiskBufferConfig() *DiskBufferConfig {
 }
 
 // Build creates a new Buffer from a DiskBufferConfig
-func (c DiskBufferConfig) Build(_ operator.BuildContext, _ string) (Buffer, error) {
+func (c DiskBufferConfig) Build(context operator.BuildContext, pluginID string) (Buffer, error) {
 	maxSize := c.MaxSize
 	if maxSize == 0 {
 		maxSize = 1 << 32
@@ -58,6 +60,7 @@ func (c DiskBufferConfig) Build(_ operator.BuildContext, _ string) (Buffer, erro
 		return nil, fmt.Errorf("missing required field 'path'")
 	}
 	b := NewDiskBuffer(int64(maxSize))
+	b.entries = metrics.BufferEntries.WithLabelValues(context.PrependNamespace(pluginID), "disk")
 	if err := b.Open(c.Path, c.Sync); err != nil {
 		return nil, err
 	}
@@ -105,6 +108,9 @@ type DiskBuffer struct {
 	maxChunkSize  uint
 
 	reconfigMutex sync.RWMutex
+
+	// entries tracks the number of entries that have not been flushed
+	entries prometheus.Gauge
 }
 
 // NewDiskBuffer creates a new DiskBuffer
@@ -114,6 +120,7 @@ func NewDiskBuffer(maxDiskSize int64) *DiskBuffer {
 		entryAdded:        make(chan int64, 1),
 		copyBuffer:        make([]byte, 1<<16),
 		diskSizeSemaphore: semaphore.NewWeighted(int64(maxDiskSize)),
+		entries:           prometheus.NewGauge(prometheus.GaugeOpts{Name: "unregistered"}),
 	}
 }
 
@@ -159,6 +166,7 @@ func (d *DiskBuffer) Open(path string, sync bool) error {
 	d.metadata.unreadStartOffset = 0
 	d.addUnreadCount(int64(len(d.metadata.read)))
 	d.metadata.read = d.metadata.read[:0]
+	d.entries.Add(float64(d.metadata.unreadCount))
 	return d.metadata.Sync()
 }
 
@@ -167,12 +175,33 @@ func (d *DiskBuffer) Close() error {
 	d.Lock()
 	defer d.Unlock()
 
+	d.entries.Sub(float64(d.unflushedCount()))
+
 	if err := d.metadata.Close(); err != nil {
 		return err
 	}
 	return d.data.Close()
 }
 
+// Len returns the number of entries held by the disk buffer that have not been flushed
+func (d *DiskBuffer) Len() int {
+	d.Lock()
+	defer d.Unlock()
+	return d.unflushedCount()
+}
+
+// unflushedCount returns the number of entries that have not been flushed.
+// The disk buffer lock must be held when calling this.
+func (d *DiskBuffer) unflushedCount() int {
+	unflushed := int(d.metadata.unreadCount)
+	for _, entry := range d.metadata.read {
+		if !entry.flushed {
+			unflushed++
+		}
+	}
+	return unflushed
+}
+
 // Add adds an entry to the buffer, blocking until it is either added or the context
 // is cancelled.
 func (d *DiskBuffer) Add(ctx context.Context, newEntry *entry.Entry) error {
@@ -200,6 +229,7 @@ func (d *DiskBuffer) Add(ctx context.Context, newEntry *entry.Entry) error {
 	}
 
 	d.addUnreadCount(1)
+	d.entries.Inc()
 
 	return nil
 }
@@ -363,6 +393,7 @@ func (dc *diskClearer) MarkAllAsFlushed() error {
 		entry.flushed = true
 		dc.buffer.flushedBytes += entry.length
 	}
+	dc.buffer.entries.Sub(float64(len(dc.readEntries)))
 	dc.buffer.Unlock()
 	return dc.buffer.checkCompact()
 }
@@ -378,6 +409,7 @@ func (dc *diskClearer) MarkRangeAsFlushed(start, end uint) error {
 		entry.flushed = true
 		dc.buffer.flushedBytes += entry.length
 	}
+	dc.buffer.entries.Sub(float64(end - start))
 	dc.buffer.Unlock()
 	return dc.buffer.checkCompact()
 }
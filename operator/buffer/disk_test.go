@@ -107,6 +107,17 @@ func TestDiskBuffer(t *testing.T) {
 		require.Equal(t, 10, n)
 	})
 
+	t.Run("Len", func(t *testing.T) {
+		t.Parallel()
+		b := openBuffer(t)
+		writeN(t, b, 20, 0)
+		require.Equal(t, 20, b.Len())
+		readN(t, b, 10, 0)
+		require.Equal(t, 20, b.Len())
+		flushN(t, b, 5, 10)
+		require.Equal(t, 15, b.Len())
+	})
+
 	t.Run("Write20Read10CompactRead10", func(t *testing.T) {
 		t.Parallel()
 		b := openBuffer(t)
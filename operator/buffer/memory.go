@@ -12,8 +12,10 @@ import (
 
 	"github.com/observiq/stanza/database"
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
 	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/operator/helper"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.etcd.io/bbolt"
 	"golang.org/x/sync/semaphore"
 )
@@ -47,6 +49,7 @@ func (c MemoryBufferConfig) Build(context operator.BuildContext, pluginID string
 		inFlight:      make(map[uint64]*entry.Entry, c.MaxEntries),
 		maxChunkDelay: c.MaxChunkDelay.Raw(),
 		maxChunkSize:  c.MaxChunkSize,
+		entries:       metrics.BufferEntries.WithLabelValues(context.PrependNamespace(pluginID), "memory"),
 	}
 	if err := mb.loadFromDB(); err != nil {
 		return nil, err
@@ -69,15 +72,19 @@ type MemoryBuffer struct {
 	maxChunkDelay time.Duration
 	maxChunkSize  uint
 	reconfigMutex sync.RWMutex
+	entries       prometheus.Gauge
 }
 
-// Add inserts an entry into the memory database, blocking until there is space
+// Add inserts an entry into the memory database, blocking until there is space.
+// The entry is retained until it is flushed, or saved to the database on Close.
 func (m *MemoryBuffer) Add(ctx context.Context, e *entry.Entry) error {
 	if err := m.sem.Acquire(ctx, 1); err != nil {
 		return err
 	}
 
+	e.Retain()
 	m.buf <- e
+	m.entries.Inc()
 	return nil
 }
 
@@ -180,12 +187,9 @@ type memoryClearer struct {
 }
 
 func (mc *memoryClearer) MarkAllAsFlushed() error {
-	mc.buffer.inFlightMux.Lock()
-	for _, id := range mc.ids {
-		delete(mc.buffer.inFlight, id)
-	}
-	mc.buffer.inFlightMux.Unlock()
+	mc.buffer.release(mc.ids)
 	mc.buffer.sem.Release(int64(len(mc.ids)))
+	mc.buffer.entries.Sub(float64(len(mc.ids)))
 	return nil
 }
 
@@ -195,16 +199,38 @@ func (mc *memoryClearer) MarkRangeAsFlushed(start, end uint) error {
 		return fmt.Errorf("invalid range")
 	}
 
-	mc.buffer.inFlightMux.Lock()
-	for _, id := range mc.ids[start:end] {
-		delete(mc.buffer.inFlight, id)
-	}
-	mc.buffer.inFlightMux.Unlock()
+	mc.buffer.release(mc.ids[start:end])
 	// #nosec G115 - Value will not be negative
 	mc.buffer.sem.Release(int64(end - start))
+	mc.buffer.entries.Sub(float64(end - start))
 	return nil
 }
 
+// release removes the entries identified by `ids` from the in flight entries,
+// then releases them, since they have been delivered
+func (m *MemoryBuffer) release(ids []uint64) {
+	flushed := make([]*entry.Entry, 0, len(ids))
+	m.inFlightMux.Lock()
+	for _, id := range ids {
+		if e, ok := m.inFlight[id]; ok {
+			flushed = append(flushed, e)
+			delete(m.inFlight, id)
+		}
+	}
+	m.inFlightMux.Unlock()
+
+	for _, e := range flushed {
+		e.Release()
+	}
+}
+
+// Len returns the number of entries held by the memory buffer, including entries that are being flushed
+func (m *MemoryBuffer) Len() int {
+	m.inFlightMux.Lock()
+	defer m.inFlightMux.Unlock()
+	return len(m.inFlight) + len(m.buf)
+}
+
 // newFlushFunc returns a function that will remove the entries identified by `ids` from the buffer
 func (m *MemoryBuffer) newClearer(ids []uint64) Clearer {
 	return &memoryClearer{
@@ -214,11 +240,14 @@ func (m *MemoryBuffer) newClearer(ids []uint64) Clearer {
 }
 
 // Close closes the memory buffer, saving all entries currently in the memory buffer to the
-// agent's database.
+// agent's database. The saved entries are released, since they will be flushed after a restart.
 func (m *MemoryBuffer) Close() error {
 	m.inFlightMux.Lock()
 	defer m.inFlightMux.Unlock()
-	return m.db.Update(func(tx *bbolt.Tx) error {
+	m.entries.Sub(float64(len(m.inFlight) + len(m.buf)))
+
+	saved := make([]*entry.Entry, 0, len(m.inFlight)+len(m.buf))
+	err := m.db.Update(func(tx *bbolt.Tx) error {
 		memBufBucket, err := tx.CreateBucketIfNotExists([]byte("memory_buffer"))
 		if err != nil {
 			return err
@@ -233,6 +262,7 @@ func (m *MemoryBuffer) Close() error {
 			if err := putKeyValue(b, k, v); err != nil {
 				return err
 			}
+			saved = append(saved, v)
 		}
 
 		for {
@@ -242,11 +272,20 @@ func (m *MemoryBuffer) Close() error {
 				if err := putKeyValue(b, m.entryID, e); err != nil {
 					return err
 				}
+				saved = append(saved, e)
 			default:
 				return nil
 			}
 		}
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range saved {
+		e.Release()
+	}
+	return nil
 }
 
 func putKeyValue(b *bbolt.Bucket, k uint64, v *entry.Entry) error {
@@ -288,6 +327,7 @@ func (m *MemoryBuffer) loadFromDB() error {
 
 			select {
 			case m.buf <- &e:
+				m.entries.Inc()
 				return nil
 			default:
 				return fmt.Errorf("max_entries is smaller than the number of entries stored in the database")
@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"testing"
@@ -44,6 +45,17 @@ func TestMemoryBuffer(t *testing.T) {
 		readN(t, b, 10, 10)
 	})
 
+	t.Run("Len", func(t *testing.T) {
+		t.Parallel()
+		b := newMemoryBuffer(t)
+		writeN(t, b, 20, 0)
+		require.Equal(t, 20, b.Len())
+		readN(t, b, 10, 0)
+		require.Equal(t, 20, b.Len())
+		flushN(t, b, 5, 10)
+		require.Equal(t, 15, b.Len())
+	})
+
 	t.Run("CheckN", func(t *testing.T) {
 		t.Run("Read", func(t *testing.T) {
 			b := newMemoryBuffer(t)
@@ -234,6 +246,31 @@ func TestMemoryBuffer(t *testing.T) {
 		readN(t, b2, 5, 0)
 		readN(t, b2, 10, 10)
 	})
+
+	t.Run("ReleaseFlushed", func(t *testing.T) {
+		t.Parallel()
+		b := newMemoryBuffer(t)
+
+		var acked int32
+		for i := 0; i < 2; i++ {
+			e := entry.New()
+			e.SetAck(entry.NewAck(func() { atomic.AddInt32(&acked, 1) }))
+			require.NoError(t, b.Add(context.Background(), e))
+			e.Release()
+		}
+		require.Equal(t, int32(0), atomic.LoadInt32(&acked))
+
+		dst := make([]*entry.Entry, 1)
+		clearer, n, err := b.Read(dst)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.NoError(t, clearer.MarkAllAsFlushed())
+		require.Equal(t, int32(1), atomic.LoadInt32(&acked))
+
+		// Entries saved to the database are released as well
+		require.NoError(t, b.Close())
+		require.Equal(t, int32(2), atomic.LoadInt32(&acked))
+	})
 }
 
 func BenchmarkMemoryBuffer(b *testing.B) {
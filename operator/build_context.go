@@ -17,6 +17,9 @@ type BuildContext struct {
 	Namespace        string
 	DefaultOutputIDs []string
 	PluginDepth      int
+
+	// Pipeline is the name of the named pipeline being built, or empty for the default pipeline
+	Pipeline string
 }
 
 // PrependNamespace adds the current namespace of the build context to the
@@ -35,6 +38,24 @@ func (bc BuildContext) WithSubNamespace(namespace string) BuildContext {
 	return newBuildContext
 }
 
+// WithPipeline creates a new build context for the operators of a named pipeline.
+// The operators are namespaced by the name of the pipeline, and persist their data
+// in a scope of the database that is separate from other pipelines.
+func (bc BuildContext) WithPipeline(name string) BuildContext {
+	newBuildContext := bc.WithSubNamespace(name)
+	newBuildContext.Pipeline = name
+	return newBuildContext
+}
+
+// PersisterScope returns the database scope that the operator with the given ID
+// persists its data in, which is unique to the pipeline the operator belongs to
+func (bc BuildContext) PersisterScope(id string) string {
+	if bc.Pipeline == "" {
+		return id
+	}
+	return fmt.Sprintf("%s/%s", bc.Pipeline, id)
+}
+
 // WithDefaultOutputIDs sets the default output IDs for the current context or
 // the current operator build
 func (bc BuildContext) WithDefaultOutputIDs(ids []string) BuildContext {
@@ -60,6 +81,7 @@ func (bc BuildContext) Copy() BuildContext {
 		Namespace:        bc.Namespace,
 		DefaultOutputIDs: bc.DefaultOutputIDs,
 		PluginDepth:      bc.PluginDepth,
+		Pipeline:         bc.Pipeline,
 	}
 }
 
@@ -32,6 +32,25 @@ func TestBuildContext(t *testing.T) {
 		require.Equal(t, "$.ns", bc.Namespace)
 	})
 
+	t.Run("WithPipeline", func(t *testing.T) {
+		bc := BuildContext{
+			Namespace: "$",
+		}
+		bc2 := bc.WithPipeline("team_a")
+		require.Equal(t, "$.team_a", bc2.Namespace)
+		require.Equal(t, "team_a", bc2.Pipeline)
+		require.Equal(t, "", bc.Pipeline)
+		require.Equal(t, "team_a", bc2.WithSubNamespace("plugin").Pipeline)
+	})
+
+	t.Run("PersisterScope", func(t *testing.T) {
+		bc := BuildContext{
+			Namespace: "$",
+		}
+		require.Equal(t, "file_input", bc.PersisterScope("file_input"))
+		require.Equal(t, "team_a/file_input", bc.WithPipeline("team_a").PersisterScope("file_input"))
+	})
+
 	t.Run("WithDefaultOutputIDs", func(t *testing.T) {
 		bc := BuildContext{
 			DefaultOutputIDs: []string{"orig"},
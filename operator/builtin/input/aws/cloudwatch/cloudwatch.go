@@ -108,7 +108,7 @@ func (c *CloudwatchInputConfig) Build(buildContext operator.BuildContext) ([]ope
 		pollInterval:        c.PollInterval,
 		startAtEnd:          startAtEnd,
 		persist: Persister{
-			DB: helper.NewScopedDBPersister(buildContext.Database, c.ID()),
+			DB: helper.NewScopedDBPersister(buildContext.Database, buildContext.PersisterScope(c.ID())),
 		},
 	}
 	return []operator.Operator{cloudwatchInput}, nil
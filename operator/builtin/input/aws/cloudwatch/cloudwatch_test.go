@@ -455,7 +455,7 @@ func TestHandleEvent(t *testing.T) {
 					"ingestion_time": ingestionTime,
 					"message":        msg,
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id":   eventID,
 					"log_group":  logGroupName,
 					"log_stream": logStreamName,
@@ -477,7 +477,7 @@ func TestHandleEvent(t *testing.T) {
 				Record: map[string]interface{}{
 					"ingestion_time": ingestionTime,
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id":   eventID,
 					"log_group":  logGroupName,
 					"log_stream": logStreamName,
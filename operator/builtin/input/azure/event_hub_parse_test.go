@@ -45,7 +45,7 @@ func TestParseEvent(t *testing.T) {
 						"x-opt-offset":          &testOffset,
 					},
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id": "000-555-666",
 				},
 			},
@@ -97,7 +97,7 @@ func TestParseEvent(t *testing.T) {
 						"iothub-enqueuedtime":                  &testTime,
 					},
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id": "1111",
 				},
 			},
@@ -140,7 +140,7 @@ func TestPromoteTime(t *testing.T) {
 						"x-opt-enqueued-time": &enqueuedTime,
 					},
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id": "000-555-666",
 				},
 			},
@@ -162,7 +162,7 @@ func TestPromoteTime(t *testing.T) {
 						"iothub-enqueuedtime": &ioTHubEnqueuedTime,
 					},
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id": "000-555-666",
 				},
 			},
@@ -186,7 +186,7 @@ func TestPromoteTime(t *testing.T) {
 						"iothub-enqueuedtime": &ioTHubEnqueuedTime,
 					},
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id": "000-555-666",
 				},
 			},
@@ -210,7 +210,7 @@ func TestPromoteTime(t *testing.T) {
 						"iothub-enqueuedtime": &ioTHubEnqueuedTime,
 					},
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id": "000-555-666",
 				},
 			},
@@ -41,7 +41,7 @@ func (c *EventHubInputConfig) Build(buildContext operator.BuildContext) ([]opera
 		EventHub: azure.EventHub{
 			AzureConfig: c.AzureConfig,
 			Persist: &azure.Persister{
-				DB: helper.NewScopedDBPersister(buildContext.Database, c.ID()),
+				DB: helper.NewScopedDBPersister(buildContext.Database, buildContext.PersisterScope(c.ID())),
 			},
 		},
 	}
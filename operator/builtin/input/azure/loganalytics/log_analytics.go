@@ -42,7 +42,7 @@ func (c *LogAnalyticsInputConfig) Build(buildContext operator.BuildContext) ([]o
 		EventHub: azure.EventHub{
 			AzureConfig: c.AzureConfig,
 			Persist: &azure.Persister{
-				DB: helper.NewScopedDBPersister(buildContext.Database, c.ID()),
+				DB: helper.NewScopedDBPersister(buildContext.Database, buildContext.PersisterScope(c.ID())),
 			},
 		},
 		json: jsoniter.ConfigFastest,
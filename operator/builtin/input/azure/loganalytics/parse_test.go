@@ -54,7 +54,7 @@ func TestParse(t *testing.T) {
 			},
 			&entry.Entry{
 				Timestamp: testTimeGenerated,
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"azure_log_analytics_table": "unit_test",
 				},
 				Record: map[string]interface{}{
@@ -76,7 +76,7 @@ func TestParse(t *testing.T) {
 						"timegenerated": testTimeGeneratedSTR,
 					},
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"event_id": "000",
 				},
 			},
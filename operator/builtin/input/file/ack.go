@@ -0,0 +1,57 @@
+package file
+
+import "sync"
+
+// ackTracker tracks the entries read from a file that have not been delivered yet,
+// so that the file's offset is only persisted up to the oldest of them
+type ackTracker struct {
+	mux     sync.Mutex
+	pending []*pendingEntry
+}
+
+// pendingEntry is an entry that was read from a file, identified by the offset it starts at
+type pendingEntry struct {
+	offset int64
+	acked  bool
+}
+
+func newAckTracker() *ackTracker {
+	return &ackTracker{
+		pending: make([]*pendingEntry, 0),
+	}
+}
+
+// track starts tracking an entry that starts at the supplied offset. The
+// returned function must be called once the entry has been delivered.
+func (t *ackTracker) track(offset int64) func() {
+	p := &pendingEntry{offset: offset}
+
+	t.mux.Lock()
+	t.pending = append(t.pending, p)
+	t.mux.Unlock()
+
+	return func() {
+		t.mux.Lock()
+		defer t.mux.Unlock()
+
+		p.acked = true
+		i := 0
+		for i < len(t.pending) && t.pending[i].acked {
+			i++
+		}
+		t.pending = t.pending[i:]
+	}
+}
+
+// checkpoint returns the offset that is safe to persist for a file that has been
+// read up to the supplied offset. This is the start of the oldest entry that has
+// not been delivered yet, if there is one.
+func (t *ackTracker) checkpoint(offset int64) int64 {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if len(t.pending) > 0 && t.pending[0].offset < offset {
+		return t.pending[0].offset
+	}
+	return offset
+}
@@ -0,0 +1,27 @@
+package file
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAckTracker(t *testing.T) {
+	tracker := newAckTracker()
+	require.Equal(t, int64(10), tracker.checkpoint(10))
+
+	ack1 := tracker.track(10)
+	ack2 := tracker.track(20)
+	ack3 := tracker.track(30)
+	require.Equal(t, int64(10), tracker.checkpoint(40))
+
+	// Acknowledging out of order does not move the checkpoint past the oldest entry
+	ack2()
+	require.Equal(t, int64(10), tracker.checkpoint(40))
+
+	ack1()
+	require.Equal(t, int64(30), tracker.checkpoint(40))
+
+	ack3()
+	require.Equal(t, int64(40), tracker.checkpoint(40))
+}
@@ -59,6 +59,7 @@ type InputConfig struct {
 	LabelRegex              string                 `json:"label_regex,omitempty"                 yaml:"label_regex,omitempty"`
 	Encoding                helper.EncodingConfig  `json:",inline,omitempty"                     yaml:",inline,omitempty"`
 	FilenameRecallPeriod    helper.Duration        `json:"filename_recall_period,omitempty"      yaml:"filename_recall_period,omitempty"`
+	WaitForAck              bool                   `json:"wait_for_ack,omitempty"                yaml:"wait_for_ack,omitempty"`
 }
 
 // Build will build a file input operator from the supplied configuration
@@ -125,6 +126,10 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 		return nil, fmt.Errorf("invalid start_at location '%s'", c.StartAt)
 	}
 
+	if c.WaitForAck && c.DeleteAfterRead {
+		return nil, fmt.Errorf("wait_for_ack cannot be used with delete_after_read")
+	}
+
 	var labelRegex *regexp.Regexp
 	if c.LabelRegex != "" {
 		r, err := regexp.Compile(c.LabelRegex)
@@ -172,13 +177,14 @@ func (c InputConfig) Build(context operator.BuildContext) ([]operator.Operator,
 		finder:                c.Finder,
 		SplitFunc:             splitFunc,
 		PollInterval:          c.PollInterval.Raw(),
-		persist:               helper.NewScopedDBPersister(context.Database, c.ID()),
+		persist:               helper.NewScopedDBPersister(context.Database, context.PersisterScope(c.ID())),
 		FilePathField:         filePathField,
 		FileNameField:         fileNameField,
 		FilePathResolvedField: filePathResolvedField,
 		FileNameResolvedField: fileNameResolvedField,
 		startAtBeginning:      startAtBeginning,
 		deleteAfterRead:       c.DeleteAfterRead,
+		waitForAck:            c.WaitForAck,
 		queuedMatches:         make([]string, 0),
 		labelRegex:            labelRegex,
 		encoding:              encoding,
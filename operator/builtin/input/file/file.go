@@ -19,6 +19,7 @@ import (
 // InputOperator is an operator that monitors files for entries
 type InputOperator struct {
 	helper.InputOperator
+	helper.Pauser
 
 	finder                Finder
 	FilePathField         entry.Field
@@ -41,6 +42,7 @@ type InputOperator struct {
 
 	startAtBeginning bool
 	deleteAfterRead  bool
+	waitForAck       bool
 
 	fingerprintSize int
 
@@ -88,6 +90,7 @@ func (f *InputOperator) startPoller(ctx context.Context) {
 	f.wg.Add(1)
 	go func() {
 		defer f.wg.Done()
+		defer f.RoutineExited(ctx, "poller")
 		globTicker := time.NewTicker(f.PollInterval)
 		defer globTicker.Stop()
 
@@ -98,6 +101,11 @@ func (f *InputOperator) startPoller(ctx context.Context) {
 			case <-globTicker.C:
 			}
 
+			// Offsets are kept while paused, so reading resumes where it stopped
+			if f.Paused() {
+				continue
+			}
+
 			f.poll(ctx)
 		}
 	}()
@@ -340,7 +348,7 @@ func (f *InputOperator) syncLastPollFiles() {
 
 	// Encode each known file
 	for _, fileReader := range f.knownFiles {
-		if err := enc.Encode(fileReader); err != nil {
+		if err := enc.Encode(fileReader.checkpoint()); err != nil {
 			f.Errorw("Failed to encode known files", zap.Error(err))
 		}
 	}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/operator/helper"
 	"github.com/observiq/stanza/testutil"
 	"github.com/stretchr/testify/require"
@@ -954,3 +955,60 @@ func TestEncodings(t *testing.T) {
 		})
 	}
 }
+
+// retainingOutput is a fake output that holds on to the entries it receives, like a buffered output
+type retainingOutput struct {
+	*testutil.FakeOutput
+}
+
+func (o retainingOutput) Process(ctx context.Context, e *entry.Entry) error {
+	e.Retain()
+	return o.FakeOutput.Process(ctx, e)
+}
+
+// WaitForAck tests that the persisted offset of a file only
+// advances past the entries that have been delivered
+func TestWaitForAck(t *testing.T) {
+	t.Parallel()
+	tempDir := testutil.NewTempDir(t)
+	cfg := newDefaultConfig(tempDir)
+	cfg.PollInterval = helper.NewDuration(50 * time.Millisecond)
+	cfg.WaitForAck = true
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*InputOperator)
+
+	output := retainingOutput{testutil.NewFakeOutput(t)}
+	require.NoError(t, op.SetOutputs([]operator.Operator{output}))
+
+	temp := openTemp(t, tempDir)
+	writeString(t, temp, "testlog1\ntestlog2\n")
+
+	require.NoError(t, op.Start())
+	defer op.Stop()
+
+	first := waitForOne(t, output.Received)
+	require.Equal(t, "testlog1", first.Record)
+	waitForMessage(t, output.Received, "testlog2")
+
+	// Deliver only the first entry and wait for the offsets to be synced
+	first.Release()
+	time.Sleep(300 * time.Millisecond)
+
+	// The undelivered entry is read again after a restart
+	require.NoError(t, op.Stop())
+	require.NoError(t, op.Start())
+	waitForMessage(t, output.Received, "testlog2")
+	expectNoMessagesUntil(t, output.Received, 200*time.Millisecond)
+}
+
+func TestWaitForAckWithDeleteAfterRead(t *testing.T) {
+	cfg := newDefaultConfig(testutil.NewTempDir(t))
+	cfg.WaitForAck = true
+	cfg.DeleteAfterRead = true
+
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "wait_for_ack cannot be used with delete_after_read")
+}
@@ -61,6 +61,10 @@ type Reader struct {
 	decoder      *encoding.Decoder
 	decodeBuffer []byte
 
+	// acks tracks the entries that have not been delivered yet.
+	// It is shared with the copies of the reader.
+	acks *ackTracker
+
 	*zap.SugaredLogger `json:"-"`
 }
 
@@ -75,6 +79,7 @@ func (f *InputOperator) NewReader(path string, file *os.File, fp *Fingerprint) (
 		decoder:       f.encoding.Encoding.NewDecoder(),
 		decodeBuffer:  make([]byte, 1<<12),
 		fileLabels:    f.resolveFileLabels(path),
+		acks:          newAckTracker(),
 	}
 	return r, nil
 }
@@ -86,6 +91,7 @@ func (f *Reader) Copy(file *os.File) (*Reader, error) {
 		return nil, err
 	}
 	reader.Offset = f.Offset
+	reader.acks = f.acks
 	for k, v := range f.HeaderLabels {
 		reader.HeaderLabels[k] = v
 	}
@@ -219,10 +225,28 @@ func (f *Reader) emit(ctx context.Context, msgBuf []byte) error {
 		}
 	}
 
+	// The reader's offset is the start of the entry until it has been consumed
+	if f.fileInput.waitForAck {
+		e.SetAck(entry.NewAck(f.acks.track(f.Offset)))
+	}
+
 	f.fileInput.Write(ctx, e)
+	e.Release()
 	return nil
 }
 
+// checkpoint returns the reader as it should be persisted. If the input waits for
+// acknowledgements, the offset is that of the oldest entry that has not been delivered.
+func (f *Reader) checkpoint() *Reader {
+	if !f.fileInput.waitForAck {
+		return f
+	}
+
+	checkpoint := *f
+	checkpoint.Offset = f.acks.checkpoint(f.Offset)
+	return &checkpoint
+}
+
 // decode converts the bytes in msgBuf to utf-8 from the configured encoding
 func (f *Reader) decode(msgBuf []byte) (string, error) {
 	for {
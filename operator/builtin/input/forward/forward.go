@@ -3,6 +3,7 @@ package forward
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
 	"time"
@@ -97,6 +98,7 @@ func (f *ForwardInput) Start() error {
 		}
 		if err != nil && err != http.ErrServerClosed {
 			f.Errorw("Serve error", zap.Error(err))
+			f.SetUnhealthy(fmt.Sprintf("server exited unexpectedly: %s", err))
 		}
 	}()
 
@@ -51,6 +51,7 @@ func (c *GenerateInputConfig) Build(context operator.BuildContext) ([]operator.O
 // GenerateInput is an operator that generates log entries.
 type GenerateInput struct {
 	helper.InputOperator
+	helper.Pauser
 	entry  entry.Entry
 	count  int
 	static bool
@@ -74,6 +75,10 @@ func (g *GenerateInput) Start() error {
 			default:
 			}
 
+			if err := g.Wait(ctx); err != nil {
+				return
+			}
+
 			entry := g.entry.Copy()
 			if !g.static {
 				entry.Timestamp = time.Now()
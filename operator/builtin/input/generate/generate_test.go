@@ -2,6 +2,7 @@ package generate
 
 import (
 	"testing"
+	"time"
 
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/operator"
@@ -32,3 +33,28 @@ func TestInputGenerate(t *testing.T) {
 		fake.ExpectRecord(t, "test message")
 	}
 }
+
+func TestInputGeneratePause(t *testing.T) {
+	cfg := NewGenerateInputConfig("test_operator_id")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Count = 1
+	cfg.Entry = entry.Entry{
+		Record: "test message",
+	}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*GenerateInput)
+
+	fake := testutil.NewFakeOutput(t)
+	err = op.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, err)
+
+	op.Pause()
+	require.NoError(t, op.Start())
+	defer op.Stop()
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+
+	op.Resume()
+	fake.ExpectRecord(t, "test message")
+}
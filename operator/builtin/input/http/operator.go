@@ -84,6 +84,7 @@ func (t *HTTPInput) goListen(ctx context.Context) {
 	t.wg.Add(1)
 	go func() {
 		defer t.wg.Done()
+		defer t.RoutineExited(ctx, "server")
 		t.Debugf("Starting http server on socket %s", t.server.Addr)
 		if t.tls {
 			if err := t.server.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
@@ -445,7 +445,7 @@ func TestParse(t *testing.T) {
 				Record: map[string]interface{}{
 					"message": "generic event",
 				},
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"net.peer.ip":      "10.1.1.1",
 					"net.peer.port":    "5555",
 					"net.host.ip":      "1.1.1.1",
@@ -472,7 +472,7 @@ func TestParse(t *testing.T) {
 					"msg":   "generic event",
 					"stage": "dev",
 				},
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"net.peer.ip":      "10.1.1.1",
 					"net.peer.port":    "5555",
 					"net.host.ip":      "1.1.1.1",
@@ -510,7 +510,7 @@ func TestParse(t *testing.T) {
 						"user": "admin",
 					},
 				},
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"net.peer.ip":      "10.1.1.1",
 					"net.peer.port":    "5555",
 					"net.host.ip":      "1.1.1.1",
@@ -536,7 +536,7 @@ func TestParse(t *testing.T) {
 				Record: map[string]interface{}{
 					"message": "generic event",
 				},
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"net.host.ip":      "1.1.1.1",
 					"net.host.port":    "80",
 					"protocol":         "HTTP",
@@ -560,7 +560,7 @@ func TestParse(t *testing.T) {
 				Record: map[string]interface{}{
 					"message": "generic event",
 				},
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"net.peer.ip":      "10.1.1.1",
 					"net.peer.port":    "5555",
 					"protocol":         "HTTP",
@@ -584,7 +584,7 @@ func TestParse(t *testing.T) {
 				Record: map[string]interface{}{
 					"message": "generic event",
 				},
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"net.peer.ip":   "10.1.1.1",
 					"net.peer.port": "5555",
 					"net.host.ip":   "1.1.1.1",
@@ -88,7 +88,7 @@ func (c JournaldInputConfig) Build(buildContext operator.BuildContext) ([]operat
 
 	journaldInput := &JournaldInput{
 		InputOperator: inputOperator,
-		persist:       helper.NewScopedDBPersister(buildContext.Database, c.ID()),
+		persist:       helper.NewScopedDBPersister(buildContext.Database, buildContext.PersisterScope(c.ID())),
 		newCmd: func(ctx context.Context, cursor []byte) cmd {
 			finalArgs := args
 			if cursor != nil {
@@ -106,6 +106,7 @@ func (c JournaldInputConfig) Build(buildContext operator.BuildContext) ([]operat
 // JournaldInput is an operator that process logs using journald
 type JournaldInput struct {
 	helper.InputOperator
+	helper.Pauser
 
 	newCmd func(ctx context.Context, cursor []byte) cmd
 
@@ -147,6 +148,7 @@ func (operator *JournaldInput) startPoller(ctx context.Context) {
 
 	go func() {
 		defer operator.wg.Done()
+		defer operator.RoutineExited(ctx, "poller")
 
 		globTicker := time.NewTicker(operator.pollInterval)
 		defer globTicker.Stop()
@@ -159,6 +161,11 @@ func (operator *JournaldInput) startPoller(ctx context.Context) {
 			case <-globTicker.C:
 			}
 
+			// The cursor is kept while paused, so reading resumes where it stopped
+			if operator.Paused() {
+				continue
+			}
+
 			if err := operator.poll(ctx); err != nil {
 				operator.Errorf("error while polling journald: %s", err)
 			}
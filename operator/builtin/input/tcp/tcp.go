@@ -143,6 +143,7 @@ func (c TCPInputConfig) Build(context operator.BuildContext) ([]operator.Operato
 // TCPInput is an operator that listens for log entries over tcp.
 type TCPInput struct {
 	helper.InputOperator
+	helper.Pauser
 	address       string
 	maxBufferSize int
 	addLabels     bool
@@ -201,6 +202,7 @@ func (t *TCPInput) goListen(ctx context.Context) {
 
 	go func() {
 		defer t.wg.Done()
+		defer t.RoutineExited(ctx, "listener")
 
 		for {
 			conn, err := t.listener.Accept()
@@ -251,6 +253,11 @@ func (t *TCPInput) goHandleMessages(ctx context.Context, conn net.Conn, cancel c
 		scanner := bufio.NewScanner(conn)
 		scanner.Buffer(buf, t.maxBufferSize*1024)
 		for scanner.Scan() {
+			// While paused, the connection is not read, so clients are held back by tcp flow control
+			if err := t.Wait(ctx); err != nil {
+				return
+			}
+
 			entry, err := t.NewEntry(scanner.Text())
 			if err != nil {
 				t.Errorw("Failed to create entry", zap.Error(err))
@@ -147,7 +147,7 @@ func tcpInputLabelsTest(input []byte, expected []string) func(t *testing.T) {
 		for _, expectedMessage := range expected {
 			select {
 			case entry := <-entryChan:
-				expectedLabels := map[string]string{
+				expectedLabels := map[string]interface{}{
 					"net.transport": "IP.TCP",
 				}
 				if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
@@ -241,6 +241,38 @@ func tlsTCPInputTest(input []byte, expected []string) func(t *testing.T) {
 	}
 }
 
+func TestTCPInputPause(t *testing.T) {
+	cfg := NewTCPInputConfig("test_id")
+	cfg.ListenAddress = ":0"
+	cfg.OutputIDs = []string{"fake"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	tcpInput := ops[0].(*TCPInput)
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, tcpInput.SetOutputs([]operator.Operator{fake}))
+	require.NoError(t, tcpInput.Start())
+	defer tcpInput.Stop()
+
+	conn, err := net.Dial("tcp", tcpInput.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("before\n"))
+	require.NoError(t, err)
+	fake.ExpectRecord(t, "before")
+
+	tcpInput.Pause()
+	require.True(t, tcpInput.Paused())
+	_, err = conn.Write([]byte("paused\n"))
+	require.NoError(t, err)
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+
+	tcpInput.Resume()
+	fake.ExpectRecord(t, "paused")
+}
+
 func TestBuild(t *testing.T) {
 	cases := []struct {
 		name        string
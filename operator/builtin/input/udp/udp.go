@@ -60,6 +60,7 @@ func (c UDPInputConfig) Build(context operator.BuildContext) ([]operator.Operato
 type UDPInput struct {
 	buffer []byte
 	helper.InputOperator
+	helper.Pauser
 	address   *net.UDPAddr
 	addLabels bool
 
@@ -89,6 +90,7 @@ func (u *UDPInput) goHandleMessages(ctx context.Context) {
 
 	go func() {
 		defer u.wg.Done()
+		defer u.RoutineExited(ctx, "reader")
 
 		for {
 			message, remoteAddr, err := u.readMessage()
@@ -102,6 +104,11 @@ func (u *UDPInput) goHandleMessages(ctx context.Context) {
 				break
 			}
 
+			// While paused, messages are held by the socket until its receive buffer is full
+			if err := u.Wait(ctx); err != nil {
+				return
+			}
+
 			entry, err := u.NewEntry(message)
 			if err != nil {
 				u.Errorw("Failed to create entry", zap.Error(err))
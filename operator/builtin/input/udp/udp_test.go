@@ -97,7 +97,7 @@ func udpInputLabelsTest(input []byte, expected []string) func(t *testing.T) {
 		for _, expectedRecord := range expected {
 			select {
 			case entry := <-entryChan:
-				expectedLabels := map[string]string{
+				expectedLabels := map[string]interface{}{
 					"net.transport": "IP.UDP",
 				}
 				// LocalAddr for udpInput.connection is a server address
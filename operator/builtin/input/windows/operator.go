@@ -44,7 +44,7 @@ func (c *EventLogConfig) Build(context operator.BuildContext) ([]operator.Operat
 		return nil, fmt.Errorf("the `start_at` field must be set to `beginning` or `end`")
 	}
 
-	offsets := helper.NewScopedDBPersister(context.Database, c.ID())
+	offsets := helper.NewScopedDBPersister(context.Database, context.PersisterScope(c.ID()))
 
 	eventLogInput := &EventLogInput{
 		InputOperator: inputOperator,
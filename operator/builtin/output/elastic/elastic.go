@@ -3,7 +3,10 @@ package elastic
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"io"
+	"net/http"
 	"strconv"
 	"sync"
 
@@ -76,7 +79,7 @@ func (c ElasticOutputConfig) Build(bc operator.BuildContext) ([]operator.Operato
 		return nil, err
 	}
 
-	flusher := c.FlusherConfig.Build(bc.Logger.SugaredLogger)
+	flusher := c.FlusherConfig.Build(bc.Logger.SugaredLogger, outputOperator.ID())
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -128,13 +131,24 @@ func (e *ElasticOutput) Stop() error {
 	return e.buffer.Close()
 }
 
+// Status reports the health of the operator's flusher
+func (e *ElasticOutput) Status() operator.Status {
+	return e.flusher.Status()
+}
+
+// Pending returns the number of entries held by the operator's buffer
+func (e *ElasticOutput) Pending() int {
+	return e.buffer.Len()
+}
+
 // Process adds an entry to the outputs buffer
 func (e *ElasticOutput) Process(ctx context.Context, entry *entry.Entry) error {
 	return e.buffer.Add(ctx, entry)
 }
 
-// ProcessMulti will send entries to elasticsearch.
-func (e *ElasticOutput) createRequest(entries []*entry.Entry) *esapi.BulkRequest {
+// createOperations creates the operations of a bulk request from the entries, returning them along
+// with the entries they index, in order. Entries that can not be encoded are rejected.
+func (e *ElasticOutput) createOperations(ctx context.Context, entries []*entry.Entry) ([][]byte, []*entry.Entry) {
 	type indexDirective struct {
 		Index struct {
 			Index string `json:"_index"`
@@ -145,45 +159,134 @@ func (e *ElasticOutput) createRequest(entries []*entry.Entry) *esapi.BulkRequest
 	// The bulk API expects newline-delimited json strings, with an operation directive
 	// immediately followed by the document.
 	// https://www.elastic.co/guide/en/elasticsearch/reference/master/docs-bulk.html
-	var buffer bytes.Buffer
 	var err error
+	operations := make([][]byte, 0, len(entries))
+	included := make([]*entry.Entry, 0, len(entries))
 	for _, entry := range entries {
 		directive := indexDirective{}
 		directive.Index.Index, err = e.FindIndex(entry)
 		if err != nil {
-			e.Warnw("Failed to find index", zap.Any("error", err))
+			e.Reject(ctx, entry, errors.Wrap(err, "find index"))
 			continue
 		}
 
 		directive.Index.ID, err = e.FindID(entry)
 		if err != nil {
-			e.Warnw("Failed to find id", zap.Any("error", err))
+			e.Reject(ctx, entry, errors.Wrap(err, "find id"))
 			continue
 		}
 
 		directiveJSON, err := json.Marshal(directive)
 		if err != nil {
-			e.Warnw("Failed to marshal directive JSON", zap.Any("error", err))
+			e.Reject(ctx, entry, errors.Wrap(err, "marshal directive"))
 			continue
 		}
 
-		entryJSON, err := json.Marshal(entry)
+		entryJSON, err := json.Marshal(newDocument(entry))
 		if err != nil {
-			e.Warnw("Failed to marshal entry JSON", zap.Any("error", err))
+			e.Reject(ctx, entry, errors.Wrap(err, "marshal entry"))
 			continue
 		}
 
-		buffer.Write(directiveJSON)
-		buffer.Write([]byte("\n"))
-		buffer.Write(entryJSON)
-		buffer.Write([]byte("\n"))
+		var operation bytes.Buffer
+		operation.Write(directiveJSON)
+		operation.Write([]byte("\n"))
+		operation.Write(entryJSON)
+		operation.Write([]byte("\n"))
+		operations = append(operations, operation.Bytes())
+		included = append(included, entry)
+	}
+
+	return operations, included
+}
+
+// document is an entry as it is indexed by elasticsearch, with its trace context
+// in the trace.id and span.id fields of the Elastic Common Schema
+type document struct {
+	entryFields
+	Trace *ecsID `json:"trace,omitempty"`
+	Span  *ecsID `json:"span,omitempty"`
+}
+
+// entryFields are the fields of an entry, embedded in a document without the
+// JSON marshalling of the entry, which would replace the fields of the document
+type entryFields entry.Entry
+
+// ecsID is the id of a trace or span in the Elastic Common Schema
+type ecsID struct {
+	ID string `json:"id"`
+}
+
+// newDocument creates the document indexed for an entry
+func newDocument(e *entry.Entry) interface{} {
+	if len(e.TraceID) == 0 && len(e.SpanID) == 0 && len(e.TraceFlags) == 0 {
+		return e
+	}
+
+	doc := document{entryFields: entryFields(*e)}
+	doc.TraceID, doc.SpanID, doc.TraceFlags = nil, nil, nil
+	if len(e.TraceID) > 0 {
+		doc.Trace = &ecsID{ID: hex.EncodeToString(e.TraceID)}
+	}
+	if len(e.SpanID) > 0 {
+		doc.Span = &ecsID{ID: hex.EncodeToString(e.SpanID)}
+	}
+	return doc
+}
+
+// bulkResponse is the response to a bulk request
+type bulkResponse struct {
+	Errors bool                          `json:"errors"`
+	Items  []map[string]bulkItemResponse `json:"items"`
+}
+
+// bulkItemResponse is the result of a single operation in a bulk request
+type bulkItemResponse struct {
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// handleBulkResponse will reject the entries that elasticsearch refused to index
+// because of a client error, like a mapping conflict, since sending them again
+// will not succeed. It returns the indices of the entries that failed with a
+// retryable error, like a 429 or 5xx status, so that they can be sent again.
+func (e *ElasticOutput) handleBulkResponse(ctx context.Context, body io.Reader, entries []*entry.Entry) []int {
+	var response bulkResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		e.Warnw("Failed to decode bulk response", zap.Error(err))
+		return nil
 	}
 
-	request := &esapi.BulkRequest{
-		Body: bytes.NewReader(buffer.Bytes()),
+	if !response.Errors {
+		return nil
 	}
 
-	return request
+	retry := make([]int, 0)
+	for i, item := range response.Items {
+		if i >= len(entries) {
+			break
+		}
+
+		for _, result := range item {
+			if result.Status < 400 {
+				continue
+			}
+
+			if result.Status == http.StatusTooManyRequests || result.Status >= 500 {
+				e.Debugw("Elasticsearch failed to index entry", "status_code", result.Status, "error", string(result.Error))
+				retry = append(retry, i)
+				continue
+			}
+
+			e.Reject(ctx, entries[i], errors.NewError(
+				"Elasticsearch rejected the entry.",
+				"Review the error for details, like a mapping conflict.",
+				"status_code", strconv.Itoa(result.Status),
+				"error", string(result.Error),
+			))
+		}
+	}
+	return retry
 }
 
 func (e *ElasticOutput) feedFlusher(ctx context.Context) {
@@ -196,8 +299,18 @@ func (e *ElasticOutput) feedFlusher(ctx context.Context) {
 			continue
 		}
 
+		operations, included := e.createOperations(ctx, entries)
+		if len(included) == 0 {
+			if err := clearer.MarkAllAsFlushed(); err != nil {
+				e.Errorw("Failed to mark entries as flushed", zap.Error(err))
+			}
+			continue
+		}
+
 		e.flusher.Do(func(ctx context.Context) error {
-			req := e.createRequest(entries)
+			req := &esapi.BulkRequest{
+				Body: bytes.NewReader(bytes.Join(operations, nil)),
+			}
 			res, err := req.Do(ctx, e.client)
 			if err != nil {
 				return errors.NewError(
@@ -206,6 +319,7 @@ func (e *ElasticOutput) feedFlusher(ctx context.Context) {
 					"underlying_error", err.Error(),
 				)
 			}
+			defer res.Body.Close()
 
 			if res.IsError() {
 				return errors.NewError(
@@ -216,6 +330,24 @@ func (e *ElasticOutput) feedFlusher(ctx context.Context) {
 				)
 			}
 
+			// Only the entries that failed with a retryable error are sent again,
+			// so that the entries that were indexed are not duplicated
+			if retry := e.handleBulkResponse(ctx, res.Body, included); len(retry) > 0 {
+				retryOperations := make([][]byte, 0, len(retry))
+				retryEntries := make([]*entry.Entry, 0, len(retry))
+				for _, i := range retry {
+					retryOperations = append(retryOperations, operations[i])
+					retryEntries = append(retryEntries, included[i])
+				}
+				operations, included = retryOperations, retryEntries
+
+				return errors.NewError(
+					"Elasticsearch failed to index some entries with a retryable error.",
+					"The entries will be retried. Review the status of the elasticsearch cluster if this persists.",
+					"failed_entries", strconv.Itoa(len(retry)),
+				)
+			}
+
 			if err = clearer.MarkAllAsFlushed(); err != nil {
 				e.Errorw("Failed to mark entries as flushed", zap.Error(err))
 			}
@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
 	"github.com/observiq/stanza/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -131,3 +136,128 @@ func TestElastic(t *testing.T) {
 		require.Equal(t, "test", entry["record"])
 	}
 }
+
+func TestNewDocument(t *testing.T) {
+	e := entry.New()
+	e.Record = "test"
+	require.Same(t, e, newDocument(e))
+
+	e.TraceID = []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	e.SpanID = []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	e.TraceFlags = []byte{0x01}
+
+	docJSON, err := json.Marshal(newDocument(e))
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(docJSON, &doc))
+	require.Equal(t, "test", doc["record"])
+	require.Equal(t, map[string]interface{}{"id": "4bf92f3577b34da6a3ce929d0e0e4736"}, doc["trace"])
+	require.Equal(t, map[string]interface{}{"id": "00f067aa0ba902b7"}, doc["span"])
+	require.NotContains(t, doc, "trace_id")
+	require.NotContains(t, doc, "span_id")
+	require.NotContains(t, doc, "trace_flags")
+
+	require.NotNil(t, e.TraceID, "the entry should not be modified")
+}
+
+func TestHandleBulkResponse(t *testing.T) {
+	cfg := NewElasticOutputConfig("test")
+	cfg.DeadLetterOutput = "fake"
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*ElasticOutput)
+
+	deadLetter := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{deadLetter}))
+
+	accepted := entry.New()
+	accepted.Record = "accepted"
+	rejected := entry.New()
+	rejected.Record = "rejected"
+	retried := entry.New()
+	retried.Record = "retried"
+
+	body := `{"errors":true,"items":[
+		{"index":{"status":201}},
+		{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}},
+		{"index":{"status":429,"error":{"type":"es_rejected_execution_exception"}}}
+	]}`
+	retry := op.handleBulkResponse(context.Background(), strings.NewReader(body), []*entry.Entry{accepted, rejected, retried})
+	require.Equal(t, []int{2}, retry)
+
+	select {
+	case e := <-deadLetter.Received:
+		require.Equal(t, "rejected", e.Record)
+		require.Equal(t, "$.test", e.Labels[helper.DeadLetterOperatorLabel])
+		require.Contains(t, e.Labels[helper.DeadLetterErrorLabel], "mapper_parsing_exception")
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for dead lettered entry")
+	}
+	deadLetter.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestElasticRetriesFailedItems(t *testing.T) {
+	var mux sync.Mutex
+	attempts := map[string]int{}
+	received := make(chan string, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		items := make([]string, 0)
+		for dec.More() {
+			var directive, doc map[string]interface{}
+			require.NoError(t, dec.Decode(&directive))
+			require.NoError(t, dec.Decode(&doc))
+
+			record := doc["record"].(string)
+			received <- record
+
+			mux.Lock()
+			attempts[record]++
+			status := 201
+			if record == "retried" && attempts[record] == 1 {
+				status = 429
+			}
+			mux.Unlock()
+			items = append(items, fmt.Sprintf(`{"index":{"status":%d}}`, status))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"errors":true,"items":[%s]}`, strings.Join(items, ","))
+	}))
+	defer ts.Close()
+
+	cfg := NewElasticOutputConfig("test")
+	cfg.Addresses = []string{ts.URL}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	require.NoError(t, op.Start())
+	defer op.Stop()
+
+	for _, record := range []string{"accepted", "retried"} {
+		e := entry.New()
+		e.Record = record
+		require.NoError(t, op.Process(context.Background(), e))
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 3; i++ {
+		select {
+		case record := <-received:
+			counts[record]++
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "Timed out waiting for request", "received %v", counts)
+		}
+	}
+	require.Equal(t, map[string]int{"accepted": 1, "retried": 2}, counts)
+
+	select {
+	case record := <-received:
+		require.FailNow(t, "Received unexpected entry", record)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
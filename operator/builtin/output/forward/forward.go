@@ -54,7 +54,7 @@ func (c ForwardOutputConfig) Build(bc operator.BuildContext) ([]operator.Operato
 		return nil, errors.NewError("missing required parameter 'address'", "")
 	}
 
-	flusher := c.FlusherConfig.Build(bc.Logger.SugaredLogger)
+	flusher := c.FlusherConfig.Build(bc.Logger.SugaredLogger, outputOperator.ID())
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -104,6 +104,16 @@ func (f *ForwardOutput) Stop() error {
 	return f.buffer.Close()
 }
 
+// Status reports the health of the operator's flusher
+func (f *ForwardOutput) Status() operator.Status {
+	return f.flusher.Status()
+}
+
+// Pending returns the number of entries held by the operator's buffer
+func (f *ForwardOutput) Pending() int {
+	return f.buffer.Len()
+}
+
 // Process adds an entry to the outputs buffer
 func (f *ForwardOutput) Process(ctx context.Context, entry *entry.Entry) error {
 	return f.buffer.Add(ctx, entry)
@@ -135,7 +145,10 @@ func (f *ForwardOutput) feedFlusher(ctx context.Context) {
 			req, err := f.createRequest(ctx, entries)
 			if err != nil {
 				f.Errorf("Failed to create request", zap.Error(err))
-				// drop these logs because we couldn't creat a request and a retry won't help
+				// reject these logs because we couldn't creat a request and a retry won't help
+				for _, e := range entries {
+					f.Reject(ctx, e, err)
+				}
 				if err := clearer.MarkAllAsFlushed(); err != nil {
 					f.Errorf("Failed to mark entries as flushed after failing to create a request", zap.Error(err))
 				}
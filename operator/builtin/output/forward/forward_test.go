@@ -38,6 +38,9 @@ func TestForwardOutput(t *testing.T) {
 	newEntry := entry.New()
 	newEntry.Record = "test"
 	newEntry.Timestamp = newEntry.Timestamp.Round(time.Second)
+	newEntry.TraceID = []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	newEntry.SpanID = []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+	newEntry.TraceFlags = []byte{0x01}
 	require.NoError(t, forwardOutput.Start())
 	defer forwardOutput.Stop()
 	require.NoError(t, forwardOutput.Process(context.Background(), newEntry))
@@ -56,5 +59,8 @@ func TestForwardOutput(t *testing.T) {
 		require.Equal(t, newEntry.SeverityText, e.SeverityText)
 		require.Equal(t, newEntry.Labels, e.Labels)
 		require.Equal(t, newEntry.Resource, e.Resource)
+		require.Equal(t, newEntry.TraceID, e.TraceID)
+		require.Equal(t, newEntry.SpanID, e.SpanID)
+		require.Equal(t, newEntry.TraceFlags, e.TraceFlags)
 	}
 }
@@ -92,7 +92,7 @@ func (c GoogleCloudOutputConfig) Build(bc operator.BuildContext) ([]operator.Ope
 		return nil, errors.New("failed to get project id from config or credentials")
 	}
 
-	newFlusher := c.FlusherConfig.Build(bc.Logger.SugaredLogger)
+	newFlusher := c.FlusherConfig.Build(bc.Logger.SugaredLogger, outputOperator.ID())
 	clientOptions := c.createClientOptions(credentials, c.UseCompression)
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -124,6 +124,7 @@ func (c GoogleCloudOutputConfig) Build(bc operator.BuildContext) ([]operator.Ope
 		ctx:            ctx,
 		cancel:         cancel,
 	}
+	requestBuilder.Reject = googleCloudOutput.reject
 
 	return []operator.Operator{googleCloudOutput}, nil
 }
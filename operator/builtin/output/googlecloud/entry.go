@@ -87,9 +87,17 @@ func (g *GoogleEntryBuilder) setLogName(entry *entry.Entry, logEntry *logging.Lo
 	return nil
 }
 
-// setTrace sets the trace of the protobuf entry using a field on the stanza entry
+// setTrace sets the trace of the protobuf entry using a field on the stanza entry.
+// Without a trace field, the trace context of the stanza entry is used instead.
 func (g *GoogleEntryBuilder) setTrace(entry *entry.Entry, logEntry *logging.LogEntry) error {
+	if len(entry.TraceFlags) > 0 {
+		logEntry.TraceSampled = entry.TraceFlags[0]&0x01 == 0x01
+	}
+
 	if g.TraceField == nil {
+		if len(entry.TraceID) > 0 {
+			logEntry.Trace = fmt.Sprintf("projects/%s/traces/%x", g.ProjectID, entry.TraceID)
+		}
 		return nil
 	}
 
@@ -102,9 +110,13 @@ func (g *GoogleEntryBuilder) setTrace(entry *entry.Entry, logEntry *logging.LogE
 	return nil
 }
 
-// setSpanID sets the span id of the protobuf entry using a field on the stanza entry
+// setSpanID sets the span id of the protobuf entry using a field on the stanza entry.
+// Without a span id field, the span id of the stanza entry is used instead.
 func (g *GoogleEntryBuilder) setSpanID(entry *entry.Entry, logEntry *logging.LogEntry) error {
 	if g.SpanIDField == nil {
+		if len(entry.SpanID) > 0 {
+			logEntry.SpanId = fmt.Sprintf("%x", entry.SpanID)
+		}
 		return nil
 	}
 
@@ -139,18 +151,19 @@ func (g *GoogleEntryBuilder) setLocation(entry *entry.Entry, logEntry *logging.L
 	return nil
 }
 
-// setLabels sets the labels of the protobuf entry based on the supplied stanza entry
-func (g *GoogleEntryBuilder) setLabels(entry *entry.Entry, logEntry *logging.LogEntry) error {
-	labels := entry.Labels
-	if labels == nil {
-		labels = make(map[string]string)
+// setLabels sets the labels of the protobuf entry based on the supplied stanza entry.
+// Google Cloud only supports string labels, so other values are formatted as strings.
+func (g *GoogleEntryBuilder) setLabels(e *entry.Entry, logEntry *logging.LogEntry) error {
+	labels := make(map[string]string, len(e.Labels)+len(e.Resource))
+	for key, value := range e.Labels {
+		labels[key] = entry.AttributeString(value)
 	}
 
-	for key, value := range entry.Resource {
+	for key, value := range e.Resource {
 		if _, ok := labels[key]; ok {
 			return fmt.Errorf("duplicate key exists on both labels and resource: %s", key)
 		}
-		labels[key] = value
+		labels[key] = entry.AttributeString(value)
 	}
 
 	logEntry.Labels = labels
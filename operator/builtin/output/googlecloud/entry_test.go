@@ -55,7 +55,7 @@ func TestBuildEntry(t *testing.T) {
 		{
 			name: "missing location field",
 			stanzaEntry: &entry.Entry{
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"host.name": "test_host",
 				},
 			},
@@ -68,10 +68,10 @@ func TestBuildEntry(t *testing.T) {
 			name: "duplicate label keys",
 			stanzaEntry: &entry.Entry{
 				Record: "test record",
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"duplicate_key": "value_1",
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"duplicate_key": "value_2",
 				},
 			},
@@ -118,11 +118,11 @@ func TestBuildEntry(t *testing.T) {
 					"trace":        "test_trace",
 					"span_id":      "test_span",
 				},
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"host.name":      "test_host",
 					"resource_label": "resource_value",
 				},
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"test_label": "test_value",
 				},
 				Timestamp: time.UnixMilli(0),
@@ -162,6 +162,29 @@ func TestBuildEntry(t *testing.T) {
 				Timestamp: timestamppb.New(time.UnixMilli(0)),
 			},
 		},
+		{
+			name: "entry trace context",
+			stanzaEntry: &entry.Entry{
+				Record:     []byte("test"),
+				Timestamp:  time.UnixMilli(0),
+				TraceID:    []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanID:     []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+				TraceFlags: []byte{0x01},
+			},
+			builder: GoogleEntryBuilder{
+				MaxEntrySize: 5000,
+				ProjectID:    "test_project",
+			},
+			expectedEntry: &logging.LogEntry{
+				Payload: &logging.LogEntry_TextPayload{
+					TextPayload: "test",
+				},
+				Trace:        "projects/test_project/traces/4bf92f3577b34da6a3ce929d0e0e4736",
+				SpanId:       "00f067aa0ba902b7",
+				TraceSampled: true,
+				Timestamp:    timestamppb.New(time.UnixMilli(0)),
+			},
+		},
 		{
 			name: "valid bytes entry",
 			stanzaEntry: &entry.Entry{
@@ -202,6 +225,35 @@ func TestBuildEntry(t *testing.T) {
 				Timestamp: timestamppb.New(time.UnixMilli(0)),
 			},
 		},
+		{
+			name: "typed labels",
+			stanzaEntry: &entry.Entry{
+				Record: "test",
+				Labels: map[string]interface{}{
+					"int_label":   5,
+					"float_label": 1.5,
+					"bool_label":  true,
+					"list_label":  []interface{}{"a", 1},
+				},
+				Timestamp: time.UnixMilli(0),
+			},
+			builder: GoogleEntryBuilder{
+				MaxEntrySize: 5000,
+				ProjectID:    "test_project",
+			},
+			expectedEntry: &logging.LogEntry{
+				Payload: &logging.LogEntry_TextPayload{
+					TextPayload: "test",
+				},
+				Labels: map[string]string{
+					"int_label":   "5",
+					"float_label": "1.5",
+					"bool_label":  "true",
+					"list_label":  `["a",1]`,
+				},
+				Timestamp: timestamppb.New(time.UnixMilli(0)),
+			},
+		},
 	}
 
 	for _, tc := range testCases {
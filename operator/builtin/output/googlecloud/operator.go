@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/operator/buffer"
 	"github.com/observiq/stanza/operator/flusher"
 	"github.com/observiq/stanza/operator/helper"
@@ -90,11 +91,26 @@ func (g *GoogleCloudOutput) Stop() error {
 	return nil
 }
 
+// Status reports the health of the operator's flusher
+func (g *GoogleCloudOutput) Status() operator.Status {
+	return g.flusher.Status()
+}
+
+// Pending returns the number of entries held by the operator's buffer
+func (g *GoogleCloudOutput) Pending() int {
+	return g.buffer.Len()
+}
+
 // Process adds an incoming entry to the buffer
 func (g *GoogleCloudOutput) Process(ctx context.Context, e *entry.Entry) error {
 	return g.buffer.Add(ctx, e)
 }
 
+// reject will send an entry that can never be sent to google cloud to the dead letter output
+func (g *GoogleCloudOutput) reject(e *entry.Entry, err error) {
+	g.Reject(g.ctx, e, err)
+}
+
 // testConnection will attempt to send an entry to google cloud logging
 func (g *GoogleCloudOutput) testConnection(ctx context.Context) error {
 	request := g.createTestRequest()
@@ -1,6 +1,8 @@
 package googlecloud
 
 import (
+	"fmt"
+
 	"github.com/observiq/stanza/entry"
 	"go.uber.org/zap"
 
@@ -19,27 +21,42 @@ type GoogleRequestBuilder struct {
 	MaxRequestSize int
 	ProjectID      string
 	EntryBuilder   EntryBuilder
+
+	// Reject is called with the entries that can never be sent. If it is nil, they are dropped.
+	Reject func(*entry.Entry, error)
 	*zap.SugaredLogger
 }
 
 // Build builds a series of write requests from stanza entries
 func (g *GoogleRequestBuilder) Build(entries []*entry.Entry) []*logging.WriteLogEntriesRequest {
 	protoEntries := []*logging.LogEntry{}
+	sources := make(map[*logging.LogEntry]*entry.Entry, len(entries))
 	for _, entry := range entries {
 		protoEntry, err := g.EntryBuilder.Build(entry)
 		if err != nil {
-			g.Errorw("Failed to create protobuf entry. Dropping entry", zap.Any("error", err))
+			g.reject(entry, fmt.Errorf("create protobuf entry: %w", err))
 			continue
 		}
 		protoEntries = append(protoEntries, protoEntry)
+		sources[protoEntry] = entry
 	}
 
-	return g.buildRequests(protoEntries)
+	return g.buildRequests(protoEntries, sources)
+}
+
+// reject will pass an entry that can never be sent to the reject function, or drop it
+func (g *GoogleRequestBuilder) reject(e *entry.Entry, err error) {
+	if g.Reject == nil {
+		g.Errorw("Dropping entry that can not be sent", zap.Error(err))
+		return
+	}
+	g.Reject(e, err)
 }
 
-// buildRequests builds a series of requests from the supplied protobuf entries.
-// The number of requests created cooresponds to the max request size of the builder.
-func (g *GoogleRequestBuilder) buildRequests(entries []*logging.LogEntry) []*logging.WriteLogEntriesRequest {
+// buildRequests builds a series of requests from the supplied protobuf entries, which
+// were built from the sources. The number of requests created cooresponds to the max
+// request size of the builder.
+func (g *GoogleRequestBuilder) buildRequests(entries []*logging.LogEntry, sources map[*logging.LogEntry]*entry.Entry) []*logging.WriteLogEntriesRequest {
 	request := g.buildRequest(entries)
 	size := proto.Size(request)
 	if size <= g.MaxRequestSize {
@@ -48,7 +65,7 @@ func (g *GoogleRequestBuilder) buildRequests(entries []*logging.LogEntry) []*log
 	}
 
 	if len(request.Entries) == 1 {
-		g.Errorw("Single entry exceeds max request size. Dropping entry", "size", size)
+		g.reject(sources[request.Entries[0]], fmt.Errorf("entry of size %d exceeds max request size %d", size, g.MaxRequestSize))
 		return []*logging.WriteLogEntriesRequest{}
 	}
 
@@ -70,7 +87,7 @@ func (g *GoogleRequestBuilder) buildRequests(entries []*logging.LogEntry) []*log
 	}
 
 	secondEntries := request.Entries[index:totalEntries]
-	secondRequests := g.buildRequests(secondEntries)
+	secondRequests := g.buildRequests(secondEntries, sources)
 
 	return append([]*logging.WriteLogEntriesRequest{firstRequest}, secondRequests...)
 }
@@ -120,3 +120,27 @@ func createEntry(num int) (*entry.Entry, *logging.LogEntry) {
 	result := &logging.LogEntry{Payload: &logging.LogEntry_TextPayload{TextPayload: fmt.Sprintf("request %d", num)}}
 	return entry, result
 }
+
+func TestImpossibleEntryRejected(t *testing.T) {
+	entryOne := &entry.Entry{Record: "Test Request"}
+	resultOne := &logging.LogEntry{Payload: &logging.LogEntry_TextPayload{TextPayload: "Test Request"}}
+
+	entryBuilder := &MockEntryBuilder{}
+	entryBuilder.On("Build", entryOne).Return(resultOne, nil)
+
+	var rejected []*entry.Entry
+	requestBuilder := GoogleRequestBuilder{
+		MaxRequestSize: 1,
+		ProjectID:      "test_project",
+		EntryBuilder:   entryBuilder,
+		SugaredLogger:  zap.NewNop().Sugar(),
+		Reject: func(e *entry.Entry, err error) {
+			require.Error(t, err)
+			rejected = append(rejected, e)
+		},
+	}
+
+	requests := requestBuilder.Build([]*entry.Entry{entryOne})
+	require.Len(t, requests, 0)
+	require.Equal(t, []*entry.Entry{entryOne}, rejected)
+}
@@ -79,14 +79,19 @@ func hasResourceKeys(entry *entry.Entry, keys ...string) bool {
 	return true
 }
 
+// resourceValue returns the value of a resource key of the entry, formatted as a string
+func resourceValue(e *entry.Entry, key string) string {
+	return entry.AttributeString(e.Resource[key])
+}
+
 // createPodResource creates a pod resource from the entry
 func createPodResource(entry *entry.Entry) *monitoredres.MonitoredResource {
 	resource := &monitoredres.MonitoredResource{
 		Type: k8sPod,
 		Labels: map[string]string{
-			"pod_name":       entry.Resource[k8sPodName],
-			"namespace_name": entry.Resource[k8sNamespace],
-			"cluster_name":   entry.Resource[k8sClusterName],
+			"pod_name":       resourceValue(entry, k8sPodName),
+			"namespace_name": resourceValue(entry, k8sNamespace),
+			"cluster_name":   resourceValue(entry, k8sClusterName),
 		},
 	}
 
@@ -99,18 +104,18 @@ func createPodResource(entry *entry.Entry) *monitoredres.MonitoredResource {
 
 // createContainerResource creates a container resource from the entry
 func createContainerResource(entry *entry.Entry) *monitoredres.MonitoredResource {
-	containerName := entry.Resource[containerName]
+	containerName := resourceValue(entry, containerName)
 	if containerName == "" {
-		containerName = entry.Resource[k8sContainerName]
+		containerName = resourceValue(entry, k8sContainerName)
 	}
 
 	resource := &monitoredres.MonitoredResource{
 		Type: k8sContainer,
 		Labels: map[string]string{
 			"container_name": containerName,
-			"pod_name":       entry.Resource[k8sPodName],
-			"namespace_name": entry.Resource[k8sNamespace],
-			"cluster_name":   entry.Resource[k8sClusterName],
+			"pod_name":       resourceValue(entry, k8sPodName),
+			"namespace_name": resourceValue(entry, k8sNamespace),
+			"cluster_name":   resourceValue(entry, k8sClusterName),
 		},
 	}
 
@@ -128,8 +133,8 @@ func createNodeResource(entry *entry.Entry) *monitoredres.MonitoredResource {
 	resource := &monitoredres.MonitoredResource{
 		Type: k8sNode,
 		Labels: map[string]string{
-			"cluster_name": entry.Resource[k8sClusterName],
-			"node_name":    entry.Resource[hostName],
+			"cluster_name": resourceValue(entry, k8sClusterName),
+			"node_name":    resourceValue(entry, hostName),
 		},
 	}
 
@@ -144,7 +149,7 @@ func createClusterResource(entry *entry.Entry) *monitoredres.MonitoredResource {
 	resource := &monitoredres.MonitoredResource{
 		Type: k8sCluster,
 		Labels: map[string]string{
-			"cluster_name": entry.Resource[k8sClusterName],
+			"cluster_name": resourceValue(entry, k8sClusterName),
 		},
 	}
 
@@ -158,7 +163,7 @@ func createGenericResource(entry *entry.Entry) *monitoredres.MonitoredResource {
 	resource := &monitoredres.MonitoredResource{
 		Type: genericNode,
 		Labels: map[string]string{
-			"node_id": entry.Resource[hostName],
+			"node_id": resourceValue(entry, hostName),
 		},
 	}
 
@@ -17,7 +17,7 @@ func TestGetResource(t *testing.T) {
 		{
 			name: "k8s pod",
 			entry: &entry.Entry{
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"k8s.pod.name":       "test_pod",
 					"k8s.namespace.name": "test_namespace",
 					"k8s.cluster.name":   "test_cluster",
@@ -35,7 +35,7 @@ func TestGetResource(t *testing.T) {
 		{
 			name: "k8s container simple",
 			entry: &entry.Entry{
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"container.name":     "test_container",
 					"k8s.pod.name":       "test_pod",
 					"k8s.namespace.name": "test_namespace",
@@ -55,7 +55,7 @@ func TestGetResource(t *testing.T) {
 		{
 			name: "k8s container longhand",
 			entry: &entry.Entry{
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"k8s.container.name": "test_container",
 					"k8s.pod.name":       "test_pod",
 					"k8s.namespace.name": "test_namespace",
@@ -75,7 +75,7 @@ func TestGetResource(t *testing.T) {
 		{
 			name: "k8s node",
 			entry: &entry.Entry{
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"k8s.cluster.name": "test_cluster",
 					"host.name":        "test_host",
 				},
@@ -91,7 +91,7 @@ func TestGetResource(t *testing.T) {
 		{
 			name: "k8s cluster",
 			entry: &entry.Entry{
-				Resource: map[string]string{
+				Resource: map[string]interface{}{
 					"k8s.cluster.name": "test_cluster",
 				},
 			},
@@ -105,7 +105,7 @@ func TestGetResource(t *testing.T) {
 		{
 			name: "unknown",
 			entry: &entry.Entry{
-				Resource: map[string]string{},
+				Resource: map[string]interface{}{},
 			},
 			resource: nil,
 		},
@@ -72,7 +72,7 @@ func (c NewRelicOutputConfig) Build(bc operator.BuildContext) ([]operator.Operat
 		return nil, errors.Wrap(err, "'base_uri' is not a valid URL")
 	}
 
-	flusher := c.FlusherConfig.Build(bc.Logger.SugaredLogger)
+	flusher := c.FlusherConfig.Build(bc.Logger.SugaredLogger, outputOperator.ID())
 	ctx, cancel := context.WithCancel(context.Background())
 
 	nro := &NewRelicOutput{
@@ -148,6 +148,16 @@ func (nro *NewRelicOutput) Stop() error {
 	return nro.buffer.Close()
 }
 
+// Status reports the health of the operator's flusher
+func (nro *NewRelicOutput) Status() operator.Status {
+	return nro.flusher.Status()
+}
+
+// Pending returns the number of entries held by the operator's buffer
+func (nro *NewRelicOutput) Pending() int {
+	return nro.buffer.Len()
+}
+
 // Process adds an entry to the output's buffer
 func (nro *NewRelicOutput) Process(ctx context.Context, entry *entry.Entry) error {
 	return nro.buffer.Add(ctx, entry)
@@ -184,7 +194,10 @@ func (nro *NewRelicOutput) feedFlusher(ctx context.Context) {
 			req, err := nro.newRequest(ctx, entries)
 			if err != nil {
 				nro.Errorw("Failed to create request from payload", zap.Error(err))
-				// drop these logs because we couldn't creat a request and a retry won't help
+				// reject these logs because we couldn't creat a request and a retry won't help
+				for _, e := range entries {
+					nro.Reject(ctx, e, err)
+				}
 				if err := clearer.MarkAllAsFlushed(); err != nil {
 					nro.Errorf("Failed to mark entries as flushed after failing to create a request", zap.Error(err))
 				}
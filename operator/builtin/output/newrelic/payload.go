@@ -1,6 +1,8 @@
 package newrelic
 
 import (
+	"encoding/hex"
+
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/version"
 )
@@ -59,6 +61,13 @@ func LogMessageFromEntry(entry *entry.Entry, messageField entry.Field) *LogMessa
 	logMessage.Attributes["labels"] = entry.Labels
 	logMessage.Attributes["severity"] = entry.Severity.String()
 
+	if len(entry.TraceID) > 0 {
+		logMessage.Attributes["trace.id"] = hex.EncodeToString(entry.TraceID)
+	}
+	if len(entry.SpanID) > 0 {
+		logMessage.Attributes["span.id"] = hex.EncodeToString(entry.SpanID)
+	}
+
 	return logMessage
 }
 
@@ -104,7 +104,7 @@ type CSVParser struct {
 // Process will parse an entry for csv.
 func (r *CSVParser) Process(ctx context.Context, e *entry.Entry) error {
 	if r.headerLabel != "" {
-		h, ok := e.Labels[r.headerLabel]
+		h, ok := e.Labels[r.headerLabel].(string)
 		if !ok {
 			// TODO: returned error is not logged, so log it here
 			err := fmt.Errorf("failed to read dynamic header label %s", r.headerLabel)
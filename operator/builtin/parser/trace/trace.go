@@ -0,0 +1,57 @@
+package trace
+
+import (
+	"context"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("trace_parser", func() operator.Builder { return NewTraceParserConfig("") })
+}
+
+// NewTraceParserConfig creates a new trace parser config with default values
+func NewTraceParserConfig(operatorID string) *TraceParserConfig {
+	return &TraceParserConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "trace_parser"),
+		TraceParser:       helper.NewTraceParser(),
+	}
+}
+
+// TraceParserConfig is the configuration of a trace parser operator.
+type TraceParserConfig struct {
+	helper.TransformerConfig `yaml:",inline"`
+	helper.TraceParser       `yaml:",omitempty,inline"`
+}
+
+// Build will build a trace parser operator.
+func (c TraceParserConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.TraceParser.Validate(context); err != nil {
+		return nil, err
+	}
+
+	traceParser := &TraceParserOperator{
+		TransformerOperator: transformerOperator,
+		TraceParser:         c.TraceParser,
+	}
+
+	return []operator.Operator{traceParser}, nil
+}
+
+// TraceParserOperator is an operator that parses the trace context of an entry from its fields.
+type TraceParserOperator struct {
+	helper.TransformerOperator
+	helper.TraceParser
+}
+
+// Process will parse the trace context of an entry.
+func (t *TraceParserOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return t.ProcessWith(ctx, entry, t.TraceParser.Parse)
+}
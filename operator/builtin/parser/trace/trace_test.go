@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceParserBuild(t *testing.T) {
+	cfg := NewTraceParserConfig("test_operator_id")
+	cfg.OutputIDs = []string{"output1"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.IsType(t, &TraceParserOperator{}, ops[0])
+}
+
+func TestTraceParserProcess(t *testing.T) {
+	cfg := NewTraceParserConfig("test_operator_id")
+	cfg.OutputIDs = []string{"output1"}
+	parseFrom := entry.NewLabelField("traceparent")
+	cfg.Traceparent.ParseFrom = &parseFrom
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0]
+
+	mockOutput := &testutil.Operator{}
+	resultChan := make(chan *entry.Entry, 1)
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		resultChan <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+	op.(*TraceParserOperator).OutputOperators = []operator.Operator{mockOutput}
+
+	ent := entry.New()
+	ent.AddLabel("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ent.Record = map[string]interface{}{"span_id": "b7ad6b7169203331"}
+
+	require.NoError(t, op.Process(context.Background(), ent))
+	result := <-resultChan
+
+	require.Equal(t, []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}, result.TraceID)
+	require.Equal(t, []byte{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31}, result.SpanID, "the span_id field should override the traceparent")
+	require.Equal(t, []byte{0x00}, result.TraceFlags)
+	require.Empty(t, result.Labels)
+	require.Equal(t, map[string]interface{}{}, result.Record)
+}
+
+func TestTraceParserInvalid(t *testing.T) {
+	cfg := NewTraceParserConfig("test_operator_id")
+	cfg.OutputIDs = []string{"output1"}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*TraceParserOperator)
+
+	ent := entry.New()
+	ent.Record = map[string]interface{}{"trace_id": "not-a-trace-id"}
+
+	err = op.Process(context.Background(), ent)
+	require.Error(t, err)
+	require.Nil(t, ent.TraceID)
+}
@@ -120,7 +120,7 @@ func TestProcessAndBuild(t *testing.T) {
 			newTestEntry,
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{"new": "newVal"}
+				e.Labels = map[string]interface{}{"new": "newVal"}
 				return e
 			},
 			false,
@@ -136,7 +136,7 @@ func TestProcessAndBuild(t *testing.T) {
 			newTestEntry,
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "newVal"}
+				e.Resource = map[string]interface{}{"new": "newVal"}
 				return e
 			},
 			false,
@@ -152,7 +152,7 @@ func TestProcessAndBuild(t *testing.T) {
 			newTestEntry,
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "val_suffix"}
+				e.Resource = map[string]interface{}{"new": "val_suffix"}
 				return e
 			},
 			false,
@@ -231,6 +231,42 @@ func TestProcessAndBuild(t *testing.T) {
 				return cfg
 			}(),
 			newTestEntry,
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Resource = map[string]interface{}{
+					"new": 1,
+				}
+				return e
+			},
+			false,
+		},
+		{
+			"add_list_to_label",
+			func() *AddOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewLabelField("new")
+				cfg.Value = []string{"a", "b"}
+				return cfg
+			}(),
+			newTestEntry,
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Labels = map[string]interface{}{
+					"new": []interface{}{"a", "b"},
+				}
+				return e
+			},
+			false,
+		},
+		{
+			"add_map_to_resource",
+			func() *AddOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewResourceField("new")
+				cfg.Value = map[string]interface{}{"key": "value"}
+				return cfg
+			}(),
+			newTestEntry,
 			nil,
 			true,
 		},
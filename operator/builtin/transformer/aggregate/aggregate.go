@@ -0,0 +1,265 @@
+package aggregate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"go.uber.org/zap"
+)
+
+func init() {
+	operator.Register("aggregate", func() operator.Builder { return NewAggregateConfig("") })
+}
+
+// NewAggregateConfig creates a new aggregate config with default values
+func NewAggregateConfig(operatorID string) *AggregateConfig {
+	return &AggregateConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "aggregate"),
+		Interval:          helper.NewDuration(time.Minute),
+		Percentiles:       []float64{50, 90, 99},
+		MaxGroups:         10000,
+	}
+}
+
+// AggregateConfig is the configuration of an aggregate operator
+type AggregateConfig struct {
+	helper.TransformerConfig `yaml:",inline"`
+
+	GroupBy     []entry.Field   `json:"group_by,omitempty"    yaml:"group_by,omitempty"`
+	ValueField  *entry.Field    `json:"value_field,omitempty" yaml:"value_field,omitempty"`
+	Percentiles []float64       `json:"percentiles,omitempty" yaml:"percentiles,omitempty"`
+	Interval    helper.Duration `json:"interval,omitempty"    yaml:"interval,omitempty"`
+	MaxGroups   int             `json:"max_groups,omitempty"  yaml:"max_groups,omitempty"`
+	Forward     bool            `json:"forward,omitempty"     yaml:"forward,omitempty"`
+}
+
+// Build will build an aggregate operator from the supplied configuration
+func (c AggregateConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range c.GroupBy {
+		if _, ok := field.FieldInterface.(entry.SelectorField); ok {
+			return nil, fmt.Errorf("aggregate: group_by field %s cannot be a selector", field)
+		}
+	}
+
+	for _, p := range c.Percentiles {
+		if p <= 0 || p > 100 {
+			return nil, fmt.Errorf("aggregate: percentile %v must be greater than 0 and at most 100", p)
+		}
+	}
+
+	if c.Interval.Raw() <= 0 {
+		return nil, fmt.Errorf("aggregate: interval must be greater than zero")
+	}
+
+	if c.MaxGroups <= 0 {
+		return nil, fmt.Errorf("aggregate: max_groups must be greater than zero")
+	}
+
+	aggregateOperator := &AggregateOperator{
+		TransformerOperator: transformerOperator,
+		groupBy:             c.GroupBy,
+		valueField:          c.ValueField,
+		percentiles:         c.Percentiles,
+		interval:            c.Interval.Raw(),
+		maxGroups:           c.MaxGroups,
+		forward:             c.Forward,
+		groups:              make(map[string]*group),
+		start:               time.Now(),
+	}
+
+	return []operator.Operator{aggregateOperator}, nil
+}
+
+// AggregateOperator is an operator that summarizes the entries of each group over an interval
+type AggregateOperator struct {
+	helper.TransformerOperator
+
+	groupBy     []entry.Field
+	valueField  *entry.Field
+	percentiles []float64
+	interval    time.Duration
+	maxGroups   int
+	forward     bool
+
+	// groups holds the groups of the current interval by key, and start holds the time the interval started
+	mux    sync.Mutex
+	groups map[string]*group
+	start  time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start will start summarizing the groups at the end of each interval
+func (a *AggregateOperator) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+
+	a.mux.Lock()
+	a.start = time.Now()
+	a.mux.Unlock()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.flush(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop will stop the intervals, then summarize the groups of the current interval
+func (a *AggregateOperator) Stop() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	a.flush(ctx)
+	return nil
+}
+
+// Process will add an entry to the statistics of its group, and forward it if configured
+func (a *AggregateOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := a.Skip(ctx, e)
+	if err != nil {
+		return a.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		a.Write(ctx, e)
+		return nil
+	}
+
+	if err := a.add(e); err != nil {
+		return a.HandleEntryError(ctx, e, err)
+	}
+
+	if a.forward {
+		a.Write(ctx, e)
+	}
+	return nil
+}
+
+// add adds an entry to the statistics of its group
+func (a *AggregateOperator) add(e *entry.Entry) error {
+	values := make([]interface{}, 0, len(a.groupBy))
+	for _, field := range a.groupBy {
+		value, _ := e.Get(field)
+		values = append(values, value)
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("aggregate: encode group: %s", err)
+	}
+	key := string(encoded)
+
+	var observed *float64
+	if a.valueField != nil {
+		if value, ok := e.Get(*a.valueField); ok && value != nil {
+			number, err := toFloat(value)
+			if err != nil {
+				return fmt.Errorf("aggregate: value_field %s: %s", a.valueField, err)
+			}
+			observed = &number
+		}
+	}
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	g, ok := a.groups[key]
+	if !ok {
+		if len(a.groups) >= a.maxGroups {
+			return fmt.Errorf("aggregate: max_groups of %d reached, so the entry cannot be aggregated", a.maxGroups)
+		}
+		g = &group{values: values}
+		a.groups[key] = g
+	}
+
+	g.count++
+	if observed != nil {
+		g.observe(*observed)
+	}
+	if !a.forward {
+		if ack := e.RetainAck(); ack != nil {
+			g.acks = append(g.acks, ack)
+		}
+	}
+	return nil
+}
+
+// flush writes a summary of each group of the current interval, then starts a new interval
+func (a *AggregateOperator) flush(ctx context.Context) {
+	a.mux.Lock()
+	groups, start := a.groups, a.start
+	a.groups = make(map[string]*group, len(groups))
+	a.start = time.Now()
+	a.mux.Unlock()
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		summary, err := a.summarize(groups[key], start)
+		if err != nil {
+			a.Errorw("Failed to create summary", zap.Error(err))
+			continue
+		}
+		a.Write(ctx, summary)
+		summary.Release()
+	}
+}
+
+// summarize creates the summary entry of a group. The summary holds the values of the group_by
+// fields at the same fields as the entries of the group, and the statistics in its record. It
+// holds the acknowledgements of the entries of the group, and must be released once written.
+// If the summary can not be created, the acknowledgements are released.
+func (a *AggregateOperator) summarize(g *group, start time.Time) (*entry.Entry, error) {
+	summary := entry.New()
+	summary.Timestamp = start
+	summary.CombineAcks(g.acks)
+
+	record := g.stats(a.percentiles)
+	record["interval"] = a.interval.String()
+	summary.Record = record
+
+	for i, field := range a.groupBy {
+		if g.values[i] == nil {
+			continue
+		}
+		if err := summary.Set(field, g.values[i]); err != nil {
+			summary.Release()
+			return nil, fmt.Errorf("set group_by field %s: %s", field, err)
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,351 @@
+package aggregate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testCase struct {
+	name      string
+	expectErr bool
+	op        *AggregateConfig
+	input     func() []*entry.Entry
+	output    func() []*entry.Entry
+}
+
+func newTestEntry(record map[string]interface{}) *entry.Entry {
+	e := entry.New()
+	e.Timestamp = time.Unix(1586632809, 0)
+	e.Record = record
+	return e
+}
+
+// newSummary creates a summary entry. The timestamp of a summary is the start of its interval,
+// so it is left unset and is not compared.
+func newSummary(labels map[string]interface{}, record map[string]interface{}) *entry.Entry {
+	e := entry.New()
+	e.Timestamp = time.Time{}
+	e.Labels = labels
+	e.Record = record
+	return e
+}
+
+// Test building and processing an AggregateConfig. The operator is not started, so
+// its groups are only summarized when it stops.
+func TestBuildAndProcess(t *testing.T) {
+	cases := []testCase{
+		{
+			"groups",
+			false,
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.GroupBy = []entry.Field{entry.NewLabelField("service"), entry.NewRecordField("status")}
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				entries := make([]*entry.Entry, 0)
+				for _, group := range []struct {
+					service string
+					status  int
+				}{
+					{"billing", 200}, {"billing", 200}, {"billing", 500}, {"auth", 200},
+				} {
+					e := newTestEntry(map[string]interface{}{"status": group.status, "path": "/"})
+					e.Labels = map[string]interface{}{"service": group.service}
+					entries = append(entries, e)
+				}
+				return entries
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newSummary(
+						map[string]interface{}{"service": "auth"},
+						map[string]interface{}{"status": 200, "count": 1, "interval": "1m0s"},
+					),
+					newSummary(
+						map[string]interface{}{"service": "billing"},
+						map[string]interface{}{"status": 200, "count": 2, "interval": "1m0s"},
+					),
+					newSummary(
+						map[string]interface{}{"service": "billing"},
+						map[string]interface{}{"status": 500, "count": 1, "interval": "1m0s"},
+					),
+				}
+			},
+		},
+		{
+			"value_field",
+			false,
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.GroupBy = []entry.Field{entry.NewRecordField("endpoint")}
+				valueField := entry.NewRecordField("latency")
+				cfg.ValueField = &valueField
+				cfg.Percentiles = []float64{1, 99}
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				entries := make([]*entry.Entry, 0)
+				for _, latency := range []interface{}{"0.5", 0.1, 2, nil, 0.3} {
+					record := map[string]interface{}{"endpoint": "/orders"}
+					if latency != nil {
+						record["latency"] = latency
+					}
+					entries = append(entries, newTestEntry(record))
+				}
+				return entries
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newSummary(nil, map[string]interface{}{
+						"endpoint": "/orders",
+						"interval": "1m0s",
+						"count":    5,
+						"sum":      2.9,
+						"min":      0.1,
+						"max":      2.0,
+						"p1":       0.1,
+						"p99":      2.0,
+					}),
+				}
+			},
+		},
+		{
+			"invalid_value",
+			true,
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				valueField := entry.NewRecordField("latency")
+				cfg.ValueField = &valueField
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return []*entry.Entry{newTestEntry(map[string]interface{}{"latency": "fast"})}
+			},
+			func() []*entry.Entry {
+				return nil
+			},
+		},
+		{
+			"max_groups",
+			true,
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.GroupBy = []entry.Field{entry.NewRecordField("id")}
+				cfg.MaxGroups = 2
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"id": 1}),
+					newTestEntry(map[string]interface{}{"id": 2}),
+					newTestEntry(map[string]interface{}{"id": 1}),
+					newTestEntry(map[string]interface{}{"id": 3}),
+				}
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newSummary(nil, map[string]interface{}{"id": 1, "count": 2, "interval": "1m0s"}),
+					newSummary(nil, map[string]interface{}{"id": 2, "count": 1, "interval": "1m0s"}),
+				}
+			},
+		},
+		{
+			"forward",
+			false,
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.Forward = true
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return []*entry.Entry{newTestEntry(map[string]interface{}{"message": "test"})}
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"message": "test"}),
+					newSummary(nil, map[string]interface{}{"count": 1, "interval": "1m0s"}),
+				}
+			},
+		},
+		{
+			"if",
+			false,
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.IfExpr = `$record.type == "access"`
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"type": "audit"}),
+					newTestEntry(map[string]interface{}{"type": "access"}),
+				}
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"type": "audit"}),
+					newSummary(nil, map[string]interface{}{"count": 1, "interval": "1m0s"}),
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.op
+			cfg.OutputIDs = []string{"fake"}
+			cfg.OnError = "drop"
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			aggregate := op.(*AggregateOperator)
+			fake := testutil.NewFakeOutput(t)
+			aggregate.SetOutputs([]operator.Operator{fake})
+
+			var processErr error
+			for _, val := range tc.input() {
+				if err := aggregate.Process(context.Background(), val); err != nil {
+					processErr = err
+				}
+			}
+			if tc.expectErr {
+				require.Error(t, processErr)
+			} else {
+				require.NoError(t, processErr)
+			}
+
+			require.NoError(t, aggregate.Stop())
+			for _, expected := range tc.output() {
+				e := fake.ReceiveEntry(t)
+				if expected.Timestamp.IsZero() {
+					expected.Timestamp = e.Timestamp
+				}
+				require.Equal(t, expected, e)
+			}
+			fake.ExpectNoEntry(t, 10*time.Millisecond)
+		})
+	}
+}
+
+func defaultCfg() *AggregateConfig {
+	return NewAggregateConfig("aggregate")
+}
+
+func TestBuildInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       *AggregateConfig
+		expected string
+	}{
+		{
+			"selector",
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.GroupBy = []entry.Field{entry.NewRecordSelector("*")}
+				return cfg
+			}(),
+			"cannot be a selector",
+		},
+		{
+			"zero_percentile",
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.Percentiles = []float64{0}
+				return cfg
+			}(),
+			"must be greater than 0 and at most 100",
+		},
+		{
+			"large_percentile",
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.Percentiles = []float64{100.5}
+				return cfg
+			}(),
+			"must be greater than 0 and at most 100",
+		},
+		{
+			"zero_interval",
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.Interval = helper.NewDuration(0)
+				return cfg
+			}(),
+			"interval must be greater than zero",
+		},
+		{
+			"zero_max_groups",
+			func() *AggregateConfig {
+				cfg := defaultCfg()
+				cfg.MaxGroups = 0
+				return cfg
+			}(),
+			"max_groups must be greater than zero",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.op.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestAggregateInterval(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Interval = helper.NewDuration(50 * time.Millisecond)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	aggregate := ops[0].(*AggregateOperator)
+	fake := testutil.NewFakeOutput(t)
+	aggregate.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, aggregate.Start())
+	defer aggregate.Stop()
+
+	before := time.Now()
+	require.NoError(t, aggregate.Process(context.Background(), newTestEntry(map[string]interface{}{"message": "a"})))
+	require.NoError(t, aggregate.Process(context.Background(), newTestEntry(map[string]interface{}{"message": "b"})))
+
+	summary := fake.ReceiveEntry(t)
+	require.Equal(t, 2, summary.Record.(map[string]interface{})["count"])
+	require.False(t, summary.Timestamp.After(before))
+
+	// Each interval starts with no groups
+	fake.ExpectNoEntry(t, 100*time.Millisecond)
+	require.NoError(t, aggregate.Process(context.Background(), newTestEntry(map[string]interface{}{"message": "c"})))
+	require.Equal(t, 1, fake.ReceiveEntry(t).Record.(map[string]interface{})["count"])
+}
+
+func TestAggregateAck(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	aggregate := ops[0].(*AggregateOperator)
+	fake := testutil.NewFakeOutput(t)
+	aggregate.SetOutputs([]operator.Operator{fake})
+
+	// An entry that is not forwarded is acknowledged once the summary of its group is delivered
+	var acked bool
+	e := newTestEntry(map[string]interface{}{"message": "a"})
+	e.SetAck(entry.NewAck(func() { acked = true }))
+	require.NoError(t, aggregate.Process(context.Background(), e))
+	e.Release()
+	require.False(t, acked)
+
+	require.NoError(t, aggregate.Stop())
+	require.Equal(t, 1, fake.ReceiveEntry(t).Record.(map[string]interface{})["count"])
+	require.True(t, acked)
+}
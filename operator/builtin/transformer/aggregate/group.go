@@ -0,0 +1,116 @@
+package aggregate
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/observiq/stanza/entry"
+)
+
+// group holds the statistics of the entries of a group over an interval
+type group struct {
+	values []interface{}
+	count  int
+	sum    float64
+	min    float64
+	max    float64
+	// observed estimates the percentiles of the values of the value field in bounded memory
+	observed *sketch
+	// acks holds the acknowledgements of the entries of the group that were not forwarded,
+	// which are released once the summary of the group has been delivered
+	acks []*entry.Ack
+}
+
+// observe adds a value of the value field to the statistics of the group
+func (g *group) observe(value float64) {
+	if g.observed == nil {
+		g.observed = newSketch()
+	}
+	if g.observed.count == 0 || value < g.min {
+		g.min = value
+	}
+	if g.observed.count == 0 || value > g.max {
+		g.max = value
+	}
+	g.sum += value
+	g.observed.add(value)
+}
+
+// stats returns the statistics of the group, by name. The statistics of the value
+// field are only included if the group observed at least one value.
+func (g *group) stats(percentiles []float64) map[string]interface{} {
+	stats := map[string]interface{}{
+		"count": g.count,
+	}
+
+	if g.observed == nil || g.observed.count == 0 {
+		return stats
+	}
+
+	stats["sum"] = g.sum
+	stats["min"] = g.min
+	stats["max"] = g.max
+
+	for _, p := range percentiles {
+		stats[percentileName(p)] = g.percentile(p)
+	}
+	return stats
+}
+
+// percentile estimates a percentile of the values of the value field. The lowest and highest
+// ranks are the minimum and maximum, which are exact, and other estimates are limited to them.
+func (g *group) percentile(p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(g.observed.count)))
+	switch {
+	case rank <= 1:
+		return g.min
+	case rank >= g.observed.count:
+		return g.max
+	}
+	return math.Max(g.min, math.Min(g.max, g.observed.percentile(p)))
+}
+
+// percentileName returns the name of the statistic of a percentile, such as p99 or p99_9
+func percentileName(p float64) string {
+	return "p" + strings.ReplaceAll(strconv.FormatFloat(p, 'f', -1, 64), ".", "_")
+}
+
+// toFloat converts a numeric value, or a string that holds a number, to a float
+func toFloat(value interface{}) (float64, error) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, nil
+	case float32:
+		return float64(typed), nil
+	case int:
+		return float64(typed), nil
+	case int8:
+		return float64(typed), nil
+	case int16:
+		return float64(typed), nil
+	case int32:
+		return float64(typed), nil
+	case int64:
+		return float64(typed), nil
+	case uint:
+		return float64(typed), nil
+	case uint8:
+		return float64(typed), nil
+	case uint16:
+		return float64(typed), nil
+	case uint32:
+		return float64(typed), nil
+	case uint64:
+		return float64(typed), nil
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			return 0, fmt.Errorf("'%s' is not a number", typed)
+		}
+		return parsed, nil
+	default:
+		return 0, fmt.Errorf("type '%T' is not a number", value)
+	}
+}
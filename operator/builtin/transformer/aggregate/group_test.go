@@ -0,0 +1,92 @@
+package aggregate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupStats(t *testing.T) {
+	g := &group{count: 12}
+	for _, value := range []float64{5, 1, 9, 3, 7, 2, 8, 4, 6, 10} {
+		g.observe(value)
+	}
+
+	stats := g.stats([]float64{50, 90, 99, 99.9, 100, 1})
+
+	// Percentiles between the lowest and highest ranks are estimates
+	require.InEpsilon(t, 5.0, stats["p50"], relativeAccuracy)
+	require.InEpsilon(t, 9.0, stats["p90"], relativeAccuracy)
+	delete(stats, "p50")
+	delete(stats, "p90")
+
+	require.Equal(t, map[string]interface{}{
+		"count": 12,
+		"sum":   55.0,
+		"min":   1.0,
+		"max":   10.0,
+		"p99":   10.0,
+		"p99_9": 10.0,
+		"p100":  10.0,
+		"p1":    1.0,
+	}, stats)
+}
+
+func TestGroupStatsManyValues(t *testing.T) {
+	g := &group{}
+	for i := 1; i <= 100000; i++ {
+		g.observe(float64(i))
+	}
+
+	stats := g.stats([]float64{50, 90, 99})
+	require.InEpsilon(t, 50000.0, stats["p50"], relativeAccuracy)
+	require.InEpsilon(t, 90000.0, stats["p90"], relativeAccuracy)
+	require.InEpsilon(t, 99000.0, stats["p99"], relativeAccuracy)
+
+	// The memory of a group does not grow with the number of values
+	require.Less(t, len(g.observed.positive), 1000)
+}
+
+func TestGroupStatsNegative(t *testing.T) {
+	g := &group{count: 3}
+	g.observe(-5)
+	g.observe(-10)
+
+	g.observe(-7)
+
+	stats := g.stats([]float64{50})
+	require.Equal(t, -10.0, stats["min"])
+	require.Equal(t, -5.0, stats["max"])
+	require.InEpsilon(t, -7.0, stats["p50"], relativeAccuracy)
+}
+
+func TestGroupStatsWithoutValues(t *testing.T) {
+	g := &group{count: 3}
+	require.Equal(t, map[string]interface{}{"count": 3}, g.stats([]float64{50}))
+}
+
+func TestToFloat(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected float64
+	}{
+		{1.5, 1.5},
+		{float32(0.5), 0.5},
+		{200, 200},
+		{int64(-3), -3},
+		{uint8(7), 7},
+		{"0.25", 0.25},
+		{" 42 ", 42},
+	}
+
+	for _, tc := range cases {
+		number, err := toFloat(tc.value)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, number)
+	}
+
+	for _, value := range []interface{}{"fast", "NaN", "Inf", true, []interface{}{1}} {
+		_, err := toFloat(value)
+		require.Error(t, err, "%v", value)
+	}
+}
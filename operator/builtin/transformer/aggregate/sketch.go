@@ -0,0 +1,126 @@
+package aggregate
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// relativeAccuracy is the maximum relative error of a percentile computed from a sketch
+	relativeAccuracy = 0.01
+	// maxSketchBuckets is the maximum number of buckets a sketch holds for each sign
+	maxSketchBuckets = 2048
+	// minIndexableValue is the smallest magnitude that is not counted as zero
+	minIndexableValue = 1e-9
+)
+
+var (
+	sketchGamma    = (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	sketchLogGamma = math.Log(sketchGamma)
+)
+
+// sketch estimates the percentiles of values in bounded memory. Values are counted in buckets whose
+// bounds grow exponentially, so that the value a bucket represents is within the relative accuracy of
+// every value counted in it. If there are more than maxSketchBuckets buckets of a sign, the buckets of
+// the smallest magnitudes are collapsed together, which loses accuracy for those values only.
+type sketch struct {
+	positive map[int]int
+	negative map[int]int
+	zero     int
+	count    int
+}
+
+// newSketch creates an empty sketch
+func newSketch() *sketch {
+	return &sketch{
+		positive: make(map[int]int),
+		negative: make(map[int]int),
+	}
+}
+
+// add counts a value in the sketch
+func (s *sketch) add(value float64) {
+	s.count++
+	switch {
+	case value > minIndexableValue:
+		s.positive[bucketIndex(value)]++
+		collapse(s.positive)
+	case value < -minIndexableValue:
+		s.negative[bucketIndex(-value)]++
+		collapse(s.negative)
+	default:
+		s.zero++
+	}
+}
+
+// percentile estimates the percentile of the values in the sketch, using the nearest rank method
+func (s *sketch) percentile(p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(s.count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	// Negative values are ordered from the largest magnitude to the smallest
+	negative := sortedIndices(s.negative)
+	for i := len(negative) - 1; i >= 0; i-- {
+		rank -= s.negative[negative[i]]
+		if rank <= 0 {
+			return -bucketValue(negative[i])
+		}
+	}
+
+	rank -= s.zero
+	if rank <= 0 {
+		return 0
+	}
+
+	positive := sortedIndices(s.positive)
+	for _, index := range positive {
+		rank -= s.positive[index]
+		if rank <= 0 {
+			return bucketValue(index)
+		}
+	}
+
+	// Only reached if rounding put the rank past the last value
+	if len(positive) > 0 {
+		return bucketValue(positive[len(positive)-1])
+	}
+	return 0
+}
+
+// bucketIndex returns the index of the bucket that counts a positive value
+func bucketIndex(value float64) int {
+	return int(math.Ceil(math.Log(value) / sketchLogGamma))
+}
+
+// bucketValue returns the value that represents the values counted in a bucket, which is
+// within the relative accuracy of each of them
+func bucketValue(index int) float64 {
+	return 2 * math.Pow(sketchGamma, float64(index)) / (sketchGamma + 1)
+}
+
+// collapse merges the buckets of the smallest magnitudes into one, if there are too many buckets
+func collapse(buckets map[int]int) {
+	if len(buckets) <= maxSketchBuckets {
+		return
+	}
+
+	indices := sortedIndices(buckets)
+	excess := len(indices) - maxSketchBuckets
+	target := indices[excess]
+	for _, index := range indices[:excess] {
+		buckets[target] += buckets[index]
+		delete(buckets, index)
+	}
+}
+
+// sortedIndices returns the indices of the buckets in ascending order
+func sortedIndices(buckets map[int]int) []int {
+	indices := make([]int, 0, len(buckets))
+	for index := range buckets {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices
+}
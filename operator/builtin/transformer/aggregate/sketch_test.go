@@ -0,0 +1,35 @@
+package aggregate
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSketchPercentile(t *testing.T) {
+	s := newSketch()
+	for _, value := range []float64{-100, -1, 0, 0.001, 1, 1000, 1e9} {
+		s.add(value)
+	}
+
+	require.Equal(t, 7, s.count)
+	require.InEpsilon(t, -100.0, s.percentile(1), relativeAccuracy)
+	require.InEpsilon(t, -1.0, s.percentile(25), relativeAccuracy)
+	require.Equal(t, 0.0, s.percentile(40))
+	require.InEpsilon(t, 0.001, s.percentile(50), relativeAccuracy)
+	require.InEpsilon(t, 1.0, s.percentile(60), relativeAccuracy)
+	require.InEpsilon(t, 1000.0, s.percentile(80), relativeAccuracy)
+	require.InEpsilon(t, 1e9, s.percentile(100), relativeAccuracy)
+}
+
+func TestSketchCollapse(t *testing.T) {
+	s := newSketch()
+	for i := 0; i < 3*maxSketchBuckets; i++ {
+		s.add(math.Pow(sketchGamma, float64(i)))
+	}
+
+	// The buckets of the smallest values are collapsed, so the largest values stay accurate
+	require.Len(t, s.positive, maxSketchBuckets)
+	require.InEpsilon(t, math.Pow(sketchGamma, float64(3*maxSketchBuckets-1)), s.percentile(100), relativeAccuracy)
+}
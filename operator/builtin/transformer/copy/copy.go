@@ -56,6 +56,17 @@ func (c CopyOperatorConfig) Build(context operator.BuildContext) ([]operator.Ope
 		return nil, fmt.Errorf("copy: missing to field")
 	}
 
+	if _, ok := c.To.FieldInterface.(entry.SelectorField); ok {
+		return nil, fmt.Errorf("copy: to field cannot be a selector")
+	}
+
+	// Each field selected by a selector is copied under the to field, so it must be a record field
+	if _, ok := c.From.FieldInterface.(entry.SelectorField); ok {
+		if _, ok := c.To.FieldInterface.(entry.RecordField); !ok {
+			return nil, fmt.Errorf("copy: to field must be a record field when from is a selector")
+		}
+	}
+
 	copyOp := &CopyOperator{
 		TransformerOperator: transformerOperator,
 		From:                c.From,
@@ -79,6 +90,17 @@ func (p *CopyOperator) Process(ctx context.Context, entry *entry.Entry) error {
 
 // Transform will apply the copy operation to an entry
 func (p *CopyOperator) Transform(e *entry.Entry) error {
+	if selector, ok := p.From.FieldInterface.(entry.SelectorField); ok {
+		to := p.To.FieldInterface.(entry.RecordField)
+		for _, field := range selector.Select(e) {
+			val, _ := field.Get(e)
+			if err := selector.Place(e, to, field, val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	val, exist := p.From.Get(e)
 	if !exist {
 		return fmt.Errorf("copy: from field does not exist in this entry: %s", p.From.String())
@@ -132,7 +132,7 @@ func TestBuildAndProcess(t *testing.T) {
 						"nestedkey": "nestedval",
 					},
 				}
-				e.Labels = map[string]string{"key2": "val"}
+				e.Labels = map[string]interface{}{"key2": "val"}
 				return e
 			},
 		},
@@ -147,7 +147,7 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{"key": "val"}
+				e.Labels = map[string]interface{}{"key": "val"}
 				return e
 			},
 			func() *entry.Entry {
@@ -159,7 +159,7 @@ func TestBuildAndProcess(t *testing.T) {
 					},
 					"key2": "val",
 				}
-				e.Labels = map[string]string{"key": "val"}
+				e.Labels = map[string]interface{}{"key": "val"}
 				return e
 			},
 		},
@@ -174,13 +174,13 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{"key": "val"}
+				e.Labels = map[string]interface{}{"key": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{"key": "val"}
-				e.Resource = map[string]string{"key2": "val"}
+				e.Labels = map[string]interface{}{"key": "val"}
+				e.Resource = map[string]interface{}{"key2": "val"}
 				return e
 			},
 		},
@@ -239,6 +239,69 @@ func TestBuildAndProcess(t *testing.T) {
 			newTestEntry,
 			nil,
 		},
+		{
+			"selector_to_body",
+			false,
+			func() *CopyOperatorConfig {
+				cfg := defaultCfg()
+				cfg.From = entry.NewLabelSelector("k8s_*")
+				cfg.To = entry.NewRecordField("k8s")
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Labels = map[string]interface{}{
+					"k8s_pod": "pod",
+					"app":     "app",
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Labels = map[string]interface{}{
+					"k8s_pod": "pod",
+					"app":     "app",
+				}
+				e.Record.(map[string]interface{})["k8s"] = map[string]interface{}{
+					"k8s_pod": "pod",
+				}
+				return e
+			},
+		},
+		{
+			"selector_in_list",
+			false,
+			func() *CopyOperatorConfig {
+				cfg := defaultCfg()
+				cfg.From = entry.NewRecordSelector("items", "*", "name")
+				cfg.To = entry.NewRecordField("names")
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"name": "first", "id": 1},
+						map[string]interface{}{"name": "second", "id": 2},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"name": "first", "id": 1},
+						map[string]interface{}{"name": "second", "id": 2},
+					},
+					"names": []interface{}{
+						map[string]interface{}{"name": "first"},
+						map[string]interface{}{"name": "second"},
+					},
+				}
+				return e
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -268,3 +331,19 @@ func TestBuildAndProcess(t *testing.T) {
 func defaultCfg() *CopyOperatorConfig {
 	return NewCopyOperatorConfig("copy")
 }
+
+func TestBuildSelector(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.From = entry.NewRecordSelector("*")
+	cfg.To = entry.NewLabelField("key")
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "to field must be a record field")
+
+	cfg = defaultCfg()
+	cfg.From = entry.NewRecordField("key")
+	cfg.To = entry.NewRecordSelector("*")
+	_, err = cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "to field cannot be a selector")
+}
@@ -0,0 +1,344 @@
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"go.uber.org/zap"
+)
+
+func init() {
+	operator.Register("dedup", func() operator.Builder { return NewDedupConfig("") })
+}
+
+// defaultMaxAgeWindows is the number of windows that a window can stay open for, if max_age is not set
+const defaultMaxAgeWindows = 6
+
+// NewDedupConfig creates a new dedup config with default values
+func NewDedupConfig(operatorID string) *DedupConfig {
+	return &DedupConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "dedup"),
+		Window:            helper.NewDuration(10 * time.Second),
+		MaxKeys:           10000,
+		CountField:        entry.NewLabelField("repeat_count"),
+		FirstSeenField:    entry.NewLabelField("first_seen"),
+		LastSeenField:     entry.NewLabelField("last_seen"),
+	}
+}
+
+// DedupConfig is the configuration of a dedup operator
+type DedupConfig struct {
+	helper.TransformerConfig `yaml:",inline"`
+
+	Fields         []entry.Field   `json:"fields,omitempty"           yaml:"fields,omitempty"`
+	KeyExpr        string          `json:"key_expr,omitempty"         yaml:"key_expr,omitempty"`
+	Window         helper.Duration `json:"window,omitempty"           yaml:"window,omitempty"`
+	MaxAge         helper.Duration `json:"max_age,omitempty"          yaml:"max_age,omitempty"`
+	MaxKeys        int             `json:"max_keys,omitempty"         yaml:"max_keys,omitempty"`
+	CountField     entry.Field     `json:"count_field,omitempty"      yaml:"count_field,omitempty"`
+	FirstSeenField entry.Field     `json:"first_seen_field,omitempty" yaml:"first_seen_field,omitempty"`
+	LastSeenField  entry.Field     `json:"last_seen_field,omitempty"  yaml:"last_seen_field,omitempty"`
+}
+
+// Build will build a dedup operator from the supplied configuration
+func (c DedupConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Fields) != 0 && c.KeyExpr != "" {
+		return nil, fmt.Errorf("dedup: only one of 'fields' or 'key_expr' can be defined")
+	}
+
+	var keyExpr *vm.Program
+	if c.KeyExpr != "" {
+		keyExpr, err = expr.Compile(c.KeyExpr, expr.AllowUndefinedVariables())
+		if err != nil {
+			return nil, fmt.Errorf("dedup: failed to compile key_expr '%s': %s", c.KeyExpr, err)
+		}
+	}
+
+	fields := c.Fields
+	if len(fields) == 0 {
+		fields = []entry.Field{entry.NewRecordField()}
+	}
+
+	if c.Window.Raw() <= 0 {
+		return nil, fmt.Errorf("dedup: window must be greater than zero")
+	}
+
+	maxAge := c.MaxAge.Raw()
+	if maxAge == 0 {
+		maxAge = defaultMaxAgeWindows * c.Window.Raw()
+	}
+	if maxAge < c.Window.Raw() {
+		return nil, fmt.Errorf("dedup: max_age must not be less than window")
+	}
+
+	if c.MaxKeys <= 0 {
+		return nil, fmt.Errorf("dedup: max_keys must be greater than zero")
+	}
+
+	dedupOperator := &DedupOperator{
+		TransformerOperator: transformerOperator,
+		fields:              fields,
+		keyExpr:             keyExpr,
+		window:              c.Window.Raw(),
+		maxAge:              maxAge,
+		maxKeys:             c.MaxKeys,
+		countField:          c.CountField,
+		firstSeenField:      c.FirstSeenField,
+		lastSeenField:       c.LastSeenField,
+		keys:                make(map[[sha256.Size]byte]*list.Element),
+		order:               list.New(),
+	}
+
+	return []operator.Operator{dedupOperator}, nil
+}
+
+// DedupOperator is an operator that drops the entries that repeat an entry within a window
+type DedupOperator struct {
+	helper.TransformerOperator
+
+	fields         []entry.Field
+	keyExpr        *vm.Program
+	window         time.Duration
+	maxAge         time.Duration
+	maxKeys        int
+	countField     entry.Field
+	firstSeenField entry.Field
+	lastSeenField  entry.Field
+
+	// keys holds the open windows by key, and order holds them from the most to the least recently used
+	mux   sync.Mutex
+	keys  map[[sha256.Size]byte]*list.Element
+	order *list.List
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// dedupWindow is the window of a key. It holds the first entry with the key, which
+// is retained until the window closes, and counts the entries that repeat it.
+type dedupWindow struct {
+	key       [sha256.Size]byte
+	first     *entry.Entry
+	opened    time.Time
+	received  time.Time
+	repeats   int
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// Start will start closing the windows that have expired
+func (d *DedupOperator) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	interval := d.window
+	if interval > time.Second {
+		interval = time.Second
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.flushExpired(ctx, time.Now())
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop will stop closing windows, then close every open window
+func (d *DedupOperator) Stop() error {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	d.flush(ctx, d.closeWindows(func(*dedupWindow) bool { return true }))
+	return nil
+}
+
+// Process will drop an entry if it repeats an entry within the window of its key
+func (d *DedupOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := d.Skip(ctx, e)
+	if err != nil {
+		return d.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		d.Write(ctx, e)
+		return nil
+	}
+
+	key, err := d.key(e)
+	if err != nil {
+		return d.HandleEntryError(ctx, e, err)
+	}
+
+	evicted := d.add(key, e, time.Now())
+	if evicted != nil {
+		d.Debugw("Closing window early because max_keys was reached", zap.Int("max_keys", d.maxKeys))
+		d.flush(ctx, []*dedupWindow{evicted})
+	}
+	return nil
+}
+
+// key computes the key of an entry, from its key expression or the values of its fields
+func (d *DedupOperator) key(e *entry.Entry) ([sha256.Size]byte, error) {
+	var value interface{}
+	if d.keyExpr != nil {
+		env := helper.GetExprEnv(e)
+		defer helper.PutExprEnv(env)
+
+		result, err := vm.Run(d.keyExpr, env)
+		if err != nil {
+			return [sha256.Size]byte{}, fmt.Errorf("dedup: evaluate key_expr: %s", err)
+		}
+		value = result
+	} else {
+		values := make([]interface{}, 0, len(d.fields))
+		for _, field := range d.fields {
+			fieldValue, _ := e.Get(field)
+			values = append(values, fieldValue)
+		}
+		value = values
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("dedup: encode key: %s", err)
+	}
+	return sha256.Sum256(encoded), nil
+}
+
+// add counts an entry as a repeat, which is dropped, if its key has an open window, which keeps the window open for
+// another window duration, or opens a window for it.
+// If a window is opened while max_keys windows are open, the least recently used window is
+// closed and returned, so that it can be flushed.
+func (d *DedupOperator) add(key [sha256.Size]byte, e *entry.Entry, now time.Time) *dedupWindow {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if element, ok := d.keys[key]; ok {
+		window := element.Value.(*dedupWindow)
+		window.repeats++
+		window.received = now
+		if e.Timestamp.Before(window.firstSeen) {
+			window.firstSeen = e.Timestamp
+		}
+		if e.Timestamp.After(window.lastSeen) {
+			window.lastSeen = e.Timestamp
+		}
+		d.order.MoveToFront(element)
+		d.CountDropped()
+		return nil
+	}
+
+	var evicted *dedupWindow
+	if d.order.Len() >= d.maxKeys {
+		oldest := d.order.Back()
+		evicted = oldest.Value.(*dedupWindow)
+		d.order.Remove(oldest)
+		delete(d.keys, evicted.key)
+	}
+
+	e.Retain()
+	d.keys[key] = d.order.PushFront(&dedupWindow{
+		key:       key,
+		first:     e,
+		opened:    now,
+		received:  now,
+		firstSeen: e.Timestamp,
+		lastSeen:  e.Timestamp,
+	})
+	return evicted
+}
+
+// flushExpired closes the windows that have not received an entry for the window duration,
+// or that have been open for max_age, and flushes them
+func (d *DedupOperator) flushExpired(ctx context.Context, now time.Time) {
+	d.flush(ctx, d.closeWindows(func(window *dedupWindow) bool {
+		return now.Sub(window.received) >= d.window || now.Sub(window.opened) >= d.maxAge
+	}))
+}
+
+// closeWindows removes the windows that match the condition, and returns them ordered by the time they were opened,
+// which keeps the order of their first entries
+func (d *DedupOperator) closeWindows(condition func(*dedupWindow) bool) []*dedupWindow {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	closed := make([]*dedupWindow, 0)
+	for element := d.order.Back(); element != nil; {
+		previous := element.Prev()
+		window := element.Value.(*dedupWindow)
+		if condition(window) {
+			closed = append(closed, window)
+			d.order.Remove(element)
+			delete(d.keys, window.key)
+		}
+		element = previous
+	}
+
+	sort.SliceStable(closed, func(i, j int) bool {
+		return closed[i].opened.Before(closed[j].opened)
+	})
+	return closed
+}
+
+// flush writes the first entry of each window, annotated with the repeats of the window.
+// The windows must already be closed, so that no lock is held while the entries are written.
+func (d *DedupOperator) flush(ctx context.Context, windows []*dedupWindow) {
+	for _, window := range windows {
+		d.annotate(window)
+		d.Write(ctx, window.first)
+		window.first.Release()
+	}
+}
+
+// annotate sets the repeat count and the first and last seen timestamps on the first entry of a window
+func (d *DedupOperator) annotate(window *dedupWindow) {
+	annotations := []struct {
+		field entry.Field
+		value interface{}
+	}{
+		{d.countField, window.repeats},
+		{d.firstSeenField, window.firstSeen.Format(time.RFC3339Nano)},
+		{d.lastSeenField, window.lastSeen.Format(time.RFC3339Nano)},
+	}
+
+	for _, annotation := range annotations {
+		if annotation.field.FieldInterface == nil {
+			continue
+		}
+		if err := window.first.Set(annotation.field, annotation.value); err != nil {
+			d.Errorw("Failed to annotate entry", zap.Error(err), zap.String("field", annotation.field.String()))
+		}
+	}
+}
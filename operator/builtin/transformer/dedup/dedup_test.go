@@ -0,0 +1,421 @@
+package dedup
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testCase struct {
+	name      string
+	expectErr bool
+	op        *DedupConfig
+	input     func() []*entry.Entry
+	output    func() []*entry.Entry
+}
+
+func newTestEntry(record interface{}, timestamp time.Time) *entry.Entry {
+	e := entry.New()
+	e.Timestamp = timestamp
+	e.Record = record
+	return e
+}
+
+// newRepeatedEntry creates the entry that is sent when a window closes
+func newRepeatedEntry(record interface{}, timestamp time.Time, repeats int, lastSeen time.Time) *entry.Entry {
+	e := newTestEntry(record, timestamp)
+	e.Labels = map[string]interface{}{
+		"repeat_count": repeats,
+		"first_seen":   timestamp.Format(time.RFC3339Nano),
+		"last_seen":    lastSeen.Format(time.RFC3339Nano),
+	}
+	return e
+}
+
+// Test building and processing a DedupConfig. The operator is not started, so its
+// windows are only closed when it stops.
+func TestBuildAndProcess(t *testing.T) {
+	ts := time.Date(2020, time.June, 4, 12, 0, 0, 0, time.UTC)
+	later := ts.Add(time.Second)
+
+	cases := []testCase{
+		{
+			"record",
+			false,
+			defaultCfg(),
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"message": "a", "host": "1"}, ts),
+					newTestEntry(map[string]interface{}{"host": "1", "message": "a"}, later),
+					newTestEntry(map[string]interface{}{"message": "a", "host": "2"}, ts),
+					newTestEntry("a", ts),
+					newTestEntry("a", ts),
+				}
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newRepeatedEntry(map[string]interface{}{"message": "a", "host": "1"}, ts, 1, later),
+					newRepeatedEntry(map[string]interface{}{"message": "a", "host": "2"}, ts, 0, ts),
+					newRepeatedEntry("a", ts, 1, ts),
+				}
+			},
+		},
+		{
+			"fields",
+			false,
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []entry.Field{entry.NewRecordField("message"), entry.NewRecordField("level")}
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"message": "a", "level": "error", "id": 1}, ts),
+					newTestEntry(map[string]interface{}{"message": "a", "level": "error", "id": 2}, ts),
+					newTestEntry(map[string]interface{}{"message": "a", "level": "info"}, ts),
+					newTestEntry(map[string]interface{}{"message": "a"}, ts),
+					newTestEntry(map[string]interface{}{"message": "a", "level": nil}, ts),
+				}
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newRepeatedEntry(map[string]interface{}{"message": "a", "level": "error", "id": 1}, ts, 1, ts),
+					newRepeatedEntry(map[string]interface{}{"message": "a", "level": "info"}, ts, 0, ts),
+					newRepeatedEntry(map[string]interface{}{"message": "a"}, ts, 1, ts),
+				}
+			},
+		},
+		{
+			"key_expr",
+			false,
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.KeyExpr = `lower($record.message)`
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"message": "Timeout"}, ts),
+					newTestEntry(map[string]interface{}{"message": "TIMEOUT"}, later),
+					newTestEntry(map[string]interface{}{"message": "refused"}, ts),
+				}
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newRepeatedEntry(map[string]interface{}{"message": "Timeout"}, ts, 1, later),
+					newRepeatedEntry(map[string]interface{}{"message": "refused"}, ts, 0, ts),
+				}
+			},
+		},
+		{
+			"key_expr_error",
+			true,
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.KeyExpr = `upper($record)`
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return []*entry.Entry{newTestEntry(map[string]interface{}{"message": "a"}, ts)}
+			},
+			nil,
+		},
+		{
+			"if",
+			false,
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.IfExpr = `$record.level == "error"`
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"level": "error"}, ts),
+					newTestEntry(map[string]interface{}{"level": "info"}, ts),
+					newTestEntry(map[string]interface{}{"level": "info"}, ts),
+				}
+			},
+			func() []*entry.Entry {
+				return []*entry.Entry{
+					newTestEntry(map[string]interface{}{"level": "info"}, ts),
+					newTestEntry(map[string]interface{}{"level": "info"}, ts),
+					newRepeatedEntry(map[string]interface{}{"level": "error"}, ts, 0, ts),
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.op
+			cfg.OutputIDs = []string{"fake"}
+			cfg.OnError = "drop"
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			dedup := op.(*DedupOperator)
+			fake := testutil.NewFakeOutput(t)
+			dedup.SetOutputs([]operator.Operator{fake})
+			for _, val := range tc.input() {
+				err = dedup.Process(context.Background(), val)
+				if tc.expectErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+
+			require.NoError(t, dedup.Stop())
+			if !tc.expectErr {
+				for _, expected := range tc.output() {
+					fake.ExpectEntry(t, expected)
+				}
+			}
+			fake.ExpectNoEntry(t, 10*time.Millisecond)
+		})
+	}
+}
+
+func defaultCfg() *DedupConfig {
+	return NewDedupConfig("dedup")
+}
+
+func TestBuildInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       *DedupConfig
+		expected string
+	}{
+		{
+			"fields_and_key_expr",
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.Fields = []entry.Field{entry.NewRecordField("message")}
+				cfg.KeyExpr = `$record.message`
+				return cfg
+			}(),
+			"only one of 'fields' or 'key_expr'",
+		},
+		{
+			"invalid_key_expr",
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.KeyExpr = `$record.message ==`
+				return cfg
+			}(),
+			"failed to compile key_expr",
+		},
+		{
+			"zero_window",
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.Window = helper.NewDuration(0)
+				return cfg
+			}(),
+			"window must be greater than zero",
+		},
+		{
+			"max_age_less_than_window",
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.MaxAge = helper.NewDuration(time.Second)
+				return cfg
+			}(),
+			"max_age must not be less than window",
+		},
+		{
+			"negative_max_age",
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.MaxAge = helper.NewDuration(-time.Second)
+				return cfg
+			}(),
+			"max_age must not be less than window",
+		},
+		{
+			"zero_max_keys",
+			func() *DedupConfig {
+				cfg := defaultCfg()
+				cfg.MaxKeys = 0
+				return cfg
+			}(),
+			"max_keys must be greater than zero",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.op.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Fields = []entry.Field{entry.NewRecordField("message")}
+	cfg.Window = helper.NewDuration(100 * time.Millisecond)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	dedup := ops[0].(*DedupOperator)
+	fake := testutil.NewFakeOutput(t)
+	dedup.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, dedup.Start())
+	defer dedup.Stop()
+
+	start := time.Date(2020, time.June, 4, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		record := map[string]interface{}{"message": "connection refused", "attempt": i}
+		require.NoError(t, dedup.Process(context.Background(), newTestEntry(record, start.Add(time.Duration(i)*time.Second))))
+	}
+	require.NoError(t, dedup.Process(context.Background(), newTestEntry(map[string]interface{}{"message": "started"}, start)))
+
+	// Entries are held until their window closes
+	fake.ExpectNoEntry(t, 50*time.Millisecond)
+
+	fake.ExpectEntry(t, newRepeatedEntry(map[string]interface{}{"message": "connection refused", "attempt": 0}, start, 4, start.Add(4*time.Second)))
+	fake.ExpectEntry(t, newRepeatedEntry(map[string]interface{}{"message": "started"}, start, 0, start))
+	fake.ExpectNoEntry(t, 150*time.Millisecond)
+
+	// A new window is opened once the previous window of the key has closed
+	require.NoError(t, dedup.Process(context.Background(), newTestEntry(map[string]interface{}{"message": "connection refused"}, start)))
+	require.Equal(t, 0, fake.ReceiveEntry(t).Labels["repeat_count"])
+}
+
+func TestDedupSlidingWindow(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Window = helper.NewDuration(10 * time.Second)
+	cfg.MaxAge = helper.NewDuration(30 * time.Second)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	dedup := ops[0].(*DedupOperator)
+	fake := testutil.NewFakeOutput(t)
+	dedup.SetOutputs([]operator.Operator{fake})
+
+	start := time.Date(2020, time.June, 4, 12, 0, 0, 0, time.UTC)
+	add := func(message string, offset time.Duration) {
+		e := newTestEntry(map[string]interface{}{"message": message}, start.Add(offset))
+		key, err := dedup.key(e)
+		require.NoError(t, err)
+		require.Nil(t, dedup.add(key, e, start.Add(offset)))
+	}
+	flushAt := func(offset time.Duration) {
+		dedup.flushExpired(context.Background(), start.Add(offset))
+	}
+
+	// Each repeat keeps the window open for another window duration
+	add("a", 0)
+	add("b", 0)
+	add("a", 8*time.Second)
+	flushAt(12 * time.Second)
+	fake.ExpectEntry(t, newRepeatedEntry(map[string]interface{}{"message": "b"}, start, 0, start))
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+
+	add("a", 16*time.Second)
+	add("a", 24*time.Second)
+	flushAt(29 * time.Second)
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+
+	// A window that keeps receiving repeats is closed at max_age
+	add("a", 29*time.Second)
+	flushAt(30 * time.Second)
+	fake.ExpectEntry(t, newRepeatedEntry(map[string]interface{}{"message": "a"}, start, 4, start.Add(29*time.Second)))
+	require.Len(t, dedup.keys, 0)
+}
+
+func TestDedupMaxKeys(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.MaxKeys = 2
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	dedup := ops[0].(*DedupOperator)
+	fake := testutil.NewFakeOutput(t)
+	dedup.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, dedup.Start())
+	defer dedup.Stop()
+
+	process := func(message string) {
+		require.NoError(t, dedup.Process(context.Background(), newTestEntry(map[string]interface{}{"message": message}, time.Now())))
+	}
+
+	process("a")
+	process("b")
+	process("a")
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+
+	// The least recently used window is closed early to make room for a new key
+	process("c")
+	require.Equal(t, map[string]interface{}{"message": "b"}, fake.ReceiveEntry(t).Record)
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+	require.Len(t, dedup.keys, 2)
+}
+
+func TestDedupRetainsFirstEntry(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	dedup := ops[0].(*DedupOperator)
+	fake := testutil.NewFakeOutput(t)
+	dedup.SetOutputs([]operator.Operator{fake})
+
+	var acked int32
+	newAckedEntry := func() *entry.Entry {
+		e := newTestEntry("repeated", time.Now())
+		e.SetAck(entry.NewAck(func() { atomic.AddInt32(&acked, 1) }))
+		return e
+	}
+
+	first, repeat := newAckedEntry(), newAckedEntry()
+	require.NoError(t, dedup.Process(context.Background(), first))
+	require.NoError(t, dedup.Process(context.Background(), repeat))
+
+	// The inputs release their references once Process returns
+	first.Release()
+	repeat.Release()
+	require.Equal(t, int32(1), atomic.LoadInt32(&acked))
+
+	require.NoError(t, dedup.Stop())
+	fake.ReceiveEntry(t)
+	require.Equal(t, int32(2), atomic.LoadInt32(&acked))
+}
+
+func TestDedupDefaultMaxAge(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.Window = helper.NewDuration(5 * time.Minute)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Minute, ops[0].(*DedupOperator).maxAge)
+}
+
+func TestDedupDropMetrics(t *testing.T) {
+	cfg := NewDedupConfig("dedup_metrics")
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	dedup := ops[0].(*DedupOperator)
+	fake := testutil.NewFakeOutput(t)
+	dedup.SetOutputs([]operator.Operator{fake})
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, dedup.Process(context.Background(), newTestEntry(map[string]interface{}{"message": "a"}, time.Now())))
+	}
+	require.NoError(t, dedup.Stop())
+	require.Len(t, fake.ReceiveAll(), 1)
+	require.Equal(t, float64(2), promtestutil.ToFloat64(metrics.Dropped.WithLabelValues("$.dedup_metrics")))
+	require.Equal(t, float64(0), promtestutil.ToFloat64(metrics.Errors.WithLabelValues("$.dedup_metrics")))
+}
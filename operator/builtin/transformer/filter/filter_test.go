@@ -50,13 +50,40 @@ func TestFilterOperator(t *testing.T) {
 				Record: map[string]interface{}{
 					"message": "test_message",
 				},
-				Labels: map[string]string{
+				Labels: map[string]interface{}{
 					"key": "value",
 				},
 			},
 			`$labels.key == "value"`,
 			true,
 		},
+		{
+			"MatchTypedLabel",
+			&entry.Entry{
+				Record: map[string]interface{}{
+					"message": "test_message",
+				},
+				Labels: map[string]interface{}{
+					"status": int64(500),
+					"retry":  true,
+				},
+			},
+			`$labels.status >= 500 and $labels.retry`,
+			true,
+		},
+		{
+			"MatchTypedResource",
+			&entry.Entry{
+				Record: map[string]interface{}{
+					"message": "test_message",
+				},
+				Resource: map[string]interface{}{
+					"zones": []interface{}{"a", "b"},
+				},
+			},
+			`"b" in $resource.zones`,
+			true,
+		},
 		{
 			"NoMatchLabel",
 			&entry.Entry{
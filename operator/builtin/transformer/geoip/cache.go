@@ -0,0 +1,74 @@
+package geoip
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cache is a least recently used cache of locations by IP address. It is safe for concurrent use.
+type cache struct {
+	mux      sync.Mutex
+	size     int
+	elements map[string]*list.Element
+	order    *list.List
+}
+
+// cacheItem is an item of a cache, stored in the order list
+type cacheItem struct {
+	ip       string
+	location *location
+}
+
+// newCache creates a cache that holds at most size items. A cache with a size of 0 holds nothing.
+func newCache(size int) *cache {
+	return &cache{
+		size:     size,
+		elements: make(map[string]*list.Element, size),
+		order:    list.New(),
+	}
+}
+
+// get returns the cached location of an IP address, and whether it was cached.
+// A cached location may be nil if the IP address is not in the database.
+func (c *cache) get(ip string) (*location, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	element, ok := c.elements[ip]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheItem).location, true
+}
+
+// add caches the location of an IP address, evicting the least recently used item if the cache is full
+func (c *cache) add(ip string, loc *location) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if element, ok := c.elements[ip]; ok {
+		element.Value.(*cacheItem).location = loc
+		c.order.MoveToFront(element)
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.elements, oldest.Value.(*cacheItem).ip)
+	}
+
+	c.elements[ip] = c.order.PushFront(&cacheItem{ip: ip, location: loc})
+}
+
+// len returns the number of cached items
+func (c *cache) len() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,55 @@
+package geoip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	c := newCache(2)
+	us := &location{CountryISOCode: "US"}
+	gb := &location{CountryISOCode: "GB"}
+
+	_, ok := c.get("8.8.8.8")
+	require.False(t, ok)
+
+	c.add("8.8.8.8", us)
+	c.add("81.2.69.1", gb)
+	c.add("10.0.0.1", nil)
+	require.Equal(t, 2, c.len())
+
+	// The least recently used item is evicted
+	_, ok = c.get("8.8.8.8")
+	require.False(t, ok)
+
+	loc, ok := c.get("10.0.0.1")
+	require.True(t, ok)
+	require.Nil(t, loc)
+
+	// Getting an item makes it the most recently used
+	loc, ok = c.get("81.2.69.1")
+	require.True(t, ok)
+	require.Equal(t, gb, loc)
+
+	c.add("8.8.8.8", us)
+	_, ok = c.get("10.0.0.1")
+	require.False(t, ok)
+	_, ok = c.get("81.2.69.1")
+	require.True(t, ok)
+
+	// Adding an existing item replaces it
+	c.add("8.8.8.8", gb)
+	loc, ok = c.get("8.8.8.8")
+	require.True(t, ok)
+	require.Equal(t, gb, loc)
+	require.Equal(t, 2, c.len())
+}
+
+func TestCacheDisabled(t *testing.T) {
+	c := newCache(0)
+	c.add("8.8.8.8", &location{})
+	_, ok := c.get("8.8.8.8")
+	require.False(t, ok)
+	require.Equal(t, 0, c.len())
+}
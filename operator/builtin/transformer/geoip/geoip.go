@@ -0,0 +1,257 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/oschwald/maxminddb-golang"
+	"go.uber.org/zap"
+)
+
+func init() {
+	operator.Register("geoip", func() operator.Builder { return NewGeoIPConfig("") })
+}
+
+// NewGeoIPConfig creates a new geoip config with default values
+func NewGeoIPConfig(operatorID string) *GeoIPConfig {
+	return &GeoIPConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "geoip"),
+		To:                entry.NewRecordField("geoip"),
+		Language:          "en",
+		CacheSize:         1000,
+		PollInterval:      helper.NewDuration(10 * time.Second),
+	}
+}
+
+// GeoIPConfig is the configuration of a geoip operator
+type GeoIPConfig struct {
+	helper.TransformerConfig `yaml:",inline"`
+
+	Database     string          `json:"database"                yaml:"database"`
+	Field        entry.Field     `json:"field"                   yaml:"field"`
+	To           entry.Field     `json:"to,omitempty"            yaml:"to,omitempty"`
+	Language     string          `json:"language,omitempty"      yaml:"language,omitempty"`
+	CacheSize    int             `json:"cache_size,omitempty"    yaml:"cache_size,omitempty"`
+	PollInterval helper.Duration `json:"poll_interval,omitempty" yaml:"poll_interval,omitempty"`
+}
+
+// Build will build a geoip operator from the supplied configuration
+func (c GeoIPConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Database == "" {
+		return nil, fmt.Errorf("geoip: missing required field 'database'")
+	}
+
+	if c.Field.FieldInterface == nil {
+		return nil, fmt.Errorf("geoip: missing required field 'field'")
+	}
+
+	to, ok := c.To.FieldInterface.(entry.RecordField)
+	if !ok {
+		return nil, fmt.Errorf("geoip: 'to' must be a record field, but got %s", c.To)
+	}
+
+	if c.CacheSize < 0 {
+		return nil, fmt.Errorf("geoip: cache_size must not be negative")
+	}
+
+	if c.PollInterval.Raw() < 0 {
+		return nil, fmt.Errorf("geoip: poll_interval must not be negative")
+	}
+
+	geoIPOperator := &GeoIPOperator{
+		TransformerOperator: transformerOperator,
+		path:                c.Database,
+		field:               c.Field,
+		to:                  to,
+		language:            c.Language,
+		cacheSize:           c.CacheSize,
+		pollInterval:        c.PollInterval.Raw(),
+	}
+
+	return []operator.Operator{geoIPOperator}, nil
+}
+
+// GeoIPOperator is an operator that adds the location of an IP address to entries
+type GeoIPOperator struct {
+	helper.TransformerOperator
+
+	path         string
+	field        entry.Field
+	to           entry.RecordField
+	language     string
+	cacheSize    int
+	pollInterval time.Duration
+
+	// db holds the current database, which is swapped as a whole with a new cache when the file changes
+	db      atomic.Value
+	modTime time.Time
+	size    int64
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// database is a loaded database, with the cache of its lookups
+type database struct {
+	reader *maxminddb.Reader
+	cache  *cache
+}
+
+// Start will load the database, and start watching its file for changes
+func (g *GeoIPOperator) Start() error {
+	if _, err := g.reload(); err != nil {
+		return errors.Wrap(err, "load geoip database")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+
+	if g.pollInterval == 0 {
+		return nil
+	}
+
+	g.wg.Add(1)
+	go g.poll(ctx)
+	return nil
+}
+
+// Stop will stop watching the file of the database
+func (g *GeoIPOperator) Stop() error {
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.wg.Wait()
+	return nil
+}
+
+// poll reloads the database whenever its file changes. If the new database
+// cannot be loaded, the error is logged and the previous database is kept.
+func (g *GeoIPOperator) poll(ctx context.Context) {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(g.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := g.reload()
+			if err != nil {
+				g.Errorw("Failed to reload geoip database", zap.Error(err), zap.String("path", g.path))
+				continue
+			}
+			if reloaded {
+				g.Debugw("Reloaded geoip database", zap.String("path", g.path))
+			}
+		}
+	}
+}
+
+// reload loads the database if its file changed since it was last loaded. The whole file is
+// read into memory, so that a replaced database is never read while lookups are in progress.
+func (g *GeoIPOperator) reload() (bool, error) {
+	info, err := os.Stat(g.path)
+	if err != nil {
+		return false, err
+	}
+
+	if g.db.Load() != nil && info.ModTime().Equal(g.modTime) && info.Size() == g.size {
+		return false, nil
+	}
+
+	contents, err := os.ReadFile(g.path)
+	if err != nil {
+		return false, err
+	}
+
+	reader, err := maxminddb.FromBytes(contents)
+	if err != nil {
+		return false, err
+	}
+
+	g.db.Store(&database{reader: reader, cache: newCache(g.cacheSize)})
+	g.modTime, g.size = info.ModTime(), info.Size()
+	return true, nil
+}
+
+// Process will process an entry with a geoip transformation
+func (g *GeoIPOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return g.ProcessWith(ctx, entry, g.Transform)
+}
+
+// Transform will look up the IP address of an entry, and add its location to the entry.
+// Entries without the IP field, or with an IP address that is not in the database, are not changed.
+func (g *GeoIPOperator) Transform(e *entry.Entry) error {
+	value, ok := e.Get(g.field)
+	if !ok || value == nil {
+		return nil
+	}
+
+	var str string
+	switch typed := value.(type) {
+	case string:
+		str = typed
+	case []byte:
+		str = string(typed)
+	default:
+		return fmt.Errorf("geoip: type '%T' cannot be parsed as an IP address", value)
+	}
+
+	ip := net.ParseIP(str)
+	if ip == nil {
+		return fmt.Errorf("geoip: '%s' is not an IP address", str)
+	}
+
+	loc, err := g.lookup(ip)
+	if err != nil {
+		return err
+	}
+	if loc == nil {
+		return nil
+	}
+
+	for key, value := range loc.fields() {
+		if err := e.Set(g.to.Child(key), value); err != nil {
+			return errors.Wrap(err, "geoip: set "+key)
+		}
+	}
+	return nil
+}
+
+// lookup returns the location of an IP address from the cache, or from the database
+func (g *GeoIPOperator) lookup(ip net.IP) (*location, error) {
+	db := g.db.Load().(*database)
+
+	key := ip.String()
+	if loc, ok := db.cache.get(key); ok {
+		return loc, nil
+	}
+
+	var record mmdbRecord
+	_, found, err := db.reader.LookupNetwork(ip, &record)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: lookup %s: %s", key, err)
+	}
+
+	var loc *location
+	if found {
+		loc = record.location(g.language)
+	}
+	db.cache.add(key, loc)
+	return loc, nil
+}
@@ -0,0 +1,341 @@
+package geoip
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// The test databases were generated with github.com/maxmind/mmdbwriter. They hold 8.8.8.0/24
+// with a city, location and ASN, 81.2.69.0/24 with a country and location, and 2001:db8::/32 with
+// an ASN. The city of 8.8.8.0/24 is Mountain View in test.mmdb, and San Jose in test-reload.mmdb.
+const (
+	testDatabase       = "testdata/test.mmdb"
+	testReloadDatabase = "testdata/test-reload.mmdb"
+)
+
+type testCase struct {
+	name      string
+	expectErr bool
+	op        *GeoIPConfig
+	input     func() *entry.Entry
+	output    func() *entry.Entry
+}
+
+// Test building and processing a GeoIPConfig
+func TestBuildAndProcess(t *testing.T) {
+	newTestEntry := func(record map[string]interface{}) func() *entry.Entry {
+		return func() *entry.Entry {
+			e := entry.New()
+			e.Timestamp = time.Unix(1586632809, 0)
+			e.Record = record
+			return e
+		}
+	}
+
+	cases := []testCase{
+		{
+			"city_and_asn",
+			false,
+			defaultCfg(),
+			newTestEntry(map[string]interface{}{"ip": "8.8.8.8"}),
+			newTestEntry(map[string]interface{}{
+				"ip": "8.8.8.8",
+				"geoip": map[string]interface{}{
+					"country_iso_code": "US",
+					"country_name":     "United States",
+					"continent_code":   "NA",
+					"region_iso_code":  "CA",
+					"region_name":      "California",
+					"city_name":        "Mountain View",
+					"postal_code":      "94035",
+					"timezone":         "America/Chicago",
+					"location":         map[string]interface{}{"lat": 37.751, "lon": -97.822},
+					"asn":              uint(15169),
+					"as_organization":  "GOOGLE",
+				},
+			}),
+		},
+		{
+			"language",
+			false,
+			func() *GeoIPConfig {
+				cfg := defaultCfg()
+				cfg.Language = "de"
+				cfg.To = entry.NewRecordField("client", "geo")
+				return cfg
+			}(),
+			newTestEntry(map[string]interface{}{"ip": "81.2.69.142", "client": map[string]interface{}{"port": 443}}),
+			newTestEntry(map[string]interface{}{
+				"ip": "81.2.69.142",
+				"client": map[string]interface{}{
+					"port": 443,
+					"geo": map[string]interface{}{
+						"country_iso_code": "GB",
+						"country_name":     "Vereinigtes Königreich",
+						"location":         map[string]interface{}{"lat": 51.5142, "lon": -0.0931},
+					},
+				},
+			}),
+		},
+		{
+			"ipv6",
+			false,
+			defaultCfg(),
+			newTestEntry(map[string]interface{}{"ip": []byte("2001:db8::1")}),
+			newTestEntry(map[string]interface{}{
+				"ip": []byte("2001:db8::1"),
+				"geoip": map[string]interface{}{
+					"asn":             uint(64496),
+					"as_organization": "Example Network",
+				},
+			}),
+		},
+		{
+			"merge_existing",
+			false,
+			defaultCfg(),
+			newTestEntry(map[string]interface{}{"ip": "2001:db8::1", "geoip": map[string]interface{}{"country_iso_code": "US"}}),
+			newTestEntry(map[string]interface{}{
+				"ip": "2001:db8::1",
+				"geoip": map[string]interface{}{
+					"country_iso_code": "US",
+					"asn":              uint(64496),
+					"as_organization":  "Example Network",
+				},
+			}),
+		},
+		{
+			"not_in_database",
+			false,
+			defaultCfg(),
+			newTestEntry(map[string]interface{}{"ip": "10.0.0.1"}),
+			newTestEntry(map[string]interface{}{"ip": "10.0.0.1"}),
+		},
+		{
+			"missing_field",
+			false,
+			defaultCfg(),
+			newTestEntry(map[string]interface{}{"message": "test"}),
+			newTestEntry(map[string]interface{}{"message": "test"}),
+		},
+		{
+			"not_an_ip",
+			true,
+			defaultCfg(),
+			newTestEntry(map[string]interface{}{"ip": "localhost"}),
+			nil,
+		},
+		{
+			"not_a_string",
+			true,
+			defaultCfg(),
+			newTestEntry(map[string]interface{}{"ip": 8}),
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.op
+			cfg.OutputIDs = []string{"fake"}
+			cfg.OnError = "drop"
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			geoip := op.(*GeoIPOperator)
+			fake := testutil.NewFakeOutput(t)
+			geoip.SetOutputs([]operator.Operator{fake})
+			require.NoError(t, geoip.Start())
+			defer geoip.Stop()
+
+			val := tc.input()
+			err = geoip.Process(context.Background(), val)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				fake.ExpectEntry(t, tc.output())
+			}
+		})
+	}
+}
+
+func defaultCfg() *GeoIPConfig {
+	cfg := NewGeoIPConfig("geoip")
+	cfg.Database = testDatabase
+	cfg.Field = entry.NewRecordField("ip")
+	cfg.PollInterval = helper.NewDuration(0)
+	return cfg
+}
+
+func TestBuildInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       *GeoIPConfig
+		expected string
+	}{
+		{
+			"missing_database",
+			func() *GeoIPConfig {
+				cfg := defaultCfg()
+				cfg.Database = ""
+				return cfg
+			}(),
+			"missing required field 'database'",
+		},
+		{
+			"missing_field",
+			func() *GeoIPConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.Field{}
+				return cfg
+			}(),
+			"missing required field 'field'",
+		},
+		{
+			"to_label",
+			func() *GeoIPConfig {
+				cfg := defaultCfg()
+				cfg.To = entry.NewLabelField("geo")
+				return cfg
+			}(),
+			"'to' must be a record field",
+		},
+		{
+			"negative_cache_size",
+			func() *GeoIPConfig {
+				cfg := defaultCfg()
+				cfg.CacheSize = -1
+				return cfg
+			}(),
+			"cache_size must not be negative",
+		},
+		{
+			"negative_poll_interval",
+			func() *GeoIPConfig {
+				cfg := defaultCfg()
+				cfg.PollInterval = helper.NewDuration(-time.Second)
+				return cfg
+			}(),
+			"poll_interval must not be negative",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.op.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestGeoIPCache(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.CacheSize = 2
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	geoip := ops[0].(*GeoIPOperator)
+	fake := testutil.NewFakeOutput(t)
+	geoip.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, geoip.Start())
+	defer geoip.Stop()
+
+	for _, ip := range []string{"8.8.8.8", "8.8.8.8", "8.8.4.4", "10.0.0.1"} {
+		e := entry.New()
+		e.Record = map[string]interface{}{"ip": ip}
+		require.NoError(t, geoip.Process(context.Background(), e))
+		fake.ReceiveEntry(t)
+	}
+
+	db := geoip.db.Load().(*database)
+	require.Equal(t, 2, db.cache.len())
+	_, ok := db.cache.get("8.8.8.8")
+	require.False(t, ok)
+	loc, ok := db.cache.get("10.0.0.1")
+	require.True(t, ok)
+	require.Nil(t, loc)
+}
+
+func TestGeoIPStartMissingDatabase(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Database = filepath.Join(t.TempDir(), "missing.mmdb")
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	geoip := ops[0].(*GeoIPOperator)
+
+	require.Error(t, geoip.Start())
+	require.NoError(t, geoip.Stop())
+}
+
+func TestGeoIPStartInvalidDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(path, []byte("not a database"), 0600))
+
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Database = path
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	geoip := ops[0].(*GeoIPOperator)
+
+	require.Error(t, geoip.Start())
+	require.NoError(t, geoip.Stop())
+}
+
+func TestGeoIPReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "geoip.mmdb")
+	replace := func(source string) {
+		contents, err := os.ReadFile(source)
+		require.NoError(t, err)
+		tmp := filepath.Join(dir, "geoip.mmdb.tmp")
+		require.NoError(t, os.WriteFile(tmp, contents, 0600))
+		require.NoError(t, os.Rename(tmp, path))
+	}
+	replace(testDatabase)
+
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Database = path
+	cfg.PollInterval = helper.NewDuration(10 * time.Millisecond)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	geoip := ops[0].(*GeoIPOperator)
+	fake := testutil.NewFakeOutput(t)
+	geoip.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, geoip.Start())
+	defer geoip.Stop()
+
+	cityOf := func(ip string) interface{} {
+		e := entry.New()
+		e.Record = map[string]interface{}{"ip": ip}
+		require.NoError(t, geoip.Process(context.Background(), e))
+		city, _ := fake.ReceiveEntry(t).Get(entry.NewRecordField("geoip", "city_name"))
+		return city
+	}
+
+	require.Equal(t, "Mountain View", cityOf("8.8.8.8"))
+
+	replace(testReloadDatabase)
+	require.Eventually(t, func() bool {
+		return cityOf("8.8.8.8") == "San Jose"
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// An invalid database is logged, and the previous database is kept
+	require.NoError(t, os.WriteFile(path, []byte("not a database"), 0600))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, "San Jose", cityOf("8.8.8.8"))
+}
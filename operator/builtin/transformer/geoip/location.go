@@ -0,0 +1,112 @@
+package geoip
+
+// defaultLanguage is the language of names that are not available in the configured language
+const defaultLanguage = "en"
+
+// mmdbRecord is a record of a MaxMind database. It holds the fields of the City and ASN databases,
+// so that a database of either type, or a database that combines both, can be read.
+type mmdbRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Continent struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+		TimeZone  string   `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+	Postal struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"postal"`
+	ASN            uint   `maxminddb:"autonomous_system_number"`
+	ASOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// location is the location of an IP address, as it is added to entries
+type location struct {
+	CountryISOCode string
+	CountryName    string
+	ContinentCode  string
+	RegionISOCode  string
+	RegionName     string
+	CityName       string
+	PostalCode     string
+	TimeZone       string
+	Latitude       *float64
+	Longitude      *float64
+	ASN            uint
+	ASOrganization string
+}
+
+// location returns the location of a record, with names in the language if they are available
+func (r *mmdbRecord) location(language string) *location {
+	loc := &location{
+		CountryISOCode: r.Country.ISOCode,
+		CountryName:    name(r.Country.Names, language),
+		ContinentCode:  r.Continent.Code,
+		CityName:       name(r.City.Names, language),
+		PostalCode:     r.Postal.Code,
+		TimeZone:       r.Location.TimeZone,
+		Latitude:       r.Location.Latitude,
+		Longitude:      r.Location.Longitude,
+		ASN:            r.ASN,
+		ASOrganization: r.ASOrganization,
+	}
+
+	if len(r.Subdivisions) > 0 {
+		loc.RegionISOCode = r.Subdivisions[0].ISOCode
+		loc.RegionName = name(r.Subdivisions[0].Names, language)
+	}
+	return loc
+}
+
+// name returns the name in the language, or in the default language if it is not available
+func name(names map[string]string, language string) string {
+	if n, ok := names[language]; ok {
+		return n
+	}
+	return names[defaultLanguage]
+}
+
+// fields returns the fields of the location that are known. A new map
+// is returned on every call, so that entries never share their values.
+func (l *location) fields() map[string]interface{} {
+	fields := make(map[string]interface{})
+	setString := func(key, value string) {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+
+	setString("country_iso_code", l.CountryISOCode)
+	setString("country_name", l.CountryName)
+	setString("continent_code", l.ContinentCode)
+	setString("region_iso_code", l.RegionISOCode)
+	setString("region_name", l.RegionName)
+	setString("city_name", l.CityName)
+	setString("postal_code", l.PostalCode)
+	setString("timezone", l.TimeZone)
+	setString("as_organization", l.ASOrganization)
+
+	if l.Latitude != nil && l.Longitude != nil {
+		fields["location"] = map[string]interface{}{
+			"lat": *l.Latitude,
+			"lon": *l.Longitude,
+		}
+	}
+
+	if l.ASN != 0 {
+		fields["asn"] = l.ASN
+	}
+	return fields
+}
@@ -0,0 +1,52 @@
+package geoip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLocation(t *testing.T) {
+	lat, lon := 48.1374, 11.5755
+
+	var record mmdbRecord
+	record.City.Names = map[string]string{"en": "Munich", "de": "München"}
+	record.Country.ISOCode = "DE"
+	record.Country.Names = map[string]string{"en": "Germany", "de": "Deutschland"}
+	record.Continent.Code = "EU"
+	record.Subdivisions = append(record.Subdivisions, struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	}{"BY", map[string]string{"en": "Bavaria"}})
+	record.Location.Latitude = &lat
+	record.Location.Longitude = &lon
+	record.Location.TimeZone = "Europe/Berlin"
+	record.Postal.Code = "80331"
+	record.ASN = 3320
+	record.ASOrganization = "Deutsche Telekom AG"
+
+	require.Equal(t, map[string]interface{}{
+		"country_iso_code": "DE",
+		"country_name":     "Deutschland",
+		"continent_code":   "EU",
+		"region_iso_code":  "BY",
+		"region_name":      "Bavaria",
+		"city_name":        "München",
+		"postal_code":      "80331",
+		"timezone":         "Europe/Berlin",
+		"location":         map[string]interface{}{"lat": lat, "lon": lon},
+		"asn":              uint(3320),
+		"as_organization":  "Deutsche Telekom AG",
+	}, record.location("de").fields())
+}
+
+func TestLocationFieldsPartial(t *testing.T) {
+	lat := 1.5
+	loc := &location{CountryISOCode: "GB", Latitude: &lat}
+	require.Equal(t, map[string]interface{}{"country_iso_code": "GB"}, loc.fields())
+
+	// Every call returns a new map
+	fields := loc.fields()
+	fields["country_iso_code"] = "US"
+	require.Equal(t, "GB", loc.fields()["country_iso_code"])
+}
@@ -304,7 +304,7 @@ func (k *K8sMetadataDecorator) refreshPodMetadata(ctx context.Context, namespace
 
 func (k *K8sMetadataDecorator) decorateEntryWithNamespaceMetadata(nsMeta MetadataCacheEntry, entry *entry.Entry) {
 	if entry.Labels == nil {
-		entry.Labels = make(map[string]string)
+		entry.Labels = make(map[string]interface{})
 	}
 
 	for k, v := range nsMeta.Annotations {
@@ -320,7 +320,7 @@ func (k *K8sMetadataDecorator) decorateEntryWithNamespaceMetadata(nsMeta Metadat
 
 func (k *K8sMetadataDecorator) decorateEntryWithPodMetadata(podMeta MetadataCacheEntry, entry *entry.Entry) {
 	if entry.Labels == nil {
-		entry.Labels = make(map[string]string)
+		entry.Labels = make(map[string]interface{})
 	}
 
 	for k, v := range podMeta.Annotations {
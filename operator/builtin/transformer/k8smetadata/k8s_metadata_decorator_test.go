@@ -101,13 +101,13 @@ func TestK8sMetadataDecoratorCachedMetadata(t *testing.T) {
 	})
 
 	expected := entry.Entry{
-		Labels: map[string]string{
+		Labels: map[string]interface{}{
 			"k8s-pod/podlabel1":                 "podlab1",
 			"k8s-ns/label1":                     "lab1",
 			"k8s-pod-annotation/podannotation1": "podann1",
 			"k8s-ns-annotation/annotation1":     "ann1",
 		},
-		Resource: map[string]string{
+		Resource: map[string]interface{}{
 			"k8s.pod.name":       "testpodname",
 			"k8s.namespace.name": "testnamespace",
 			"k8s.service.name":   "testservice",
@@ -122,7 +122,7 @@ func TestK8sMetadataDecoratorCachedMetadata(t *testing.T) {
 	}).Return(nil)
 
 	e := &entry.Entry{
-		Resource: map[string]string{
+		Resource: map[string]interface{}{
 			"k8s.pod.name":       "testpodname",
 			"k8s.namespace.name": "testnamespace",
 		},
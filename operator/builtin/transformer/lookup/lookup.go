@@ -0,0 +1,255 @@
+package lookup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"go.uber.org/zap"
+)
+
+const (
+	// ignoreOnMiss sends entries without a match unchanged
+	ignoreOnMiss = "ignore"
+	// errorOnMiss handles entries without a match as errors, using on_error
+	errorOnMiss = "error"
+	// defaultOnMiss writes the configured defaults to entries without a match
+	defaultOnMiss = "default"
+)
+
+func init() {
+	operator.Register("lookup", func() operator.Builder { return NewLookupConfig("") })
+}
+
+// NewLookupConfig creates a new lookup config with default values
+func NewLookupConfig(operatorID string) *LookupConfig {
+	return &LookupConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "lookup"),
+		OnMiss:            ignoreOnMiss,
+		PollInterval:      helper.NewDuration(10 * time.Second),
+	}
+}
+
+// LookupConfig is the configuration of a lookup operator
+type LookupConfig struct {
+	helper.TransformerConfig `yaml:",inline"`
+
+	Path         string                 `json:"path"                    yaml:"path"`
+	Format       string                 `json:"format,omitempty"        yaml:"format,omitempty"`
+	Field        entry.Field            `json:"field"                   yaml:"field"`
+	KeyColumn    string                 `json:"key_column,omitempty"    yaml:"key_column,omitempty"`
+	Columns      map[string]entry.Field `json:"columns,omitempty"       yaml:"columns,omitempty"`
+	OnMiss       string                 `json:"on_miss,omitempty"       yaml:"on_miss,omitempty"`
+	Defaults     map[string]interface{} `json:"defaults,omitempty"      yaml:"defaults,omitempty"`
+	PollInterval helper.Duration        `json:"poll_interval,omitempty" yaml:"poll_interval,omitempty"`
+}
+
+// Build will build a lookup operator from the supplied configuration
+func (c LookupConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Path == "" {
+		return nil, fmt.Errorf("lookup: missing required field 'path'")
+	}
+
+	if c.Field.FieldInterface == nil {
+		return nil, fmt.Errorf("lookup: missing required field 'field'")
+	}
+
+	format, err := detectFormat(c.Path, c.Format)
+	if err != nil {
+		return nil, fmt.Errorf("lookup: %s", err)
+	}
+
+	switch c.OnMiss {
+	case ignoreOnMiss, errorOnMiss:
+	case defaultOnMiss:
+		if len(c.Defaults) == 0 {
+			return nil, fmt.Errorf("lookup: on_miss 'default' requires 'defaults'")
+		}
+	default:
+		return nil, fmt.Errorf("lookup: invalid on_miss '%s', expected 'ignore', 'error' or 'default'", c.OnMiss)
+	}
+
+	if c.PollInterval.Raw() < 0 {
+		return nil, fmt.Errorf("lookup: poll_interval must not be negative")
+	}
+
+	lookupOperator := &LookupOperator{
+		TransformerOperator: transformerOperator,
+		path:                c.Path,
+		format:              format,
+		field:               c.Field,
+		keyColumn:           c.KeyColumn,
+		columns:             c.Columns,
+		onMiss:              c.OnMiss,
+		defaults:            c.Defaults,
+		pollInterval:        c.PollInterval.Raw(),
+	}
+
+	return []operator.Operator{lookupOperator}, nil
+}
+
+// LookupOperator is an operator that enriches entries with the columns of a lookup table
+type LookupOperator struct {
+	helper.TransformerOperator
+
+	path         string
+	format       string
+	field        entry.Field
+	keyColumn    string
+	columns      map[string]entry.Field
+	onMiss       string
+	defaults     map[string]interface{}
+	pollInterval time.Duration
+
+	// table holds the current table, which is swapped as a whole when the file changes
+	table   atomic.Value
+	modTime time.Time
+	size    int64
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// Start will load the lookup table, and start watching its file for changes
+func (l *LookupOperator) Start() error {
+	if _, err := l.reload(); err != nil {
+		return errors.Wrap(err, "load lookup table")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+
+	if l.pollInterval == 0 {
+		return nil
+	}
+
+	l.wg.Add(1)
+	go l.poll(ctx)
+	return nil
+}
+
+// Stop will stop watching the file of the lookup table
+func (l *LookupOperator) Stop() error {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.wg.Wait()
+	return nil
+}
+
+// poll reloads the lookup table whenever its file changes. If the new table
+// cannot be loaded, the error is logged and the previous table is kept.
+func (l *LookupOperator) poll(ctx context.Context) {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(l.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := l.reload()
+			if err != nil {
+				l.Errorw("Failed to reload lookup table", zap.Error(err), zap.String("path", l.path))
+				continue
+			}
+			if reloaded {
+				l.Debugw("Reloaded lookup table", zap.String("path", l.path))
+			}
+		}
+	}
+}
+
+// reload loads the lookup table if its file changed since it was last loaded
+func (l *LookupOperator) reload() (bool, error) {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false, err
+	}
+
+	if l.table.Load() != nil && info.ModTime().Equal(l.modTime) && info.Size() == l.size {
+		return false, nil
+	}
+
+	t, err := loadTable(l.path, l.format, l.keyColumn)
+	if err != nil {
+		return false, err
+	}
+
+	l.table.Store(t)
+	l.modTime, l.size = info.ModTime(), info.Size()
+	return true, nil
+}
+
+// Process will process an entry with a lookup transformation
+func (l *LookupOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return l.ProcessWith(ctx, entry, l.Transform)
+}
+
+// Transform will look up the key of an entry, and write the columns of the matching row to the entry
+func (l *LookupOperator) Transform(e *entry.Entry) error {
+	var r row
+	if value, ok := e.Get(l.field); ok && value != nil {
+		t, _ := l.table.Load().(table)
+		r = t[entry.AttributeString(value)]
+	}
+
+	if r == nil {
+		switch l.onMiss {
+		case errorOnMiss:
+			return fmt.Errorf("lookup: no row found for the key in %s", l.field)
+		case defaultOnMiss:
+			r = l.defaults
+		default:
+			return nil
+		}
+	}
+
+	return l.write(e, r)
+}
+
+// write writes the columns of a row to the entry. Without configured columns, every column
+// except the key column is written to the record, under the name of the column.
+func (l *LookupOperator) write(e *entry.Entry, r row) error {
+	if len(l.columns) == 0 {
+		names := make([]string, 0, len(r))
+		for name := range r {
+			if name != l.keyColumn {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if err := e.Set(entry.NewRecordField(name), copyJSON(r[name])); err != nil {
+				return errors.Wrap(err, "lookup: set column "+name)
+			}
+		}
+		return nil
+	}
+
+	for name, field := range l.columns {
+		value, ok := r[name]
+		if !ok {
+			continue
+		}
+		if err := e.Set(field, copyJSON(value)); err != nil {
+			return errors.Wrap(err, "lookup: set column "+name)
+		}
+	}
+	return nil
+}
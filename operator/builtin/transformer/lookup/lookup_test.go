@@ -0,0 +1,308 @@
+package lookup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testCSV = "host,team,region\nweb-1,frontend,us\ndb-1,storage,eu\n"
+
+type testCase struct {
+	name      string
+	expectErr bool
+	op        *LookupConfig
+	input     func() *entry.Entry
+	output    func() *entry.Entry
+}
+
+func writeTable(t *testing.T, path, contents string) {
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+}
+
+// Test building and processing a LookupConfig
+func TestBuildAndProcess(t *testing.T) {
+	tempDir := t.TempDir()
+	csvPath := filepath.Join(tempDir, "owners.csv")
+	writeTable(t, csvPath, testCSV)
+	txtPath := filepath.Join(tempDir, "owners.txt")
+	writeTable(t, txtPath, testCSV)
+
+	newTestEntry := func(record map[string]interface{}) func() *entry.Entry {
+		return func() *entry.Entry {
+			e := entry.New()
+			e.Timestamp = time.Unix(1586632809, 0)
+			e.Record = record
+			return e
+		}
+	}
+
+	cases := []testCase{
+		{
+			"all_columns",
+			false,
+			defaultCfg(csvPath),
+			newTestEntry(map[string]interface{}{"host": "web-1"}),
+			newTestEntry(map[string]interface{}{"host": "web-1", "team": "frontend", "region": "us"}),
+		},
+		{
+			"columns",
+			false,
+			func() *LookupConfig {
+				cfg := defaultCfg(csvPath)
+				cfg.Columns = map[string]entry.Field{
+					"team":   entry.NewLabelField("team"),
+					"region": entry.NewResourceField("region"),
+				}
+				return cfg
+			}(),
+			newTestEntry(map[string]interface{}{"host": "db-1"}),
+			func() *entry.Entry {
+				e := newTestEntry(map[string]interface{}{"host": "db-1"})()
+				e.Labels = map[string]interface{}{"team": "storage"}
+				e.Resource = map[string]interface{}{"region": "eu"}
+				return e
+			},
+		},
+		{
+			"explicit_format",
+			false,
+			func() *LookupConfig {
+				cfg := defaultCfg(txtPath)
+				cfg.Format = "csv"
+				return cfg
+			}(),
+			newTestEntry(map[string]interface{}{"host": "web-1"}),
+			newTestEntry(map[string]interface{}{"host": "web-1", "team": "frontend", "region": "us"}),
+		},
+		{
+			"miss_ignore",
+			false,
+			defaultCfg(csvPath),
+			newTestEntry(map[string]interface{}{"host": "cache-1"}),
+			newTestEntry(map[string]interface{}{"host": "cache-1"}),
+		},
+		{
+			"missing_key_field",
+			false,
+			defaultCfg(csvPath),
+			newTestEntry(map[string]interface{}{"message": "hello"}),
+			newTestEntry(map[string]interface{}{"message": "hello"}),
+		},
+		{
+			"miss_default",
+			false,
+			func() *LookupConfig {
+				cfg := defaultCfg(csvPath)
+				cfg.OnMiss = defaultOnMiss
+				cfg.Defaults = map[string]interface{}{"team": "unknown"}
+				cfg.Columns = map[string]entry.Field{"team": entry.NewLabelField("team")}
+				return cfg
+			}(),
+			newTestEntry(map[string]interface{}{"host": "cache-1"}),
+			func() *entry.Entry {
+				e := newTestEntry(map[string]interface{}{"host": "cache-1"})()
+				e.Labels = map[string]interface{}{"team": "unknown"}
+				return e
+			},
+		},
+		{
+			"miss_error",
+			true,
+			func() *LookupConfig {
+				cfg := defaultCfg(csvPath)
+				cfg.OnMiss = errorOnMiss
+				return cfg
+			}(),
+			newTestEntry(map[string]interface{}{"host": "cache-1"}),
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.op
+			cfg.OutputIDs = []string{"fake"}
+			cfg.OnError = "drop"
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			lookup := op.(*LookupOperator)
+			fake := testutil.NewFakeOutput(t)
+			lookup.SetOutputs([]operator.Operator{fake})
+			require.NoError(t, lookup.Start())
+			defer lookup.Stop()
+
+			val := tc.input()
+			err = lookup.Process(context.Background(), val)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				fake.ExpectEntry(t, tc.output())
+			}
+		})
+	}
+}
+
+func defaultCfg(path string) *LookupConfig {
+	cfg := NewLookupConfig("lookup")
+	cfg.Path = path
+	cfg.Field = entry.NewRecordField("host")
+	cfg.PollInterval = helper.NewDuration(0)
+	return cfg
+}
+
+func TestBuildInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       *LookupConfig
+		expected string
+	}{
+		{
+			"missing_path",
+			defaultCfg(""),
+			"missing required field 'path'",
+		},
+		{
+			"missing_field",
+			func() *LookupConfig {
+				cfg := defaultCfg("owners.csv")
+				cfg.Field = entry.Field{}
+				return cfg
+			}(),
+			"missing required field 'field'",
+		},
+		{
+			"unknown_extension",
+			defaultCfg("owners.txt"),
+			"unsupported table format 'txt'",
+		},
+		{
+			"invalid_on_miss",
+			func() *LookupConfig {
+				cfg := defaultCfg("owners.csv")
+				cfg.OnMiss = "skip"
+				return cfg
+			}(),
+			"invalid on_miss 'skip'",
+		},
+		{
+			"default_without_defaults",
+			func() *LookupConfig {
+				cfg := defaultCfg("owners.csv")
+				cfg.OnMiss = defaultOnMiss
+				return cfg
+			}(),
+			"requires 'defaults'",
+		},
+		{
+			"negative_poll_interval",
+			func() *LookupConfig {
+				cfg := defaultCfg("owners.csv")
+				cfg.PollInterval = helper.NewDuration(-time.Second)
+				return cfg
+			}(),
+			"poll_interval must not be negative",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.op.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestLookupJSONKeyNumber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codes.json")
+	writeTable(t, path, `[{"code": 404, "description": "not found", "details": {"retry": false}}]`)
+
+	cfg := defaultCfg(path)
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Field = entry.NewRecordField("status")
+	cfg.KeyColumn = "code"
+	cfg.Columns = map[string]entry.Field{
+		"description": entry.NewRecordField("status_text"),
+		"details":     entry.NewRecordField("details"),
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	lookup := ops[0].(*LookupOperator)
+	fake := testutil.NewFakeOutput(t)
+	lookup.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, lookup.Start())
+	defer lookup.Stop()
+
+	e := entry.New()
+	e.Record = map[string]interface{}{"status": 404}
+	require.NoError(t, lookup.Process(context.Background(), e))
+	fake.ExpectRecord(t, map[string]interface{}{
+		"status":      404,
+		"status_text": "not found",
+		"details":     map[string]interface{}{"retry": false},
+	})
+
+	// The written value must be a copy of the table
+	e.Record.(map[string]interface{})["details"].(map[string]interface{})["retry"] = true
+	table := lookup.table.Load().(table)
+	require.Equal(t, false, table["404"]["details"].(map[string]interface{})["retry"])
+}
+
+func TestLookupStartMissingFile(t *testing.T) {
+	cfg := defaultCfg(filepath.Join(t.TempDir(), "missing.csv"))
+	cfg.OutputIDs = []string{"fake"}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	lookup := ops[0].(*LookupOperator)
+
+	require.Error(t, lookup.Start())
+	require.NoError(t, lookup.Stop())
+}
+
+func TestLookupReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owners.csv")
+	writeTable(t, path, testCSV)
+
+	cfg := defaultCfg(path)
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Columns = map[string]entry.Field{"team": entry.NewLabelField("team")}
+	cfg.PollInterval = helper.NewDuration(10 * time.Millisecond)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	lookup := ops[0].(*LookupOperator)
+	fake := testutil.NewFakeOutput(t)
+	lookup.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, lookup.Start())
+	defer lookup.Stop()
+
+	teamOf := func(host string) interface{} {
+		e := entry.New()
+		e.Record = map[string]interface{}{"host": host}
+		require.NoError(t, lookup.Process(context.Background(), e))
+		return fake.ReceiveEntry(t).Labels["team"]
+	}
+
+	require.Equal(t, "frontend", teamOf("web-1"))
+
+	writeTable(t, path, "host,team\nweb-1,platform\n")
+	require.Eventually(t, func() bool {
+		return teamOf("web-1") == "platform"
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// An invalid table is logged, and the previous table is kept
+	writeTable(t, path, "host,team\nweb-1\n")
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, "platform", teamOf("web-1"))
+}
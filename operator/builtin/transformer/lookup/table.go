@@ -0,0 +1,159 @@
+package lookup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/observiq/stanza/entry"
+)
+
+const (
+	// csvFormat is a table with a header row that names its columns
+	csvFormat = "csv"
+	// jsonFormat is a table that is either an object of rows by key, or a list of rows
+	jsonFormat = "json"
+)
+
+// row is the columns of a row of a lookup table, by name
+type row map[string]interface{}
+
+// table is a lookup table of rows by key. A table is never modified after it
+// is loaded, so that it can be read concurrently without locks.
+type table map[string]row
+
+// detectFormat returns the format of a table, using the extension of its path if no format is configured
+func detectFormat(path, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	switch format {
+	case csvFormat, jsonFormat:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported table format '%s', expected 'csv' or 'json'", format)
+	}
+}
+
+// loadTable reads and parses a lookup table from a file
+func loadTable(path, format, keyColumn string) (table, error) {
+	file, err := os.Open(path) // #nosec - the path of the table is configured by the user
+	if err != nil {
+		return nil, fmt.Errorf("open table: %s", err)
+	}
+	defer file.Close()
+
+	switch format {
+	case jsonFormat:
+		return parseJSONTable(file, keyColumn)
+	default:
+		return parseCSVTable(file, keyColumn)
+	}
+}
+
+// parseCSVTable parses a CSV table. The first row is a header that names the columns,
+// and the key column defaults to the first column. Later rows replace earlier rows with the same key.
+func parseCSVTable(reader io.Reader, keyColumn string) (table, error) {
+	csvReader := csv.NewReader(reader)
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse csv: %s", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("parse csv: missing header row")
+	}
+
+	header := records[0]
+	keyIndex := 0
+	if keyColumn != "" {
+		keyIndex = -1
+		for i, column := range header {
+			if column == keyColumn {
+				keyIndex = i
+				break
+			}
+		}
+		if keyIndex == -1 {
+			return nil, fmt.Errorf("parse csv: header has no key column '%s'", keyColumn)
+		}
+	}
+
+	t := make(table, len(records)-1)
+	for _, record := range records[1:] {
+		r := make(row, len(header))
+		for i, column := range header {
+			r[column] = record[i]
+		}
+		t[record[keyIndex]] = r
+	}
+	return t, nil
+}
+
+// parseJSONTable parses a JSON table. The table is either an object of rows by key,
+// or a list of rows that each hold the key in the key column.
+func parseJSONTable(reader io.Reader, keyColumn string) (table, error) {
+	var decoded interface{}
+	if err := json.NewDecoder(reader).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("parse json: %s", err)
+	}
+
+	switch typed := decoded.(type) {
+	case map[string]interface{}:
+		t := make(table, len(typed))
+		for key, value := range typed {
+			r, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("parse json: row '%s' is not an object", key)
+			}
+			t[key] = r
+		}
+		return t, nil
+	case []interface{}:
+		if keyColumn == "" {
+			return nil, fmt.Errorf("parse json: a list of rows requires a key_column")
+		}
+
+		t := make(table, len(typed))
+		for i, value := range typed {
+			r, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("parse json: row %d is not an object", i)
+			}
+			key, ok := r[keyColumn]
+			if !ok || key == nil {
+				return nil, fmt.Errorf("parse json: row %d has no key column '%s'", i, keyColumn)
+			}
+			t[entry.AttributeString(key)] = r
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("parse json: expected an object or a list of rows, but got %T", decoded)
+	}
+}
+
+// copyJSON returns a deep copy of a value decoded from JSON, so that entries
+// never share the maps and lists of a table that other entries also read
+func copyJSON(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for key, child := range typed {
+			copied[key] = copyJSON(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, child := range typed {
+			copied[i] = copyJSON(child)
+		}
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,181 @@
+package lookup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	cases := []struct {
+		path     string
+		format   string
+		expected string
+	}{
+		{"/tables/owners.csv", "", csvFormat},
+		{"/tables/owners.JSON", "", jsonFormat},
+		{"/tables/owners.txt", "csv", csvFormat},
+		{"/tables/owners", "json", jsonFormat},
+	}
+
+	for _, tc := range cases {
+		format, err := detectFormat(tc.path, tc.format)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, format)
+	}
+
+	_, err := detectFormat("/tables/owners.txt", "")
+	require.Error(t, err)
+	_, err = detectFormat("/tables/owners.csv", "yaml")
+	require.Error(t, err)
+}
+
+func TestParseCSVTable(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		keyColumn string
+		expected  table
+	}{
+		{
+			"FirstColumn",
+			"host,team,region\nweb-1,frontend,us\ndb-1,storage,eu\n",
+			"",
+			table{
+				"web-1": {"host": "web-1", "team": "frontend", "region": "us"},
+				"db-1":  {"host": "db-1", "team": "storage", "region": "eu"},
+			},
+		},
+		{
+			"KeyColumn",
+			"team,host\nfrontend,web-1\n",
+			"host",
+			table{
+				"web-1": {"host": "web-1", "team": "frontend"},
+			},
+		},
+		{
+			"LastRowWins",
+			"code,description\n404,not found\n404,missing\n",
+			"",
+			table{
+				"404": {"code": "404", "description": "missing"},
+			},
+		},
+		{
+			"HeaderOnly",
+			"code,description\n",
+			"",
+			table{},
+		},
+		{
+			"Quoted",
+			"code,description\n500,\"internal, server error\"\n",
+			"",
+			table{
+				"500": {"code": "500", "description": "internal, server error"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := parseCSVTable(strings.NewReader(tc.input), tc.keyColumn)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestParseCSVTableInvalid(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		keyColumn string
+	}{
+		{"Empty", "", ""},
+		{"MissingKeyColumn", "host,team\nweb-1,frontend\n", "region"},
+		{"WrongColumnCount", "host,team\nweb-1\n", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseCSVTable(strings.NewReader(tc.input), tc.keyColumn)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseJSONTable(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		keyColumn string
+		expected  table
+	}{
+		{
+			"Object",
+			`{"web-1": {"team": "frontend", "tags": ["a"]}, "db-1": {"team": "storage"}}`,
+			"",
+			table{
+				"web-1": {"team": "frontend", "tags": []interface{}{"a"}},
+				"db-1":  {"team": "storage"},
+			},
+		},
+		{
+			"List",
+			`[{"code": 404, "description": "not found"}, {"code": 500, "description": "server error"}]`,
+			"code",
+			table{
+				"404": {"code": 404.0, "description": "not found"},
+				"500": {"code": 500.0, "description": "server error"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := parseJSONTable(strings.NewReader(tc.input), tc.keyColumn)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, parsed)
+		})
+	}
+}
+
+func TestParseJSONTableInvalid(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     string
+		keyColumn string
+	}{
+		{"NotJSON", `{"web-1"`, ""},
+		{"String", `"web-1"`, ""},
+		{"RowNotObject", `{"web-1": "frontend"}`, ""},
+		{"ListWithoutKeyColumn", `[{"host": "web-1"}]`, ""},
+		{"ListRowNotObject", `["web-1"]`, "host"},
+		{"ListMissingKey", `[{"team": "frontend"}]`, "host"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseJSONTable(strings.NewReader(tc.input), tc.keyColumn)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCopyJSON(t *testing.T) {
+	original := map[string]interface{}{
+		"list":   []interface{}{"a", map[string]interface{}{"key": "value"}},
+		"nested": map[string]interface{}{"key": "value"},
+	}
+
+	copied := copyJSON(original).(map[string]interface{})
+	require.Equal(t, original, copied)
+
+	copied["nested"].(map[string]interface{})["key"] = "changed"
+	copied["list"].([]interface{})[0] = "changed"
+	require.Equal(t, "value", original["nested"].(map[string]interface{})["key"])
+	require.Equal(t, "a", original["list"].([]interface{})[0])
+}
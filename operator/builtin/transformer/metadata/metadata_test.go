@@ -33,7 +33,7 @@ func TestMetadata(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"label1": "value1",
 				}
 				return e
@@ -49,7 +49,7 @@ func TestMetadata(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"label1": "startend",
 				}
 				return e
@@ -65,7 +65,7 @@ func TestMetadata(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"label1": "foo",
 				}
 				return e
@@ -81,7 +81,7 @@ func TestMetadata(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "value1",
 				}
 				return e
@@ -97,7 +97,7 @@ func TestMetadata(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "startend",
 				}
 				return e
@@ -113,7 +113,7 @@ func TestMetadata(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "foo",
 				}
 				return e
@@ -38,6 +38,17 @@ func (c MoveOperatorConfig) Build(context operator.BuildContext) ([]operator.Ope
 		return nil, fmt.Errorf("move: missing to or from field")
 	}
 
+	if _, ok := c.To.FieldInterface.(entry.SelectorField); ok {
+		return nil, fmt.Errorf("move: to field cannot be a selector")
+	}
+
+	// Each field selected by a selector is moved under the to field, so it must be a record field
+	if _, ok := c.From.FieldInterface.(entry.SelectorField); ok {
+		if _, ok := c.To.FieldInterface.(entry.RecordField); !ok {
+			return nil, fmt.Errorf("move: to field must be a record field when from is a selector")
+		}
+	}
+
 	moveOperator := &MoveOperator{
 		TransformerOperator: transformerOperator,
 		From:                c.From,
@@ -61,6 +72,18 @@ func (p *MoveOperator) Process(ctx context.Context, entry *entry.Entry) error {
 
 // Transform will apply the move operation to an entry
 func (p *MoveOperator) Transform(e *entry.Entry) error {
+	if selector, ok := p.From.FieldInterface.(entry.SelectorField); ok {
+		to := p.To.FieldInterface.(entry.RecordField)
+		matches := selector.Select(e)
+		for i := len(matches) - 1; i >= 0; i-- {
+			val, _ := matches[i].Delete(e)
+			if err := selector.Place(e, to, matches[i], val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	val, exist := p.From.Delete(e)
 	if !exist {
 		return fmt.Errorf("move: field does not exist")
@@ -72,7 +72,7 @@ func TestMoveProcess(t *testing.T) {
 						"nestedkey": "nestedval",
 					},
 				}
-				e.Labels = map[string]string{"new": "val"}
+				e.Labels = map[string]interface{}{"new": "val"}
 				return e
 			},
 		},
@@ -87,7 +87,7 @@ func TestMoveProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{"new": "val"}
+				e.Labels = map[string]interface{}{"new": "val"}
 				return e
 			},
 			func() *entry.Entry {
@@ -99,7 +99,7 @@ func TestMoveProcess(t *testing.T) {
 						"nestedkey": "nestedval",
 					},
 				}
-				e.Labels = map[string]string{}
+				e.Labels = map[string]interface{}{}
 				return e
 			},
 		},
@@ -114,13 +114,13 @@ func TestMoveProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{"new": "val"}
+				e.Labels = map[string]interface{}{"new": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "val"}
-				e.Labels = map[string]string{}
+				e.Resource = map[string]interface{}{"new": "val"}
+				e.Labels = map[string]interface{}{}
 				return e
 			},
 		},
@@ -135,13 +135,13 @@ func TestMoveProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{"new": "val"}
+				e.Resource = map[string]interface{}{"new": "val"}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{}
-				e.Labels = map[string]string{"new": "val"}
+				e.Resource = map[string]interface{}{}
+				e.Labels = map[string]interface{}{"new": "val"}
 				return e
 			},
 		},
@@ -347,6 +347,132 @@ func TestMoveProcess(t *testing.T) {
 				return e
 			},
 		},
+		{
+			"MoveListItemToLabel",
+			false,
+			func() *MoveOperatorConfig {
+				cfg := defaultCfg()
+				cfg.From = entry.NewRecordField("items", "-1", "name")
+				cfg.To = entry.NewLabelField("app.kubernetes.io/name")
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"name": "first"},
+						map[string]interface{}{"name": "last"},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"name": "first"},
+						map[string]interface{}{},
+					},
+				}
+				e.Labels = map[string]interface{}{"app.kubernetes.io/name": "last"}
+				return e
+			},
+		},
+		{
+			"MoveToListItem",
+			false,
+			func() *MoveOperatorConfig {
+				cfg := defaultCfg()
+				cfg.From = entry.NewRecordField("key")
+				cfg.To = entry.NewRecordField("items", "0")
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"key":   "val",
+					"items": []interface{}{"old"},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"items": []interface{}{"val"},
+				}
+				return e
+			},
+		},
+		{
+			"MoveSelectorToRecord",
+			false,
+			func() *MoveOperatorConfig {
+				cfg := defaultCfg()
+				cfg.From = entry.NewRecordSelector("users", "*", "password")
+				cfg.To = entry.NewRecordField("secrets")
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"users": map[string]interface{}{
+						"a": map[string]interface{}{"password": "1"},
+						"b": map[string]interface{}{"password": "2"},
+						"c": map[string]interface{}{},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"users": map[string]interface{}{
+						"a": map[string]interface{}{},
+						"b": map[string]interface{}{},
+						"c": map[string]interface{}{},
+					},
+					"secrets": map[string]interface{}{
+						"a": map[string]interface{}{"password": "1"},
+						"b": map[string]interface{}{"password": "2"},
+					},
+				}
+				return e
+			},
+		},
+		{
+			"MoveSelectorInList",
+			false,
+			func() *MoveOperatorConfig {
+				cfg := defaultCfg()
+				cfg.From = entry.NewRecordSelector("users", "*", "password")
+				cfg.To = entry.NewRecordField("secrets")
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"users": []interface{}{
+						map[string]interface{}{"name": "a", "password": "1"},
+						map[string]interface{}{"name": "b", "password": "2"},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"users": []interface{}{
+						map[string]interface{}{"name": "a"},
+						map[string]interface{}{"name": "b"},
+					},
+					"secrets": []interface{}{
+						map[string]interface{}{"password": "1"},
+						map[string]interface{}{"password": "2"},
+					},
+				}
+				return e
+			},
+		},
 	}
 	for _, tc := range cases {
 		t.Run("BuildandProcess/"+tc.name, func(t *testing.T) {
@@ -375,3 +501,19 @@ func TestMoveProcess(t *testing.T) {
 func defaultCfg() *MoveOperatorConfig {
 	return NewMoveOperatorConfig("move")
 }
+
+func TestMoveBuildSelector(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.From = entry.NewLabelSelector("*")
+	cfg.To = entry.NewResourceField("key")
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "to field must be a record field")
+
+	cfg = defaultCfg()
+	cfg.From = entry.NewRecordField("key")
+	cfg.To = entry.NewRecordSelector("*")
+	_, err = cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "to field cannot be a selector")
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// bucket is the token bucket of a key. It refills one token per interval, up to its capacity,
+// and counts the entries that were dropped because it was empty.
+type bucket struct {
+	key     string
+	tokens  float64
+	updated time.Time
+	dropped int
+}
+
+// bucketStore holds the buckets of the most recently used keys
+type bucketStore struct {
+	interval time.Duration
+	capacity float64
+	initial  float64
+	maxKeys  int
+
+	// buckets holds the buckets by key, and order holds them from the most to the least recently used
+	mux     sync.Mutex
+	buckets map[string]*list.Element
+	order   *list.List
+}
+
+// newBucketStore creates a bucket store, whose new buckets start with the initial number of tokens
+func newBucketStore(interval time.Duration, capacity, initial float64, maxKeys int) *bucketStore {
+	return &bucketStore{
+		interval: interval,
+		capacity: capacity,
+		initial:  initial,
+		maxKeys:  maxKeys,
+		buckets:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// take takes a token from the bucket of a key. If the bucket is empty and wait is true, the token is
+// reserved and the time until it is available is returned. If the bucket is empty and wait is false,
+// the entry is counted as dropped and take returns false. If a bucket is created while max_keys buckets
+// exist, the least recently used bucket is removed and returned, so that its drops can be reported.
+func (s *bucketStore) take(key string, now time.Time, wait bool) (delay time.Duration, ok bool, evicted *bucket) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var b *bucket
+	if element, exists := s.buckets[key]; exists {
+		b = element.Value.(*bucket)
+		s.order.MoveToFront(element)
+		s.refill(b, now)
+	} else {
+		b, evicted = s.create(key, now)
+	}
+
+	switch {
+	case b.tokens >= 1:
+		b.tokens--
+		return 0, true, evicted
+	case wait:
+		b.tokens--
+		return time.Duration(-b.tokens * float64(s.interval)), true, evicted
+	default:
+		b.dropped++
+		return 0, false, evicted
+	}
+}
+
+// reset removes every bucket, then creates the bucket of a key, which refills from now on
+func (s *bucketStore) reset(key string, now time.Time) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.buckets = make(map[string]*list.Element)
+	s.order.Init()
+	s.create(key, now)
+}
+
+// create creates the bucket of a key. If max_keys buckets exist, the least recently used
+// bucket is removed and returned.
+func (s *bucketStore) create(key string, now time.Time) (b *bucket, evicted *bucket) {
+	if s.order.Len() >= s.maxKeys {
+		oldest := s.order.Back()
+		evicted = oldest.Value.(*bucket)
+		s.order.Remove(oldest)
+		delete(s.buckets, evicted.key)
+	}
+
+	b = &bucket{key: key, tokens: s.initial, updated: now}
+	s.buckets[key] = s.order.PushFront(b)
+	return b, evicted
+}
+
+// refill adds the tokens that a bucket earned since it was last updated
+func (s *bucketStore) refill(b *bucket, now time.Time) {
+	elapsed := now.Sub(b.updated)
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens += float64(elapsed) / float64(s.interval)
+	if b.tokens > s.capacity {
+		b.tokens = s.capacity
+	}
+	b.updated = now
+}
+
+// takeDropped returns the number of dropped entries of each key that dropped entries, and resets them
+func (s *bucketStore) takeDropped() map[string]int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	dropped := make(map[string]int)
+	for element := s.order.Front(); element != nil; element = element.Next() {
+		b := element.Value.(*bucket)
+		if b.dropped > 0 {
+			dropped[b.key] = b.dropped
+			b.dropped = 0
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketStoreTake(t *testing.T) {
+	store := newBucketStore(time.Second, 2, 2, 10)
+	now := time.Date(2020, time.June, 4, 12, 0, 0, 0, time.UTC)
+
+	// A new key starts with the initial tokens
+	for i := 0; i < 2; i++ {
+		delay, ok, evicted := store.take("a", now, false)
+		require.True(t, ok)
+		require.Zero(t, delay)
+		require.Nil(t, evicted)
+	}
+
+	_, ok, _ := store.take("a", now, false)
+	require.False(t, ok)
+
+	// Other keys have their own buckets
+	_, ok, _ = store.take("b", now, false)
+	require.True(t, ok)
+
+	// The bucket refills one token per interval
+	_, ok, _ = store.take("a", now.Add(time.Second), false)
+	require.True(t, ok)
+	_, ok, _ = store.take("a", now.Add(time.Second), false)
+	require.False(t, ok)
+
+	// The bucket does not refill past its capacity
+	for i := 0; i < 2; i++ {
+		_, ok, _ = store.take("a", now.Add(time.Hour), false)
+		require.True(t, ok)
+	}
+	_, ok, _ = store.take("a", now.Add(time.Hour), false)
+	require.False(t, ok)
+
+	require.Equal(t, map[string]int{"a": 3}, store.takeDropped())
+	require.Equal(t, map[string]int{}, store.takeDropped())
+}
+
+func TestBucketStoreWait(t *testing.T) {
+	store := newBucketStore(time.Second, 1, 1, 10)
+	now := time.Date(2020, time.June, 4, 12, 0, 0, 0, time.UTC)
+
+	delay, ok, _ := store.take("a", now, true)
+	require.True(t, ok)
+	require.Zero(t, delay)
+
+	// Each entry that waits reserves the next token
+	for i := 1; i <= 3; i++ {
+		delay, ok, _ = store.take("a", now, true)
+		require.True(t, ok)
+		require.Equal(t, time.Duration(i)*time.Second, delay)
+	}
+
+	delay, ok, _ = store.take("a", now.Add(3*time.Second), true)
+	require.True(t, ok)
+	require.Equal(t, time.Second, delay)
+
+	require.Equal(t, map[string]int{}, store.takeDropped())
+}
+
+func TestBucketStoreEviction(t *testing.T) {
+	store := newBucketStore(time.Second, 1, 1, 2)
+	now := time.Date(2020, time.June, 4, 12, 0, 0, 0, time.UTC)
+
+	_, _, evicted := store.take("a", now, false)
+	require.Nil(t, evicted)
+	_, _, evicted = store.take("b", now, false)
+	require.Nil(t, evicted)
+
+	// Using a key makes it the most recently used
+	_, ok, _ := store.take("a", now, false)
+	require.False(t, ok)
+
+	_, _, evicted = store.take("c", now, false)
+	require.NotNil(t, evicted)
+	require.Equal(t, "b", evicted.key)
+
+	_, _, evicted = store.take("b", now, false)
+	require.NotNil(t, evicted)
+	require.Equal(t, "a", evicted.key)
+	require.Equal(t, 1, evicted.dropped)
+
+	require.Equal(t, map[string]int{}, store.takeDropped())
+}
+
+func TestBucketStoreReset(t *testing.T) {
+	store := newBucketStore(time.Second, 2, 0, 10)
+	now := time.Date(2020, time.June, 4, 12, 0, 0, 0, time.UTC)
+
+	// A bucket without initial tokens only refills from the time it was reset
+	store.reset("", now)
+	delay, ok, _ := store.take("", now, true)
+	require.True(t, ok)
+	require.Equal(t, time.Second, delay)
+
+	store.reset("", now)
+	delay, ok, _ = store.take("", now.Add(time.Second), true)
+	require.True(t, ok)
+	require.Zero(t, delay)
+}
@@ -3,22 +3,37 @@ package ratelimit
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/operator/helper"
+	"go.uber.org/zap"
 )
 
 func init() {
 	operator.Register("rate_limit", func() operator.Builder { return NewRateLimitConfig("") })
 }
 
+const (
+	// blockAction waits until an entry is within the limit
+	blockAction = "block"
+	// dropAction drops the entries that are over the limit
+	dropAction = "drop"
+	// dropAndSummarizeAction drops the entries that are over the limit, and periodically sends the number dropped
+	dropAndSummarizeAction = "drop_and_summarize"
+)
+
 // NewRateLimitConfig creates a new rate limit config with default values
 func NewRateLimitConfig(operatorID string) *RateLimitConfig {
 	return &RateLimitConfig{
 		TransformerConfig: helper.NewTransformerConfig(operatorID, "rate_limit"),
+		MaxKeys:           10000,
+		SummaryInterval:   helper.NewDuration(time.Minute),
 	}
 }
 
@@ -26,9 +41,13 @@ func NewRateLimitConfig(operatorID string) *RateLimitConfig {
 type RateLimitConfig struct {
 	helper.TransformerConfig `yaml:",inline"`
 
-	Rate     float64         `json:"rate,omitempty"     yaml:"rate,omitempty"`
-	Interval helper.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
-	Burst    uint            `json:"burst,omitempty"    yaml:"burst,omitempty"`
+	Rate            float64         `json:"rate,omitempty"             yaml:"rate,omitempty"`
+	Interval        helper.Duration `json:"interval,omitempty"         yaml:"interval,omitempty"`
+	Burst           uint            `json:"burst,omitempty"            yaml:"burst,omitempty"`
+	Key             string          `json:"key,omitempty"              yaml:"key,omitempty"`
+	MaxKeys         int             `json:"max_keys,omitempty"         yaml:"max_keys,omitempty"`
+	OnLimit         string          `json:"on_limit,omitempty"         yaml:"on_limit,omitempty"`
+	SummaryInterval helper.Duration `json:"summary_interval,omitempty" yaml:"summary_interval,omitempty"`
 }
 
 // Build will build a rate limit operator.
@@ -50,58 +69,176 @@ func (c RateLimitConfig) Build(context operator.BuildContext) ([]operator.Operat
 		interval = c.Interval.Raw()
 	}
 
+	if interval <= 0 {
+		return nil, fmt.Errorf("one of 'rate' or 'interval' must be defined")
+	}
+
+	var key *vm.Program
+	if c.Key != "" {
+		key, err = expr.Compile(c.Key, expr.AllowUndefinedVariables())
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile key '%s': %s", c.Key, err)
+		}
+	}
+
+	if c.MaxKeys <= 0 {
+		return nil, fmt.Errorf("max_keys must be greater than zero")
+	}
+
+	// Entries over the limit of one key would block the entries of every other key
+	// behind them, so keyed limits drop entries by default
+	onLimit := c.OnLimit
+	switch {
+	case onLimit == "" && key != nil:
+		onLimit = dropAction
+	case onLimit == "":
+		onLimit = blockAction
+	case onLimit == blockAction && key != nil:
+		transformerOperator.Warnw("A keyed rate limit with on_limit 'block' blocks the entries of every key while one key is over its limit. Use 'drop' or 'drop_and_summarize' to limit each key independently.")
+	}
+
+	switch onLimit {
+	case blockAction, dropAction:
+	case dropAndSummarizeAction:
+		if c.SummaryInterval.Raw() <= 0 {
+			return nil, fmt.Errorf("summary_interval must be greater than zero")
+		}
+	default:
+		return nil, fmt.Errorf("invalid on_limit '%s', expected 'block', 'drop' or 'drop_and_summarize'", onLimit)
+	}
+
+	// A keyed bucket starts full, so that a new key is not limited before it sends anything.
+	// Without a key, the bucket starts empty and fills from the time the operator starts.
+	capacity, initial := float64(c.Burst)+1, float64(0)
+	if key != nil {
+		initial = capacity
+	}
+
 	rateLimitOperator := &RateLimitOperator{
 		TransformerOperator: transformerOperator,
-		interval:            interval,
-		burst:               c.Burst,
+		key:                 key,
+		onLimit:             onLimit,
+		summaryInterval:     c.SummaryInterval.Raw(),
+		buckets:             newBucketStore(interval, capacity, initial, c.MaxKeys),
+		start:               time.Now(),
 	}
 
 	return []operator.Operator{rateLimitOperator}, nil
 }
 
 // RateLimitOperator is an operator that limits the rate of log consumption between operators.
+// Each key has its own limit, and entries without a key share one limit.
 type RateLimitOperator struct {
 	helper.TransformerOperator
 
-	interval time.Duration
-	burst    uint
-	isReady  chan struct{}
-	cancel   context.CancelFunc
-	wg       sync.WaitGroup
+	key             *vm.Program
+	onLimit         string
+	summaryInterval time.Duration
+	buckets         *bucketStore
+
+	// start holds the time the current summary interval started
+	mux   sync.Mutex
+	start time.Time
+
+	done   <-chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
-// Process will wait until a rate is met before sending an entry to the output.
-func (p *RateLimitOperator) Process(ctx context.Context, entry *entry.Entry) error {
-	select {
-	case <-p.isReady:
-		p.Write(ctx, entry)
+// Process will wait until an entry is within the limit of its key before sending it to the output,
+// or drop it if it is over the limit and the operator is configured to drop.
+func (p *RateLimitOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := p.Skip(ctx, e)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		p.Write(ctx, e)
 		return nil
-	case <-ctx.Done():
+	}
+
+	key, err := p.keyOf(e)
+	if err != nil {
+		return p.HandleEntryError(ctx, e, err)
+	}
+
+	delay, ok, evicted := p.buckets.take(key, time.Now(), p.onLimit == blockAction)
+	if evicted != nil && evicted.dropped > 0 && p.onLimit == dropAndSummarizeAction {
+		p.Debugw("Summarizing key early because max_keys was reached", zap.String("key", evicted.key))
+		p.summarize(ctx, map[string]int{evicted.key: evicted.dropped}, p.intervalStart())
+	}
+	if !ok {
+		p.CountDropped()
 		return nil
 	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-p.done:
+		case <-ctx.Done():
+			p.CountDropped()
+			return nil
+		}
+	}
+
+	p.Write(ctx, e)
+	return nil
+}
+
+// keyOf evaluates the key of an entry. Entries share the empty key if no key is configured.
+func (p *RateLimitOperator) keyOf(e *entry.Entry) (string, error) {
+	if p.key == nil {
+		return "", nil
+	}
+
+	env := helper.GetExprEnv(e)
+	defer helper.PutExprEnv(env)
+
+	key, err := vm.Run(p.key, env)
+	if err != nil {
+		return "", fmt.Errorf("evaluate key: %s", err)
+	}
+	if key == nil {
+		return "", nil
+	}
+	return entry.AttributeString(key), nil
 }
 
 // Start will start the rate limit operator.
 func (p *RateLimitOperator) Start() error {
-	p.isReady = make(chan struct{}, p.burst)
-	ticker := time.NewTicker(p.interval)
-
 	ctx, cancel := context.WithCancel(context.Background())
 	p.cancel = cancel
+	p.done = ctx.Done()
+
+	p.mux.Lock()
+	p.start = time.Now()
+	p.mux.Unlock()
+
+	if p.key == nil {
+		p.buckets.reset("", time.Now())
+	}
+
+	if p.onLimit != dropAndSummarizeAction {
+		return nil
+	}
 
-	// Buffer the ticker ticks in isReady to allow bursts
 	p.wg.Add(1)
 	go func() {
 		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.summaryInterval)
 		defer ticker.Stop()
-		defer close(p.isReady)
 
 		for {
 			select {
-			case <-ticker.C:
-				p.increment(ctx)
 			case <-ctx.Done():
 				return
+			case <-ticker.C:
+				p.flush(ctx)
 			}
 		}
 	}()
@@ -109,18 +246,61 @@ func (p *RateLimitOperator) Start() error {
 	return nil
 }
 
-func (p *RateLimitOperator) increment(ctx context.Context) {
-	select {
-	case p.isReady <- struct{}{}:
-		return
-	case <-ctx.Done():
-		return
-	}
-}
-
-// Stop will stop the rate limit operator.
+// Stop will stop the rate limit operator. Entries that are waiting are sent immediately,
+// and the drops of the current summary interval are summarized.
 func (p *RateLimitOperator) Stop() error {
-	p.cancel()
+	if p.cancel != nil {
+		p.cancel()
+	}
 	p.wg.Wait()
+
+	if p.onLimit == dropAndSummarizeAction {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		p.flush(ctx)
+	}
 	return nil
 }
+
+// intervalStart returns the time the current summary interval started
+func (p *RateLimitOperator) intervalStart() time.Time {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.start
+}
+
+// flush summarizes the drops of the current summary interval, then starts a new interval
+func (p *RateLimitOperator) flush(ctx context.Context) {
+	p.mux.Lock()
+	start := p.start
+	p.start = time.Now()
+	p.mux.Unlock()
+
+	p.summarize(ctx, p.buckets.takeDropped(), start)
+}
+
+// summarize writes an entry for each key that dropped entries, with the number of entries dropped since start
+func (p *RateLimitOperator) summarize(ctx context.Context, dropped map[string]int, start time.Time) {
+	keys := make([]string, 0, len(dropped))
+	for key := range dropped {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		summary := entry.New()
+		summary.Timestamp = start
+		summary.Severity = entry.Warning
+
+		record := map[string]interface{}{
+			"dropped":  dropped[key],
+			"interval": time.Since(start).Round(time.Millisecond).String(),
+		}
+		if p.key != nil {
+			record["key"] = key
+		}
+		summary.Record = record
+
+		p.Write(ctx, summary)
+	}
+}
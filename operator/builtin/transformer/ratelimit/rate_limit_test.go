@@ -7,8 +7,11 @@ import (
 	"time"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
 	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
 	"github.com/observiq/stanza/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -62,3 +65,336 @@ func TestRateLimit(t *testing.T) {
 
 	require.InEpsilon(t, elapsed.Nanoseconds(), 5*time.Second.Nanoseconds(), 0.6)
 }
+
+type testCase struct {
+	name      string
+	expectErr bool
+	op        *RateLimitConfig
+	input     func() []*entry.Entry
+	output    func() []*entry.Entry
+}
+
+func newTestEntry(pod string) *entry.Entry {
+	e := entry.New()
+	e.Timestamp = time.Unix(1586632809, 0)
+	e.Record = map[string]interface{}{"pod": pod}
+	return e
+}
+
+// newTestEntries creates an entry for each pod
+func newTestEntries(pods ...string) []*entry.Entry {
+	entries := make([]*entry.Entry, 0, len(pods))
+	for _, pod := range pods {
+		entries = append(entries, newTestEntry(pod))
+	}
+	return entries
+}
+
+// Test building and processing a RateLimitConfig. The interval is an hour,
+// so no bucket refills while a case runs.
+func TestBuildAndProcess(t *testing.T) {
+	cases := []testCase{
+		{
+			"key_drop",
+			false,
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.Burst = 2
+				cfg.Key = `$record.pod`
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return newTestEntries("chatty", "chatty", "chatty", "chatty", "chatty", "quiet", "chatty")
+			},
+			func() []*entry.Entry {
+				// The chatty pod is limited to its burst, and does not affect the quiet pod
+				return newTestEntries("chatty", "chatty", "chatty", "quiet")
+			},
+		},
+		{
+			"drop_starts_empty",
+			false,
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.Burst = 2
+				cfg.OnLimit = "drop"
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return newTestEntries("chatty", "quiet")
+			},
+			func() []*entry.Entry {
+				// Without a key, the bucket starts empty, so every entry is dropped within the first interval
+				return nil
+			},
+		},
+		{
+			"if",
+			false,
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.OnLimit = "drop"
+				cfg.IfExpr = `$record.pod == "chatty"`
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return newTestEntries("chatty", "quiet", "quiet")
+			},
+			func() []*entry.Entry {
+				return newTestEntries("quiet", "quiet")
+			},
+		},
+		{
+			"key_error",
+			true,
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.Key = `upper($record)`
+				return cfg
+			}(),
+			func() []*entry.Entry {
+				return newTestEntries("chatty")
+			},
+			func() []*entry.Entry {
+				return nil
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.op
+			cfg.OutputIDs = []string{"fake"}
+			cfg.OnError = "drop"
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			rateLimit := op.(*RateLimitOperator)
+			fake := testutil.NewFakeOutput(t)
+			rateLimit.SetOutputs([]operator.Operator{fake})
+			require.NoError(t, rateLimit.Start())
+			defer rateLimit.Stop()
+
+			var processErr error
+			for _, val := range tc.input() {
+				if err := rateLimit.Process(context.Background(), val); err != nil {
+					processErr = err
+				}
+			}
+			if tc.expectErr {
+				require.Error(t, processErr)
+			} else {
+				require.NoError(t, processErr)
+			}
+
+			for _, expected := range tc.output() {
+				fake.ExpectEntry(t, expected)
+			}
+			fake.ExpectNoEntry(t, 10*time.Millisecond)
+		})
+	}
+}
+
+func defaultCfg() *RateLimitConfig {
+	cfg := NewRateLimitConfig("rate_limit")
+	cfg.Interval = helper.NewDuration(time.Hour)
+	return cfg
+}
+
+func TestBuildInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       *RateLimitConfig
+		expected string
+	}{
+		{
+			"rate_and_interval",
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 10
+				return cfg
+			}(),
+			"only one of 'rate' or 'interval'",
+		},
+		{
+			"no_rate_or_interval",
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.Interval = helper.NewDuration(0)
+				return cfg
+			}(),
+			"one of 'rate' or 'interval' must be defined",
+		},
+		{
+			"invalid_key",
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.Key = `$record.pod ==`
+				return cfg
+			}(),
+			"failed to compile key",
+		},
+		{
+			"zero_max_keys",
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.MaxKeys = 0
+				return cfg
+			}(),
+			"max_keys must be greater than zero",
+		},
+		{
+			"zero_summary_interval",
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.OnLimit = "drop_and_summarize"
+				cfg.SummaryInterval = helper.NewDuration(0)
+				return cfg
+			}(),
+			"summary_interval must be greater than zero",
+		},
+		{
+			"invalid_on_limit",
+			func() *RateLimitConfig {
+				cfg := defaultCfg()
+				cfg.OnLimit = "queue"
+				return cfg
+			}(),
+			"invalid on_limit 'queue'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.op.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+func TestRateLimitKeyBlock(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Interval = helper.NewDuration(200 * time.Millisecond)
+	cfg.Key = `$record.pod`
+	cfg.OnLimit = "block"
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	rateLimit := ops[0].(*RateLimitOperator)
+	fake := testutil.NewFakeOutput(t)
+	rateLimit.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, rateLimit.Start())
+	defer rateLimit.Stop()
+
+	// A key that is over its limit blocks the caller, and so the entries of other keys behind it
+	start := time.Now()
+	for _, pod := range []string{"chatty", "chatty", "quiet"} {
+		require.NoError(t, rateLimit.Process(context.Background(), newTestEntry(pod)))
+	}
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(150*time.Millisecond))
+
+	for _, pod := range []string{"chatty", "chatty", "quiet"} {
+		fake.ExpectRecord(t, map[string]interface{}{"pod": pod})
+	}
+}
+
+func TestRateLimitStartsEmpty(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Interval = helper.NewDuration(200 * time.Millisecond)
+	cfg.Burst = 5
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	rateLimit := ops[0].(*RateLimitOperator)
+	fake := testutil.NewFakeOutput(t)
+	rateLimit.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, rateLimit.Start())
+	defer rateLimit.Stop()
+
+	// Without a key, the first entry waits for the first interval, like before keys were supported
+	start := time.Now()
+	require.NoError(t, rateLimit.Process(context.Background(), newTestEntry("chatty")))
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(150*time.Millisecond))
+	fake.ExpectRecord(t, map[string]interface{}{"pod": "chatty"})
+}
+
+func TestRateLimitDropAndSummarize(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Key = `$record.pod`
+	cfg.OnLimit = "drop_and_summarize"
+	cfg.SummaryInterval = helper.NewDuration(100 * time.Millisecond)
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	rateLimit := ops[0].(*RateLimitOperator)
+	fake := testutil.NewFakeOutput(t)
+	rateLimit.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, rateLimit.Start())
+	defer rateLimit.Stop()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, rateLimit.Process(context.Background(), newTestEntry("chatty")))
+	}
+	require.NoError(t, rateLimit.Process(context.Background(), newTestEntry("quiet")))
+	require.Len(t, fake.ReceiveAll(), 2)
+
+	summary := fake.ReceiveEntry(t)
+	require.Equal(t, entry.Warning, summary.Severity)
+	record := summary.Record.(map[string]interface{})
+	require.Equal(t, "chatty", record["key"])
+	require.Equal(t, 4, record["dropped"])
+	require.Contains(t, record, "interval")
+
+	// Intervals without drops are not summarized
+	fake.ExpectNoEntry(t, 250*time.Millisecond)
+}
+
+func TestRateLimitSummarizeOnStop(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.OnLimit = "drop_and_summarize"
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	rateLimit := ops[0].(*RateLimitOperator)
+	fake := testutil.NewFakeOutput(t)
+	rateLimit.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, rateLimit.Start())
+
+	// Without a key, the bucket starts empty, so every entry is dropped within the first interval
+	for i := 0; i < 3; i++ {
+		require.NoError(t, rateLimit.Process(context.Background(), newTestEntry("chatty")))
+	}
+	require.Len(t, fake.ReceiveAll(), 0)
+
+	require.NoError(t, rateLimit.Stop())
+	received := fake.ReceiveAll()
+	require.Len(t, received, 1)
+
+	record := received[0].Record.(map[string]interface{})
+	require.Equal(t, 3, record["dropped"])
+	require.NotContains(t, record, "key")
+}
+
+func TestRateLimitDropMetrics(t *testing.T) {
+	cfg := NewRateLimitConfig("rate_limit_metrics")
+	cfg.Interval = helper.NewDuration(time.Hour)
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Key = `$record.pod`
+	cfg.OnLimit = "drop"
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	rateLimit := ops[0].(*RateLimitOperator)
+	fake := testutil.NewFakeOutput(t)
+	rateLimit.SetOutputs([]operator.Operator{fake})
+	require.NoError(t, rateLimit.Start())
+	defer rateLimit.Stop()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, rateLimit.Process(context.Background(), newTestEntry("chatty")))
+	}
+	require.Len(t, fake.ReceiveAll(), 1)
+	require.Equal(t, float64(2), promtestutil.ToFloat64(metrics.Dropped.WithLabelValues("$.rate_limit_metrics")))
+	require.Equal(t, float64(0), promtestutil.ToFloat64(metrics.Errors.WithLabelValues("$.rate_limit_metrics")))
+}
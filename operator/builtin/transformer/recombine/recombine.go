@@ -183,13 +183,15 @@ func (r *RecombineOperator) matchIndicatesLast() bool {
 	return !r.matchFirstLine
 }
 
-// addToBatch adds the current entry to the current batch of entries that will be combined
+// addToBatch adds the current entry to the current batch of entries that will be combined.
+// The entry is retained until it has been written.
 func (r *RecombineOperator) addToBatch(_ context.Context, e *entry.Entry) {
 	if len(r.batch) >= r.maxBatchSize {
 		r.Error("Batch size exceeds max batch size. Flushing logs that have not been recombined")
 		r.flushUncombined(context.Background())
 	}
 
+	e.Retain()
 	r.batch = append(r.batch, e)
 }
 
@@ -199,6 +201,7 @@ func (r *RecombineOperator) addToBatch(_ context.Context, e *entry.Entry) {
 func (r *RecombineOperator) flushUncombined(ctx context.Context) {
 	for _, entry := range r.batch {
 		r.Write(ctx, entry)
+		entry.Release()
 	}
 	r.batch = r.batch[:0]
 }
@@ -242,7 +245,10 @@ func (r *RecombineOperator) flushCombined() error {
 		return err
 	}
 
+	// Release the batched entries once the combined entry has been delivered
+	base.Combine(r.batch)
 	r.Write(context.Background(), base)
+	base.Release()
 	r.batch = r.batch[:0]
 	return nil
 }
@@ -0,0 +1,155 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+)
+
+func init() {
+	operator.Register("redact", func() operator.Builder { return NewRedactConfig("") })
+}
+
+// NewRedactConfig creates a new redact config with default values
+func NewRedactConfig(operatorID string) *RedactConfig {
+	return &RedactConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "redact"),
+	}
+}
+
+// RedactConfig is the configuration of a redact operator
+type RedactConfig struct {
+	helper.TransformerConfig `yaml:",inline"`
+
+	Fields []entry.Field `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Rules  []RuleConfig  `json:"rules"            yaml:"rules"`
+}
+
+// Build will build a redact operator from the supplied configuration
+func (c RedactConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Rules) == 0 {
+		return nil, fmt.Errorf("redact: missing required field 'rules'")
+	}
+
+	rules := make([]*rule, 0, len(c.Rules))
+	names := make(map[string]bool, len(c.Rules))
+	for i, ruleConfig := range c.Rules {
+		r, err := ruleConfig.build(transformerOperator.ID())
+		if err != nil {
+			return nil, fmt.Errorf("redact: rule %d: %s", i, err)
+		}
+		if names[r.name] {
+			return nil, fmt.Errorf("redact: rule %d: duplicate rule name '%s'", i, r.name)
+		}
+		names[r.name] = true
+		rules = append(rules, r)
+	}
+
+	redactOperator := &RedactOperator{
+		TransformerOperator: transformerOperator,
+		fields:              c.Fields,
+		rules:               rules,
+	}
+
+	return []operator.Operator{redactOperator}, nil
+}
+
+// RedactOperator is an operator that replaces sensitive values in entries
+type RedactOperator struct {
+	helper.TransformerOperator
+
+	fields []entry.Field
+	rules  []*rule
+}
+
+// Process will process an entry with a redact transformation
+func (r *RedactOperator) Process(ctx context.Context, entry *entry.Entry) error {
+	return r.ProcessWith(ctx, entry, r.Transform)
+}
+
+// Transform will redact the configured fields of an entry, or every string in its record
+func (r *RedactOperator) Transform(e *entry.Entry) error {
+	if len(r.fields) == 0 {
+		e.Record = r.redactValue(e.Record)
+		return nil
+	}
+
+	for _, field := range r.fields {
+		if selector, ok := field.FieldInterface.(entry.SelectorField); ok {
+			for _, match := range selector.Select(e) {
+				if err := r.redactField(e, match); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := r.redactField(e, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactField redacts the value of a field, if the entry has the field
+func (r *RedactOperator) redactField(e *entry.Entry, field entry.Field) error {
+	value, ok := e.Get(field)
+	if !ok {
+		return nil
+	}
+
+	if err := e.Set(field, r.redactValue(value)); err != nil {
+		return errors.Wrap(err, "redact: set "+field.String())
+	}
+	return nil
+}
+
+// redactValue redacts a string, or every string nested in a map or list.
+// Maps and lists are redacted in place, and values of other types are not changed.
+func (r *RedactOperator) redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case string:
+		return r.redactString(typed)
+	case []byte:
+		return []byte(r.redactString(string(typed)))
+	case map[string]interface{}:
+		for key, child := range typed {
+			typed[key] = r.redactValue(child)
+		}
+		return typed
+	case map[string]string:
+		for key, child := range typed {
+			typed[key] = r.redactString(child)
+		}
+		return typed
+	case []interface{}:
+		for i, child := range typed {
+			typed[i] = r.redactValue(child)
+		}
+		return typed
+	case []string:
+		for i, child := range typed {
+			typed[i] = r.redactString(child)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// redactString applies every rule to a string, in order
+func (r *RedactOperator) redactString(str string) string {
+	for _, rule := range r.rules {
+		str, _ = rule.redact(str)
+	}
+	return str
+}
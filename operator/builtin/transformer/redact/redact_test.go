@@ -0,0 +1,232 @@
+package redact
+
+import (
+	"context"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactBuild(t *testing.T) {
+	cases := []struct {
+		name      string
+		rules     []RuleConfig
+		expectErr bool
+	}{
+		{"Valid", []RuleConfig{{Detector: "email"}, {Name: "token", Regex: "token=\\w+"}}, false},
+		{"MissingRules", nil, true},
+		{"InvalidRule", []RuleConfig{{Detector: "phone"}}, true},
+		{"DuplicateName", []RuleConfig{{Detector: "email"}, {Name: "email", Regex: "@"}}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRedactConfig("test")
+			cfg.OutputIDs = []string{"fake"}
+			cfg.Rules = tc.rules
+
+			_, err := cfg.Build(testutil.NewBuildContext(t))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRedactTransform(t *testing.T) {
+	rules := []RuleConfig{
+		{Detector: "email"},
+		{Detector: "credit_card", Mode: keepLastMode, KeepLast: 4},
+		{Detector: "ipv4", Mask: "x.x.x.x"},
+	}
+
+	cases := []struct {
+		name     string
+		fields   []entry.Field
+		input    func() *entry.Entry
+		expected func() *entry.Entry
+	}{
+		{
+			"RecordString",
+			nil,
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = "user a@example.com paid with 4111111111111111 from 10.0.0.1"
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = "user [REDACTED] paid with ************1111 from x.x.x.x"
+				return e
+			},
+		},
+		{
+			"RecordNested",
+			nil,
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{
+					"message": "login from 10.0.0.1",
+					"status":  200,
+					"user": map[string]interface{}{
+						"email":  "a@example.com",
+						"emails": []interface{}{"b@example.com", 1},
+						"tags":   []string{"10.0.0.2"},
+					},
+					"headers": map[string]string{"x-forwarded-for": "10.0.0.3"},
+					"raw":     []byte("a@example.com"),
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{
+					"message": "login from x.x.x.x",
+					"status":  200,
+					"user": map[string]interface{}{
+						"email":  "[REDACTED]",
+						"emails": []interface{}{"[REDACTED]", 1},
+						"tags":   []string{"x.x.x.x"},
+					},
+					"headers": map[string]string{"x-forwarded-for": "x.x.x.x"},
+					"raw":     []byte("[REDACTED]"),
+				}
+				return e
+			},
+		},
+		{
+			"RecordDoesNotRedactLabels",
+			nil,
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{"message": "a@example.com"}
+				e.Labels = map[string]interface{}{"owner": "b@example.com"}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{"message": "[REDACTED]"}
+				e.Labels = map[string]interface{}{"owner": "b@example.com"}
+				return e
+			},
+		},
+		{
+			"Fields",
+			[]entry.Field{entry.NewRecordField("message"), entry.NewLabelField("owner"), entry.NewRecordField("missing")},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{
+					"message": "from a@example.com",
+					"other":   "from b@example.com",
+				}
+				e.Labels = map[string]interface{}{"owner": "c@example.com"}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{
+					"message": "from [REDACTED]",
+					"other":   "from b@example.com",
+				}
+				e.Labels = map[string]interface{}{"owner": "[REDACTED]"}
+				return e
+			},
+		},
+		{
+			"FieldNested",
+			[]entry.Field{entry.NewRecordField("user")},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{
+					"user":    map[string]interface{}{"email": "a@example.com"},
+					"message": "from b@example.com",
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{
+					"user":    map[string]interface{}{"email": "[REDACTED]"},
+					"message": "from b@example.com",
+				}
+				return e
+			},
+		},
+		{
+			"Selector",
+			[]entry.Field{entry.NewRecordSelector("users", "*", "email")},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{
+					"users": []interface{}{
+						map[string]interface{}{"email": "a@example.com", "note": "b@example.com"},
+						map[string]interface{}{"email": "c@example.com"},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := entry.New()
+				e.Record = map[string]interface{}{
+					"users": []interface{}{
+						map[string]interface{}{"email": "[REDACTED]", "note": "b@example.com"},
+						map[string]interface{}{"email": "[REDACTED]"},
+					},
+				}
+				return e
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewRedactConfig("test")
+			cfg.OutputIDs = []string{"fake"}
+			cfg.Fields = tc.fields
+			cfg.Rules = rules
+
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			fake := testutil.NewFakeOutput(t)
+			require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+			input := tc.input()
+			expected := tc.expected()
+			expected.Timestamp = input.Timestamp
+
+			require.NoError(t, op.Process(context.Background(), input))
+			fake.ExpectEntry(t, expected)
+		})
+	}
+}
+
+func TestRedactCountsPerRule(t *testing.T) {
+	cfg := NewRedactConfig("test_counts_per_rule")
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Rules = []RuleConfig{{Detector: "email"}, {Detector: "ipv4"}}
+
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	op := ops[0].(*RedactOperator)
+
+	fake := testutil.NewFakeOutput(t)
+	require.NoError(t, op.SetOutputs([]operator.Operator{fake}))
+
+	e := entry.New()
+	e.Record = map[string]interface{}{
+		"a": "a@example.com and b@example.com",
+		"b": "10.0.0.1",
+	}
+	require.NoError(t, op.Process(context.Background(), e))
+
+	require.Equal(t, float64(2), promtestutil.ToFloat64(op.rules[0].redactions))
+	require.Equal(t, float64(1), promtestutil.ToFloat64(op.rules[1].redactions))
+}
@@ -0,0 +1,231 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/observiq/stanza/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// maskMode replaces a value with a fixed string
+	maskMode = "mask"
+	// keepLastMode replaces all but the last characters of a value with asterisks
+	keepLastMode = "keep_last"
+	// hmacMode replaces a value with its salted HMAC-SHA256, so that equal values stay equal
+	hmacMode = "hmac"
+
+	// redactGroup is the name of the capture group that limits a rule to a part of its match
+	redactGroup = "redact"
+	// defaultMask is the string that replaces values in the mask mode
+	defaultMask = "[REDACTED]"
+)
+
+// detector is a built-in rule, which finds a kind of sensitive value
+type detector struct {
+	regex    *regexp.Regexp
+	validate func(string) bool
+}
+
+// detectors are the built-in rules, by name
+var detectors = map[string]detector{
+	"credit_card": {
+		regex:    regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		validate: isCreditCard,
+	},
+	"email": {
+		regex: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	"ipv4": {
+		regex: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+	},
+	"ipv6": {
+		regex:    regexp.MustCompile(`(?i)(?:[0-9a-f]{0,4}:){2,7}(?:(?:\d{1,3}\.){3}\d{1,3}|[0-9a-f]{0,4})`),
+		validate: isIPv6,
+	},
+	"bearer_token": {
+		regex: regexp.MustCompile(`(?i)\bbearer\s+(?P<redact>[A-Za-z0-9\-._~+/]+=*)`),
+	},
+}
+
+// RuleConfig is the configuration of a redaction rule
+type RuleConfig struct {
+	Name     string `json:"name,omitempty"      yaml:"name,omitempty"`
+	Detector string `json:"detector,omitempty"  yaml:"detector,omitempty"`
+	Regex    string `json:"regex,omitempty"     yaml:"regex,omitempty"`
+	Mode     string `json:"mode,omitempty"      yaml:"mode,omitempty"`
+	Mask     string `json:"mask,omitempty"      yaml:"mask,omitempty"`
+	KeepLast int    `json:"keep_last,omitempty" yaml:"keep_last,omitempty"`
+	Salt     string `json:"salt,omitempty"      yaml:"salt,omitempty"`
+}
+
+// rule is a built redaction rule
+type rule struct {
+	name       string
+	regex      *regexp.Regexp
+	group      int
+	validate   func(string) bool
+	mode       string
+	mask       string
+	keepLast   int
+	salt       []byte
+	redactions prometheus.Counter
+}
+
+// build builds a rule from its configuration
+func (c RuleConfig) build(operatorID string) (*rule, error) {
+	r := &rule{
+		name:     c.Name,
+		mode:     c.Mode,
+		mask:     c.Mask,
+		keepLast: c.KeepLast,
+		salt:     []byte(c.Salt),
+	}
+
+	switch {
+	case c.Detector != "" && c.Regex != "":
+		return nil, fmt.Errorf("only one of 'detector' or 'regex' can be defined")
+	case c.Detector != "":
+		d, ok := detectors[c.Detector]
+		if !ok {
+			return nil, fmt.Errorf("unknown detector '%s'", c.Detector)
+		}
+		r.regex, r.validate = d.regex, d.validate
+		if r.name == "" {
+			r.name = c.Detector
+		}
+	case c.Regex != "":
+		regex, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("compile regex: %s", err)
+		}
+		r.regex = regex
+		if r.name == "" {
+			return nil, fmt.Errorf("a rule with a 'regex' requires a 'name'")
+		}
+	default:
+		return nil, fmt.Errorf("one of 'detector' or 'regex' must be defined")
+	}
+
+	r.group = r.regex.SubexpIndex(redactGroup)
+	if r.group == -1 {
+		r.group = 0
+	}
+
+	switch r.mode {
+	case "":
+		r.mode = maskMode
+		fallthrough
+	case maskMode:
+		if r.mask == "" {
+			r.mask = defaultMask
+		}
+	case keepLastMode:
+		if r.keepLast <= 0 {
+			return nil, fmt.Errorf("mode 'keep_last' requires a 'keep_last' greater than zero")
+		}
+	case hmacMode:
+		if len(r.salt) == 0 {
+			return nil, fmt.Errorf("mode 'hmac' requires a 'salt'")
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode '%s', expected 'mask', 'keep_last' or 'hmac'", r.mode)
+	}
+
+	r.redactions = metrics.Redactions.WithLabelValues(operatorID, r.name)
+	return r, nil
+}
+
+// redact replaces every value found by the rule in a string, and returns the number of values replaced
+func (r *rule) redact(str string) (string, int) {
+	matches := r.regex.FindAllStringSubmatchIndex(str, -1)
+	if len(matches) == 0 {
+		return str, 0
+	}
+
+	var b strings.Builder
+	last, count := 0, 0
+	for _, match := range matches {
+		start, end := match[2*r.group], match[2*r.group+1]
+		if start < 0 || start == end {
+			continue
+		}
+
+		value := str[start:end]
+		if r.validate != nil && !r.validate(value) {
+			continue
+		}
+
+		b.WriteString(str[last:start])
+		b.WriteString(r.replace(value))
+		last = end
+		count++
+	}
+
+	if count == 0 {
+		return str, 0
+	}
+
+	b.WriteString(str[last:])
+	r.redactions.Add(float64(count))
+	return b.String(), count
+}
+
+// replace returns the replacement of a value, using the mode of the rule
+func (r *rule) replace(value string) string {
+	switch r.mode {
+	case keepLastMode:
+		length := utf8.RuneCountInString(value)
+		if length <= r.keepLast {
+			return strings.Repeat("*", length)
+		}
+		runes := []rune(value)
+		return strings.Repeat("*", length-r.keepLast) + string(runes[length-r.keepLast:])
+	case hmacMode:
+		mac := hmac.New(sha256.New, r.salt)
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))
+	default:
+		return r.mask
+	}
+}
+
+// isCreditCard returns true if the digits of a value are a valid card number, using the Luhn checksum
+func isCreditCard(value string) bool {
+	digits := make([]int, 0, len(value))
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := range digits {
+		digit := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// isIPv6 returns true if a value is an IPv6 address
+func isIPv6(value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && strings.Contains(value, ":")
+}
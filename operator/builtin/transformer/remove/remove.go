@@ -57,8 +57,14 @@ func (p *RemoveOperator) Process(ctx context.Context, entry *entry.Entry) error
 }
 
 // Transform will apply the restructure operations to an entry
-func (p *RemoveOperator) Transform(entry *entry.Entry) error {
-	_, exist := entry.Delete(p.Field)
+func (p *RemoveOperator) Transform(e *entry.Entry) error {
+	// A selector removes every field it matches, and matching no fields is not an error
+	if _, ok := p.Field.FieldInterface.(entry.SelectorField); ok {
+		e.Delete(p.Field)
+		return nil
+	}
+
+	_, exist := e.Delete(p.Field)
 	if !exist {
 		return fmt.Errorf("remove: field does not exist")
 	}
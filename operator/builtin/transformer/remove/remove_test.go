@@ -97,14 +97,14 @@ func TestProcessAndBuild(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"key": "val",
 				}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{}
+				e.Labels = map[string]interface{}{}
 				return e
 			},
 			false,
@@ -118,14 +118,83 @@ func TestProcessAndBuild(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key": "val",
 				}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{}
+				e.Resource = map[string]interface{}{}
+				return e
+			},
+			false,
+		},
+		{
+			"remove_selector",
+			func() *RemoveOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewRecordSelector("**", "password")
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"password": "secret",
+					"nested": map[string]interface{}{
+						"password": "secret",
+						"users": []interface{}{
+							map[string]interface{}{"name": "a", "password": "secret"},
+						},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"nested": map[string]interface{}{
+						"users": []interface{}{
+							map[string]interface{}{"name": "a"},
+						},
+					},
+				}
+				return e
+			},
+			false,
+		},
+		{
+			"remove_selector_no_match",
+			func() *RemoveOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewRecordSelector("*", "password")
+				return cfg
+			}(),
+			newTestEntry,
+			newTestEntry,
+			false,
+		},
+		{
+			"remove_label_selector",
+			func() *RemoveOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Field = entry.NewLabelSelector("k8s_*")
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Labels = map[string]interface{}{
+					"k8s_pod":       "pod",
+					"k8s_namespace": "namespace",
+					"app":           "app",
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Labels = map[string]interface{}{
+					"app": "app",
+				}
 				return e
 			},
 			false,
@@ -101,12 +101,17 @@ func (p *RetainOperator) Transform(e *entry.Entry) error {
 	}
 
 	for _, field := range p.Fields {
-		val, ok := e.Get(field)
-		if !ok {
+		// A selector retains every field it matches
+		if selector, ok := field.FieldInterface.(entry.SelectorField); ok {
+			for _, match := range selector.Select(e) {
+				if err := retainField(e, newEntry, match); err != nil {
+					return err
+				}
+			}
 			continue
 		}
-		err := newEntry.Set(field, val)
-		if err != nil {
+
+		if err := retainField(e, newEntry, field); err != nil {
 			return err
 		}
 	}
@@ -114,3 +119,17 @@ func (p *RetainOperator) Transform(e *entry.Entry) error {
 	*e = *newEntry
 	return nil
 }
+
+// retainField copies the value of a field to the new entry, if it exists.
+// Record fields keep the lists they pass through.
+func retainField(e, newEntry *entry.Entry, field entry.Field) error {
+	if recordField, ok := field.FieldInterface.(entry.RecordField); ok {
+		return recordField.Copy(e, newEntry)
+	}
+
+	val, ok := e.Get(field)
+	if !ok {
+		return nil
+	}
+	return newEntry.Set(field, val)
+}
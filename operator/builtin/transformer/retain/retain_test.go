@@ -171,14 +171,14 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"key": "val",
 				}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"key": "val",
 				}
 				return e
@@ -195,7 +195,7 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 					"key3": "val",
@@ -204,7 +204,7 @@ func TestBuildAndProcess(t *testing.T) {
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 				}
@@ -221,14 +221,14 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key": "val",
 				}
 				return e
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key": "val",
 				}
 				return e
@@ -245,7 +245,7 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 					"key3": "val",
@@ -254,7 +254,7 @@ func TestBuildAndProcess(t *testing.T) {
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 				}
@@ -273,11 +273,11 @@ func TestBuildAndProcess(t *testing.T) {
 			}(),
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "val",
 					"key2": "val",
 				}
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"key3": "val",
 					"key4": "val",
 				}
@@ -285,10 +285,10 @@ func TestBuildAndProcess(t *testing.T) {
 			},
 			func() *entry.Entry {
 				e := newTestEntry()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "val",
 				}
-				e.Labels = map[string]string{
+				e.Labels = map[string]interface{}{
 					"key3": "val",
 				}
 				e.Record = map[string]interface{}{
@@ -312,6 +312,88 @@ func TestBuildAndProcess(t *testing.T) {
 				return e
 			},
 		},
+		{
+			"retain_label_selector",
+			false,
+			func() *RetainOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = append(cfg.Fields, entry.NewLabelSelector("k8s_*"))
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Labels = map[string]interface{}{
+					"k8s_pod":       "pod",
+					"k8s_namespace": "namespace",
+					"app":           "app",
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Labels = map[string]interface{}{
+					"k8s_pod":       "pod",
+					"k8s_namespace": "namespace",
+				}
+				return e
+			},
+		},
+		{
+			"retain_record_selector",
+			false,
+			func() *RetainOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = append(cfg.Fields, entry.NewRecordSelector("nested*"))
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"key":          "val",
+					"nested":       map[string]interface{}{"nestedkey": "nestedval"},
+					"nested_other": "other",
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"nested":       map[string]interface{}{"nestedkey": "nestedval"},
+					"nested_other": "other",
+				}
+				return e
+			},
+		},
+		{
+			"retain_record_selector_in_list",
+			false,
+			func() *RetainOperatorConfig {
+				cfg := defaultCfg()
+				cfg.Fields = append(cfg.Fields, entry.NewRecordSelector("items", "*", "name"))
+				return cfg
+			}(),
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"key": "val",
+					"items": []interface{}{
+						map[string]interface{}{"name": "first", "id": 1},
+						map[string]interface{}{"name": "second", "id": 2},
+					},
+				}
+				return e
+			},
+			func() *entry.Entry {
+				e := newTestEntry()
+				e.Record = map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"name": "first"},
+						map[string]interface{}{"name": "second"},
+					},
+				}
+				return e
+			},
+		},
 	}
 	for _, tc := range cases {
 		t.Run("BuildandProcess/"+tc.name, func(t *testing.T) {
@@ -3,12 +3,15 @@ package router
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/antonmedv/expr"
 	"github.com/antonmedv/expr/vm"
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
 	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/operator/helper"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -83,7 +86,9 @@ func (c RouterOperatorConfig) Build(bc operator.BuildContext) ([]operator.Operat
 // RouterOperator is an operator that routes entries based on matching expressions
 type RouterOperator struct {
 	helper.BasicOperator
-	routes []*RouterOperatorRoute
+	routes     []*RouterOperatorRoute
+	outputsMux sync.RWMutex
+	entriesOut prometheus.Counter
 }
 
 // RouterOperatorRoute is a route on a router operator
@@ -92,6 +97,7 @@ type RouterOperatorRoute struct {
 	Expression      *vm.Program
 	OutputIDs       helper.OutputIDs
 	OutputOperators []operator.Operator
+	entriesIn       []prometheus.Counter
 }
 
 // CanProcess will always return true for a router operator
@@ -104,6 +110,9 @@ func (p *RouterOperator) Process(ctx context.Context, entry *entry.Entry) error
 	env := helper.GetExprEnv(entry)
 	defer helper.PutExprEnv(env)
 
+	p.outputsMux.RLock()
+	defer p.outputsMux.RUnlock()
+
 	for _, route := range p.routes {
 		matches, err := vm.Run(route.Expression, env)
 		if err != nil {
@@ -118,7 +127,13 @@ func (p *RouterOperator) Process(ctx context.Context, entry *entry.Entry) error
 				return err
 			}
 
-			for _, output := range route.OutputOperators {
+			if p.entriesOut != nil {
+				p.entriesOut.Inc()
+			}
+			for i, output := range route.OutputOperators {
+				if i < len(route.entriesIn) {
+					route.entriesIn[i].Inc()
+				}
 				_ = output.Process(ctx, entry)
 			}
 			break
@@ -135,6 +150,9 @@ func (p *RouterOperator) CanOutput() bool {
 
 // Outputs will return all connected operators.
 func (p *RouterOperator) Outputs() []operator.Operator {
+	p.outputsMux.RLock()
+	defer p.outputsMux.RUnlock()
+
 	outputs := make([]operator.Operator, 0, len(p.routes))
 	for _, route := range p.routes {
 		outputs = append(outputs, route.OutputOperators...)
@@ -144,13 +162,25 @@ func (p *RouterOperator) Outputs() []operator.Operator {
 
 // SetOutputs will set the outputs of the router operator.
 func (p *RouterOperator) SetOutputs(operators []operator.Operator) error {
+	routeOutputs := make([][]operator.Operator, 0, len(p.routes))
 	for _, route := range p.routes {
 		outputOperators, err := p.findOperators(operators, route.OutputIDs)
 		if err != nil {
 			return fmt.Errorf("failed to set outputs on route: %s", err)
 		}
-		route.OutputOperators = outputOperators
+		routeOutputs = append(routeOutputs, outputOperators)
+	}
+
+	p.outputsMux.Lock()
+	p.entriesOut = metrics.EntriesOut.WithLabelValues(p.ID())
+	for i, route := range p.routes {
+		route.OutputOperators = routeOutputs[i]
+		route.entriesIn = make([]prometheus.Counter, 0, len(routeOutputs[i]))
+		for _, output := range routeOutputs[i] {
+			route.entriesIn = append(route.entriesIn, metrics.EntriesIn.WithLabelValues(output.ID()))
+		}
 	}
+	p.outputsMux.Unlock()
 
 	return nil
 }
@@ -32,7 +32,7 @@ func TestRouterOperator(t *testing.T) {
 		routes         []*RouterOperatorRouteConfig
 		defaultOutput  helper.OutputIDs
 		expectedCounts map[string]int
-		expectedLabels map[string]string
+		expectedLabels map[string]interface{}
 	}{
 		{
 			"DefaultRoute",
@@ -110,7 +110,7 @@ func TestRouterOperator(t *testing.T) {
 			},
 			nil,
 			map[string]int{"output2": 1},
-			map[string]string{
+			map[string]interface{}{
 				"label-key": "label-value",
 			},
 		},
@@ -187,7 +187,7 @@ func TestRouterOperator(t *testing.T) {
 			op := ops[0]
 
 			results := map[string]int{}
-			var labels map[string]string
+			var labels map[string]interface{}
 
 			mock1 := testutil.NewMockOperator("$.output1")
 			mock1.On("Process", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
@@ -0,0 +1,248 @@
+package sample
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"go.uber.org/zap"
+)
+
+func init() {
+	operator.Register("sample", func() operator.Builder { return NewSampleConfig("") })
+}
+
+var randFloat = rand.Float64 // allow override for testing
+
+// NewSampleConfig creates a new sample config with default values
+func NewSampleConfig(operatorID string) *SampleConfig {
+	return &SampleConfig{
+		TransformerConfig: helper.NewTransformerConfig(operatorID, "sample"),
+		Rate:              1,
+		RateField:         entry.NewLabelField("sample_rate"),
+	}
+}
+
+// SampleConfig is the configuration of a sample operator
+type SampleConfig struct {
+	helper.TransformerConfig `yaml:",inline"`
+
+	Key       string       `json:"key,omitempty"        yaml:"key,omitempty"`
+	Rate      float64      `json:"rate"                 yaml:"rate"`
+	Rules     []RuleConfig `json:"rules,omitempty"      yaml:"rules,omitempty"`
+	RateField entry.Field  `json:"rate_field,omitempty" yaml:"rate_field,omitempty"`
+}
+
+// RuleConfig is the configuration of a rule that applies a different rate to some entries
+type RuleConfig struct {
+	MinSeverity interface{} `json:"min_severity,omitempty" yaml:"min_severity,omitempty"`
+	Expression  string      `json:"expr,omitempty"         yaml:"expr,omitempty"`
+	Rate        *float64    `json:"rate"                   yaml:"rate"`
+}
+
+// Build will build a sample operator from the supplied configuration
+func (c SampleConfig) Build(context operator.BuildContext) ([]operator.Operator, error) {
+	transformerOperator, err := c.TransformerConfig.Build(context)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Key == "" {
+		return nil, fmt.Errorf("sample: missing required field 'key'")
+	}
+
+	key, err := expr.Compile(c.Key, expr.AllowUndefinedVariables())
+	if err != nil {
+		return nil, fmt.Errorf("sample: failed to compile key '%s': %s", c.Key, err)
+	}
+
+	if err := validateRate(c.Rate); err != nil {
+		return nil, fmt.Errorf("sample: %s", err)
+	}
+
+	rules := make([]rule, 0, len(c.Rules))
+	for i, ruleConfig := range c.Rules {
+		r, err := ruleConfig.build()
+		if err != nil {
+			return nil, fmt.Errorf("sample: rule %d: %s", i, err)
+		}
+		rules = append(rules, r)
+	}
+
+	sampleOperator := &SampleOperator{
+		TransformerOperator: transformerOperator,
+		key:                 key,
+		rate:                c.Rate,
+		rules:               rules,
+		rateField:           c.RateField,
+	}
+
+	return []operator.Operator{sampleOperator}, nil
+}
+
+// rule is a built sample rule. An entry matches a rule if it matches all of its conditions.
+type rule struct {
+	minSeverity *entry.Severity
+	expression  *vm.Program
+	rate        float64
+}
+
+// build builds a rule from its configuration
+func (c RuleConfig) build() (rule, error) {
+	r := rule{}
+
+	if c.MinSeverity == nil && c.Expression == "" {
+		return r, fmt.Errorf("one of 'min_severity' or 'expr' must be defined")
+	}
+
+	if c.MinSeverity != nil {
+		severity, err := helper.ParseSeverity(c.MinSeverity)
+		if err != nil {
+			return r, fmt.Errorf("parse min_severity: %s", err)
+		}
+		r.minSeverity = &severity
+	}
+
+	if c.Expression != "" {
+		expression, err := expr.Compile(c.Expression, expr.AsBool(), expr.AllowUndefinedVariables())
+		if err != nil {
+			return r, fmt.Errorf("failed to compile expr '%s': %s", c.Expression, err)
+		}
+		r.expression = expression
+	}
+
+	if c.Rate == nil {
+		return r, fmt.Errorf("missing required field 'rate'")
+	}
+	if err := validateRate(*c.Rate); err != nil {
+		return r, err
+	}
+	r.rate = *c.Rate
+
+	return r, nil
+}
+
+// matches returns true if an entry matches every condition of the rule
+func (r rule) matches(e *entry.Entry, env map[string]interface{}) (bool, error) {
+	if r.minSeverity != nil && e.Severity < *r.minSeverity {
+		return false, nil
+	}
+
+	if r.expression != nil {
+		matches, err := vm.Run(r.expression, env)
+		if err != nil {
+			return false, err
+		}
+		return matches.(bool), nil
+	}
+
+	return true, nil
+}
+
+// validateRate returns an error if a rate is not a fraction between 0 and 1
+func validateRate(rate float64) error {
+	if rate < 0 || rate > 1 {
+		return fmt.Errorf("rate must be a number between 0 and 1")
+	}
+	return nil
+}
+
+// SampleOperator is an operator that keeps a fraction of entries, keeping or dropping all entries with the same key together
+type SampleOperator struct {
+	helper.TransformerOperator
+
+	key       *vm.Program
+	rate      float64
+	rules     []rule
+	rateField entry.Field
+}
+
+// Process will keep or drop an entry, based on the hash of its key and its sample rate
+func (s *SampleOperator) Process(ctx context.Context, e *entry.Entry) error {
+	skip, err := s.Skip(ctx, e)
+	if err != nil {
+		return s.HandleEntryError(ctx, e, err)
+	}
+	if skip {
+		s.Write(ctx, e)
+		return nil
+	}
+
+	keep, rate, err := s.sample(e)
+	if err != nil {
+		return s.HandleEntryError(ctx, e, err)
+	}
+	if !keep {
+		s.CountDropped()
+		return nil
+	}
+
+	if s.rateField.FieldInterface != nil {
+		if err := e.Set(s.rateField, rate); err != nil {
+			s.Errorw("Failed to set sample rate", zap.Error(err), zap.String("field", s.rateField.String()))
+		}
+	}
+
+	s.Write(ctx, e)
+	return nil
+}
+
+// sample decides whether an entry is kept, and returns the rate that was applied to it
+func (s *SampleOperator) sample(e *entry.Entry) (bool, float64, error) {
+	env := helper.GetExprEnv(e)
+	defer helper.PutExprEnv(env)
+
+	rate := s.rate
+	for i, r := range s.rules {
+		matches, err := r.matches(e, env)
+		if err != nil {
+			return false, 0, fmt.Errorf("sample: evaluate rule %d: %s", i, err)
+		}
+		if matches {
+			rate = r.rate
+			break
+		}
+	}
+
+	switch rate {
+	case 0:
+		return false, rate, nil
+	case 1:
+		return true, rate, nil
+	}
+
+	key, err := vm.Run(s.key, env)
+	if err != nil {
+		return false, 0, fmt.Errorf("sample: evaluate key: %s", err)
+	}
+
+	// Entries without a key cannot be sampled together, so each of them is sampled on its own
+	if key == nil || key == "" {
+		return randFloat() < rate, rate, nil
+	}
+
+	return hashFraction(entry.AttributeString(key)) < rate, rate, nil
+}
+
+// hashFraction hashes a key to a fraction between 0 and 1. The hash does not depend on the process,
+// so that every instance of the operator keeps the same keys.
+func hashFraction(key string) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum64()
+
+	// Mix the bits, since similar keys such as sequential IDs have similar FNV hashes
+	sum ^= sum >> 30
+	sum *= 0xbf58476d1ce4e5b9
+	sum ^= sum >> 27
+	sum *= 0x94d049bb133111eb
+	sum ^= sum >> 31
+
+	return float64(sum>>11) / (1 << 53)
+}
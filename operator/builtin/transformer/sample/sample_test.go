@@ -0,0 +1,397 @@
+package sample
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type testCase struct {
+	name      string
+	expectErr bool
+	op        *SampleConfig
+	input     func() *entry.Entry
+	output    func() *entry.Entry
+}
+
+func newTestEntry(traceID string, severity entry.Severity, route string) *entry.Entry {
+	e := entry.New()
+	e.Timestamp = time.Unix(1586632809, 0)
+	e.Severity = severity
+	e.Record = map[string]interface{}{
+		"trace_id": traceID,
+		"route":    route,
+	}
+	return e
+}
+
+// newSampledEntry creates an entry that was kept at a sample rate
+func newSampledEntry(traceID string, severity entry.Severity, route string, sampleRate float64) *entry.Entry {
+	e := newTestEntry(traceID, severity, route)
+	e.Labels = map[string]interface{}{"sample_rate": sampleRate}
+	return e
+}
+
+func rate(r float64) *float64 {
+	return &r
+}
+
+// Test building and processing a SampleConfig. An entry that is not kept has no output.
+func TestBuildAndProcess(t *testing.T) {
+	cases := []testCase{
+		{
+			"rate_one",
+			false,
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 1
+				return cfg
+			}(),
+			func() *entry.Entry { return newTestEntry("trace-0", entry.Info, "/") },
+			func() *entry.Entry { return newSampledEntry("trace-0", entry.Info, "/", 1) },
+		},
+		{
+			"rate_zero",
+			false,
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0
+				return cfg
+			}(),
+			func() *entry.Entry { return newTestEntry("trace-0", entry.Info, "/") },
+			nil,
+		},
+		{
+			"severity_rule",
+			false,
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0
+				cfg.Rules = []RuleConfig{{MinSeverity: "warn", Rate: rate(1)}}
+				return cfg
+			}(),
+			func() *entry.Entry { return newTestEntry("trace-0", entry.Error, "/") },
+			func() *entry.Entry { return newSampledEntry("trace-0", entry.Error, "/", 1) },
+		},
+		{
+			"numeric_severity_rule",
+			false,
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0
+				cfg.Rules = []RuleConfig{{MinSeverity: 50, Rate: rate(1)}}
+				return cfg
+			}(),
+			func() *entry.Entry { return newTestEntry("trace-0", entry.Error, "/") },
+			func() *entry.Entry { return newSampledEntry("trace-0", entry.Error, "/", 1) },
+		},
+		{
+			"severity_rule_below",
+			false,
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0
+				cfg.Rules = []RuleConfig{{MinSeverity: "warn", Rate: rate(1)}}
+				return cfg
+			}(),
+			func() *entry.Entry { return newTestEntry("trace-0", entry.Info, "/") },
+			nil,
+		},
+		{
+			"expr_rule",
+			false,
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 1
+				cfg.Rules = []RuleConfig{{Expression: `$record.route == "/health"`, Rate: rate(0)}}
+				return cfg
+			}(),
+			func() *entry.Entry { return newTestEntry("trace-0", entry.Info, "/health") },
+			nil,
+		},
+		{
+			"skip",
+			false,
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 0
+				cfg.IfExpr = `$record.route != "/checkout"`
+				return cfg
+			}(),
+			func() *entry.Entry { return newTestEntry("trace-0", entry.Info, "/checkout") },
+			func() *entry.Entry { return newTestEntry("trace-0", entry.Info, "/checkout") },
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.op
+			cfg.OutputIDs = []string{"fake"}
+			cfg.OnError = "drop"
+			ops, err := cfg.Build(testutil.NewBuildContext(t))
+			require.NoError(t, err)
+			op := ops[0]
+
+			sample := op.(*SampleOperator)
+			fake := testutil.NewFakeOutput(t)
+			sample.SetOutputs([]operator.Operator{fake})
+			val := tc.input()
+			err = sample.Process(context.Background(), val)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			if tc.output == nil {
+				fake.ExpectNoEntry(t, 10*time.Millisecond)
+				return
+			}
+			fake.ExpectEntry(t, tc.output())
+		})
+	}
+}
+
+func defaultCfg() *SampleConfig {
+	cfg := NewSampleConfig("sample")
+	cfg.Key = `$record.trace_id`
+	return cfg
+}
+
+func TestBuildInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		op       *SampleConfig
+		expected string
+	}{
+		{
+			"missing_key",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Key = ""
+				return cfg
+			}(),
+			"missing required field 'key'",
+		},
+		{
+			"invalid_key",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Key = `$record.trace_id ==`
+				return cfg
+			}(),
+			"failed to compile key",
+		},
+		{
+			"negative_rate",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = -0.1
+				return cfg
+			}(),
+			"rate must be a number between 0 and 1",
+		},
+		{
+			"rate_above_one",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rate = 1.5
+				return cfg
+			}(),
+			"rate must be a number between 0 and 1",
+		},
+		{
+			"invalid_severity_rule",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rules = []RuleConfig{{MinSeverity: "loud", Rate: rate(1)}}
+				return cfg
+			}(),
+			"parse min_severity",
+		},
+		{
+			"invalid_expr_rule",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rules = []RuleConfig{{Expression: `$record.route ==`, Rate: rate(0.01)}}
+				return cfg
+			}(),
+			"failed to compile expr",
+		},
+		{
+			"rule_without_condition",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rules = []RuleConfig{{Rate: rate(0.01)}}
+				return cfg
+			}(),
+			"one of 'min_severity' or 'expr' must be defined",
+		},
+		{
+			"rule_without_rate",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rules = []RuleConfig{{MinSeverity: "error"}}
+				return cfg
+			}(),
+			"missing required field 'rate'",
+		},
+		{
+			"rule_with_invalid_rate",
+			func() *SampleConfig {
+				cfg := defaultCfg()
+				cfg.Rules = []RuleConfig{{MinSeverity: "error", Rate: rate(2)}}
+				return cfg
+			}(),
+			"rule 0: rate must be a number between 0 and 1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.op.Build(testutil.NewBuildContext(t))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.expected)
+		})
+	}
+}
+
+// countKept processes an entry for each of n trace IDs, and returns the number of entries that were kept
+func countKept(t *testing.T, sample *SampleOperator, fake *testutil.FakeOutput, n int, severity entry.Severity, route string) int {
+	for i := 0; i < n; i++ {
+		require.NoError(t, sample.Process(context.Background(), newTestEntry(fmt.Sprintf("trace-%d", i), severity, route)))
+	}
+	return len(fake.ReceiveAll())
+}
+
+func TestSampleConsistentByKey(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Rate = 0.5
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	sample := ops[0].(*SampleOperator)
+	fake := testutil.NewFakeOutput(t)
+	sample.SetOutputs([]operator.Operator{fake})
+
+	for i := 0; i < 100; i++ {
+		traceID := fmt.Sprintf("trace-%d", i)
+		expected := hashFraction(traceID) < 0.5
+
+		for j := 0; j < 3; j++ {
+			require.NoError(t, sample.Process(context.Background(), newTestEntry(traceID, entry.Info, "/")))
+			if !expected {
+				continue
+			}
+
+			e := fake.ReceiveEntry(t)
+			require.Equal(t, traceID, e.Record.(map[string]interface{})["trace_id"])
+			require.Equal(t, 0.5, e.Labels["sample_rate"])
+		}
+	}
+
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+}
+
+func TestSampleRate(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Rate = 0.1
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	sample := ops[0].(*SampleOperator)
+	fake := testutil.NewFakeOutput(t)
+	fake.Received = make(chan *entry.Entry, 10000)
+	sample.SetOutputs([]operator.Operator{fake})
+
+	kept := countKept(t, sample, fake, 10000, entry.Info, "/")
+	require.InDelta(t, 1000, kept, 150)
+}
+
+func TestSampleRules(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Rate = 0.5
+	cfg.Rules = []RuleConfig{
+		{MinSeverity: "warn", Rate: rate(1)},
+		{Expression: `$record.route == "/health"`, Rate: rate(0)},
+	}
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	sample := ops[0].(*SampleOperator)
+	fake := testutil.NewFakeOutput(t)
+	fake.Received = make(chan *entry.Entry, 10000)
+	sample.SetOutputs([]operator.Operator{fake})
+
+	require.Equal(t, 100, countKept(t, sample, fake, 100, entry.Error, "/health"))
+	require.Equal(t, 0, countKept(t, sample, fake, 100, entry.Info, "/health"))
+	require.InDelta(t, 500, countKept(t, sample, fake, 1000, entry.Info, "/"), 100)
+
+	require.NoError(t, sample.Process(context.Background(), newTestEntry("trace-0", entry.Warning, "/")))
+	e := fake.ReceiveEntry(t)
+	require.Equal(t, 1.0, e.Labels["sample_rate"])
+}
+
+func TestSampleMissingKey(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Key = `$record.request_id`
+	cfg.Rate = 0.5
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	sample := ops[0].(*SampleOperator)
+	fake := testutil.NewFakeOutput(t)
+	sample.SetOutputs([]operator.Operator{fake})
+
+	defer func() { randFloat = rand.Float64 }()
+	randFloat = func() float64 { return 0.4 }
+	require.NoError(t, sample.Process(context.Background(), newTestEntry("trace-0", entry.Info, "/")))
+	fake.ReceiveEntry(t)
+
+	randFloat = func() float64 { return 0.6 }
+	require.NoError(t, sample.Process(context.Background(), newTestEntry("trace-0", entry.Info, "/")))
+	fake.ExpectNoEntry(t, 10*time.Millisecond)
+}
+
+func TestHashFraction(t *testing.T) {
+	require.Equal(t, hashFraction("trace-1"), hashFraction("trace-1"))
+	require.NotEqual(t, hashFraction("trace-1"), hashFraction("trace-2"))
+
+	for i := 0; i < 1000; i++ {
+		fraction := hashFraction(fmt.Sprintf("trace-%d", i))
+		require.True(t, fraction >= 0 && fraction < 1)
+	}
+}
+
+func TestSampleDropMetrics(t *testing.T) {
+	cfg := NewSampleConfig("sample_metrics")
+	cfg.Key = `$record.trace_id`
+	cfg.OutputIDs = []string{"fake"}
+	cfg.Rate = 0.5
+	ops, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	sample := ops[0].(*SampleOperator)
+	fake := testutil.NewFakeOutput(t)
+	sample.SetOutputs([]operator.Operator{fake})
+
+	dropped := 0
+	for i := 0; i < 100; i++ {
+		traceID := fmt.Sprintf("trace-%d", i)
+		if hashFraction(traceID) >= 0.5 {
+			dropped++
+		}
+		require.NoError(t, sample.Process(context.Background(), newTestEntry(traceID, entry.Info, "/")))
+	}
+	require.Len(t, fake.ReceiveAll(), 100-dropped)
+	require.Equal(t, float64(dropped), promtestutil.ToFloat64(metrics.Dropped.WithLabelValues("$.sample_metrics")))
+	require.Equal(t, float64(0), promtestutil.ToFloat64(metrics.Errors.WithLabelValues("$.sample_metrics")))
+}
@@ -0,0 +1,18 @@
+package operator
+
+// Drainer is an optional interface implemented by operators that hold entries they
+// have accepted but not yet delivered, like outputs with a buffer. It allows the
+// pipeline to wait for those entries to be delivered before stopping the operator.
+type Drainer interface {
+	// Pending returns the number of entries the operator holds.
+	Pending() int
+}
+
+// GetPending returns the number of entries held by an operator, or 0
+// if the operator does not hold entries.
+func GetPending(op Operator) int {
+	if drainer, ok := op.(Drainer); ok {
+		return drainer.Pending()
+	}
+	return 0
+}
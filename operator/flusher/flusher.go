@@ -2,11 +2,16 @@ package flusher
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/observiq/stanza/metrics"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"golang.org/x/sync/semaphore"
 )
@@ -21,30 +26,44 @@ type Config struct {
 	// Defaults to 16.
 	MaxConcurrent int `json:"max_concurrent" yaml:"max_concurrent"`
 
+	// UnhealthyAfter is how long flushes must fail continuously before the
+	// operator reports itself as unhealthy. Defaults to 5 minutes.
+	UnhealthyAfter helper.Duration `json:"unhealthy_after" yaml:"unhealthy_after"`
+
 	// TODO configurable retry
 }
 
 // NewConfig creates a new default flusher config
 func NewConfig() Config {
 	return Config{
-		MaxConcurrent: 16,
+		MaxConcurrent:  16,
+		UnhealthyAfter: helper.NewDuration(5 * time.Minute),
 	}
 }
 
-// Build uses a Config to build a new Flusher
-func (c *Config) Build(logger *zap.SugaredLogger) *Flusher {
+// Build uses a Config to build a new Flusher for the operator identified by operatorID
+func (c *Config) Build(logger *zap.SugaredLogger, operatorID string) *Flusher {
 	maxConcurrent := c.MaxConcurrent
 	if maxConcurrent == 0 {
 		maxConcurrent = 16
 	}
 
+	unhealthyAfter := c.UnhealthyAfter.Raw()
+	if unhealthyAfter == 0 {
+		unhealthyAfter = 5 * time.Minute
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Flusher{
-		ctx:           ctx,
-		cancel:        cancel,
-		sem:           semaphore.NewWeighted(int64(maxConcurrent)),
-		SugaredLogger: logger,
+		ctx:            ctx,
+		cancel:         cancel,
+		sem:            semaphore.NewWeighted(int64(maxConcurrent)),
+		unhealthyAfter: unhealthyAfter,
+		flushDuration:  metrics.FlushDuration.WithLabelValues(operatorID),
+		retries:        metrics.FlushRetries.WithLabelValues(operatorID),
+		dropped:        metrics.FlushDropped.WithLabelValues(operatorID),
+		SugaredLogger:  logger,
 	}
 }
 
@@ -52,10 +71,19 @@ func (c *Config) Build(logger *zap.SugaredLogger) *Flusher {
 // retry behavior, and cancellation.
 type Flusher struct {
 	chunkIDCounter uint64
-	ctx            context.Context
-	cancel         context.CancelFunc
-	sem            *semaphore.Weighted
-	wg             sync.WaitGroup
+
+	// failingSince is the time in unix nanoseconds at which flushes started failing
+	// continuously, or zero if the last flush succeeded
+	failingSince   int64
+	unhealthyAfter time.Duration
+
+	ctx           context.Context
+	cancel        context.CancelFunc
+	sem           *semaphore.Weighted
+	wg            sync.WaitGroup
+	flushDuration prometheus.Observer
+	retries       prometheus.Counter
+	dropped       prometheus.Counter
 	*zap.SugaredLogger
 }
 
@@ -93,16 +121,22 @@ func (f *Flusher) flushWithRetry(ctx context.Context, flush FlushFunc) {
 	chunkID := f.nextChunkID()
 	b := newExponentialBackoff()
 	for {
+		start := time.Now()
 		err := flush(ctx)
 		if err == nil {
+			f.flushDuration.Observe(time.Since(start).Seconds())
+			atomic.StoreInt64(&f.failingSince, 0)
 			return
 		}
+		atomic.CompareAndSwapInt64(&f.failingSince, 0, start.UnixNano())
 
 		waitTime := b.NextBackOff()
 		if waitTime == b.Stop {
 			f.Errorw("Reached max backoff time during chunk flush retry. Dropping logs in chunk", "chunk_id", chunkID)
+			f.dropped.Inc()
 			return
 		}
+		f.retries.Inc()
 
 		// Only log the error if the context hasn't been canceled
 		// This protects from flooding the logs with "context canceled" messages on clean shutdown
@@ -121,6 +155,25 @@ func (f *Flusher) flushWithRetry(ctx context.Context, flush FlushFunc) {
 	}
 }
 
+// Status will report the flusher as unhealthy once flushes have been
+// failing continuously for longer than the configured window.
+func (f *Flusher) Status() operator.Status {
+	failingSince := atomic.LoadInt64(&f.failingSince)
+	if failingSince == 0 {
+		return operator.Status{Healthy: true}
+	}
+
+	since := time.Unix(0, failingSince)
+	if time.Since(since) < f.unhealthyAfter {
+		return operator.Status{Healthy: true}
+	}
+
+	return operator.Status{
+		Healthy: false,
+		Message: fmt.Sprintf("flushes have been failing since %s", since.UTC().Format(time.RFC3339)),
+	}
+}
+
 func (f *Flusher) nextChunkID() uint64 {
 	return atomic.AddUint64(&f.chunkIDCounter, 1)
 }
@@ -7,6 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/observiq/stanza/metrics"
+	"github.com/observiq/stanza/operator/helper"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 )
@@ -18,7 +21,7 @@ func TestFlusher(t *testing.T) {
 
 	outChan := make(chan struct{}, 100)
 	flusherCfg := NewConfig()
-	flusher := flusherCfg.Build(zaptest.NewLogger(t).Sugar())
+	flusher := flusherCfg.Build(zaptest.NewLogger(t).Sugar(), "$.test")
 
 	failed := errors.New("test failure")
 	for i := 0; i < 100; i++ {
@@ -47,7 +50,7 @@ func TestMaxElapsedTime(t *testing.T) {
 	maxElapsedTime = 1 * time.Second
 
 	flusherCfg := NewConfig()
-	flusher := flusherCfg.Build(zaptest.NewLogger(t).Sugar())
+	flusher := flusherCfg.Build(zaptest.NewLogger(t).Sugar(), "$.test")
 
 	start := time.Now()
 	flusher.flushWithRetry(context.Background(), func(_ context.Context) error {
@@ -55,3 +58,39 @@ func TestMaxElapsedTime(t *testing.T) {
 	})
 	require.WithinDuration(t, start.Add(maxElapsedTime), time.Now(), maxElapsedTime)
 }
+
+func TestFlusherMetrics(t *testing.T) {
+	flusherCfg := NewConfig()
+	flusher := flusherCfg.Build(zaptest.NewLogger(t).Sugar(), "$.metrics")
+
+	attempts := 0
+	flusher.flushWithRetry(context.Background(), func(_ context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("test failure")
+		}
+		return nil
+	})
+
+	require.Equal(t, float64(2), promtestutil.ToFloat64(metrics.FlushRetries.WithLabelValues("$.metrics")))
+	require.Equal(t, float64(0), promtestutil.ToFloat64(metrics.FlushDropped.WithLabelValues("$.metrics")))
+}
+
+func TestFlusherStatus(t *testing.T) {
+	flusherCfg := NewConfig()
+	flusherCfg.UnhealthyAfter = helper.NewDuration(10 * time.Millisecond)
+	flusher := flusherCfg.Build(zaptest.NewLogger(t).Sugar(), "$.status")
+	require.True(t, flusher.Status().Healthy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	flusher.flushWithRetry(ctx, func(_ context.Context) error {
+		return errors.New("test failure")
+	})
+	require.False(t, flusher.Status().Healthy)
+
+	flusher.flushWithRetry(context.Background(), func(_ context.Context) error {
+		return nil
+	})
+	require.True(t, flusher.Status().Healthy)
+}
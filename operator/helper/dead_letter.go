@@ -0,0 +1,122 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
+	"github.com/observiq/stanza/operator"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DeadLetterErrorLabel is the label holding the error that caused an entry to be dead lettered.
+	DeadLetterErrorLabel = "dead_letter_error"
+
+	// DeadLetterOperatorLabel is the label holding the ID of the operator that dead lettered an entry.
+	DeadLetterOperatorLabel = "dead_letter_operator_id"
+)
+
+// deadLetter holds the output that an operator sends the entries it fails to process to
+type deadLetter struct {
+	operatorID   string
+	outputID     string
+	deadLettered prometheus.Counter
+
+	// output holds the dead letter output, which may be replaced
+	// while the operator is running when the pipeline is reloaded
+	output atomic.Value
+}
+
+// deadLetterOutput is a dead letter output along with the counter of entries sent to it
+type deadLetterOutput struct {
+	operator  operator.Operator
+	entriesIn prometheus.Counter
+}
+
+// newDeadLetter creates a dead letter for the operator identified by operatorID, which sends
+// to the output with the supplied ID, or returns nil if no output ID is supplied
+func newDeadLetter(bc operator.BuildContext, operatorID, outputID string) *deadLetter {
+	if outputID == "" {
+		return nil
+	}
+	return &deadLetter{
+		operatorID:   operatorID,
+		outputID:     bc.PrependNamespace(outputID),
+		deadLettered: metrics.DeadLettered.WithLabelValues(operatorID),
+	}
+}
+
+// setOutput will find the dead letter output among the supplied operators
+func (d *deadLetter) setOutput(operators []operator.Operator) error {
+	if d == nil {
+		return nil
+	}
+
+	for _, op := range operators {
+		if op.ID() != d.outputID {
+			continue
+		}
+
+		if !op.CanProcess() {
+			return fmt.Errorf("dead letter operator '%s' can not process entries", d.outputID)
+		}
+
+		d.output.Store(&deadLetterOutput{
+			operator:  op,
+			entriesIn: metrics.EntriesIn.WithLabelValues(op.ID()),
+		})
+		return nil
+	}
+
+	return fmt.Errorf("dead letter operator '%s' does not exist", d.outputID)
+}
+
+// withOutput returns the supplied outputs along with the dead letter output, if it is not among them
+func (d *deadLetter) withOutput(outputs []operator.Operator) []operator.Operator {
+	if d == nil {
+		return outputs
+	}
+
+	output, ok := d.output.Load().(*deadLetterOutput)
+	if !ok {
+		return outputs
+	}
+
+	for _, op := range outputs {
+		if op.ID() == output.operator.ID() {
+			return outputs
+		}
+	}
+
+	withOutput := make([]operator.Operator, 0, len(outputs)+1)
+	withOutput = append(withOutput, outputs...)
+	return append(withOutput, output.operator)
+}
+
+// send will send a copy of the entry to the dead letter output, labeled with the error
+// and the ID of the operator that failed to process it. It returns false if there is
+// no dead letter output.
+func (d *deadLetter) send(ctx context.Context, e *entry.Entry, err error) bool {
+	if d == nil {
+		return false
+	}
+
+	output, ok := d.output.Load().(*deadLetterOutput)
+	if !ok {
+		return false
+	}
+
+	deadLettered := e.Copy()
+	deadLettered.AddLabel(DeadLetterErrorLabel, err.Error())
+	deadLettered.AddLabel(DeadLetterOperatorLabel, d.operatorID)
+
+	d.deadLettered.Inc()
+	output.entriesIn.Inc()
+	if err := output.operator.Process(ctx, deadLettered); err != nil {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterMissingOutput(t *testing.T) {
+	cfg := NewTransformerConfig("test", "test")
+	cfg.OnError = DeadLetterOnError
+	_, err := cfg.Build(testutil.NewBuildContext(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no `dead_letter_output`")
+}
+
+func TestDeadLetterTransformer(t *testing.T) {
+	cfg := NewTransformerConfig("test", "test")
+	cfg.OutputIDs = []string{"output"}
+	cfg.OnError = DeadLetterOnError
+	cfg.DeadLetterOutput = "fake"
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	output := testutil.NewMockOperator("$.output")
+	deadLetter := testutil.NewFakeOutput(t)
+	err = transformer.SetOutputs([]operator.Operator{output, deadLetter})
+	require.NoError(t, err)
+	require.Equal(t, []operator.Operator{output, deadLetter}, transformer.Outputs())
+
+	e := entry.New()
+	e.Record = "test"
+	err = transformer.ProcessWith(context.Background(), e, func(e *entry.Entry) error {
+		return fmt.Errorf("failure")
+	})
+	require.Error(t, err)
+	output.AssertNotCalled(t, "Process")
+
+	expected := e.Copy()
+	expected.AddLabel(DeadLetterErrorLabel, "failure")
+	expected.AddLabel(DeadLetterOperatorLabel, "$.test")
+	deadLetter.ExpectEntry(t, expected)
+	require.Empty(t, e.Labels)
+}
+
+func TestDeadLetterTransformerOriginal(t *testing.T) {
+	cfg := NewTransformerConfig("test", "test")
+	cfg.OutputIDs = []string{"output"}
+	cfg.OnError = DeadLetterOnError
+	cfg.DeadLetterOutput = "fake"
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	deadLetter := testutil.NewFakeOutput(t)
+	err = transformer.SetOutputs([]operator.Operator{testutil.NewMockOperator("$.output"), deadLetter})
+	require.NoError(t, err)
+
+	e := entry.New()
+	e.Record = map[string]interface{}{"message": "test"}
+	err = transformer.ProcessWith(context.Background(), e, func(e *entry.Entry) error {
+		e.Record = map[string]interface{}{"partial": "change"}
+		e.AddLabel("partial", "change")
+		return fmt.Errorf("failure")
+	})
+	require.Error(t, err)
+
+	// The entry is dead lettered as it was before the transform changed it
+	dead := deadLetter.ReceiveEntry(t)
+	require.Equal(t, map[string]interface{}{"message": "test"}, dead.Record)
+	require.Equal(t, map[string]interface{}{
+		DeadLetterErrorLabel:    "failure",
+		DeadLetterOperatorLabel: "$.test",
+	}, dead.Labels)
+}
+
+func TestDeadLetterParserOriginal(t *testing.T) {
+	cfg := NewTransformerConfig("test", "test")
+	cfg.OutputIDs = []string{"output"}
+	cfg.OnError = DeadLetterOnError
+	cfg.DeadLetterOutput = "fake"
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	missing := entry.NewRecordField("missing")
+	parser := ParserOperator{
+		TransformerOperator: transformer,
+		ParseFrom:           entry.NewRecordField("message"),
+		ParseTo:             entry.NewRecordField("parsed"),
+		TimeParser:          &TimeParser{ParseFrom: &missing},
+	}
+	deadLetter := testutil.NewFakeOutput(t)
+	err = parser.SetOutputs([]operator.Operator{testutil.NewMockOperator("$.output"), deadLetter})
+	require.NoError(t, err)
+
+	e := entry.New()
+	e.Record = map[string]interface{}{"message": "test"}
+	err = parser.ProcessWith(context.Background(), e, func(i interface{}) (interface{}, error) {
+		return map[string]interface{}{"value": i}, nil
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "time parser")
+
+	// The entry is dead lettered without the parsed value, since the time parser failed
+	require.Equal(t, map[string]interface{}{"parsed": map[string]interface{}{"value": "test"}}, e.Record)
+	require.Equal(t, map[string]interface{}{"message": "test"}, deadLetter.ReceiveEntry(t).Record)
+}
+
+func TestDeadLetterTransformerOutputNotFound(t *testing.T) {
+	cfg := NewTransformerConfig("test", "test")
+	cfg.OutputIDs = []string{"output"}
+	cfg.OnError = DeadLetterOnError
+	cfg.DeadLetterOutput = "missing"
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	output := testutil.NewMockOperator("$.output")
+	err = transformer.SetOutputs([]operator.Operator{output})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not exist")
+}
+
+func TestDeadLetterOutputReject(t *testing.T) {
+	cfg := NewOutputConfig("test", "test")
+	cfg.DeadLetterOutput = "fake"
+	output, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.True(t, output.CanOutput())
+
+	deadLetter := testutil.NewFakeOutput(t)
+	err = output.SetOutputs([]operator.Operator{deadLetter})
+	require.NoError(t, err)
+	require.Equal(t, []operator.Operator{deadLetter}, output.Outputs())
+
+	e := entry.New()
+	e.Record = "test"
+	output.Reject(context.Background(), e, fmt.Errorf("rejected"))
+
+	expected := e.Copy()
+	expected.AddLabel(DeadLetterErrorLabel, "rejected")
+	expected.AddLabel(DeadLetterOperatorLabel, "$.test")
+	deadLetter.ExpectEntry(t, expected)
+}
+
+func TestDeadLetterOutputRejectWithoutOutput(t *testing.T) {
+	cfg := NewOutputConfig("test", "test")
+	output, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+	require.False(t, output.CanOutput())
+
+	e := entry.New()
+	output.Reject(context.Background(), e, fmt.Errorf("rejected"))
+	require.Empty(t, e.Labels)
+}
+
+func TestDeadLetterMetrics(t *testing.T) {
+	cfg := NewTransformerConfig("dead_letter_metrics", "test")
+	cfg.OutputIDs = []string{"output"}
+	cfg.OnError = DeadLetterOnError
+	cfg.DeadLetterOutput = "fake"
+	transformer, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	output := testutil.NewMockOperator("$.output")
+	deadLetter := testutil.NewFakeOutput(t)
+	require.NoError(t, transformer.SetOutputs([]operator.Operator{output, deadLetter}))
+
+	entriesIn := promtestutil.ToFloat64(metrics.EntriesIn.WithLabelValues("$.fake"))
+	for i := 0; i < 2; i++ {
+		err = transformer.ProcessWith(context.Background(), entry.New(), func(e *entry.Entry) error {
+			return fmt.Errorf("failure")
+		})
+		require.Error(t, err)
+		deadLetter.ReceiveEntry(t)
+	}
+
+	require.Equal(t, float64(2), promtestutil.ToFloat64(metrics.Errors.WithLabelValues("$.dead_letter_metrics")))
+	require.Equal(t, float64(2), promtestutil.ToFloat64(metrics.DeadLettered.WithLabelValues("$.dead_letter_metrics")))
+	require.Equal(t, float64(0), promtestutil.ToFloat64(metrics.Dropped.WithLabelValues("$.dead_letter_metrics")))
+	require.Equal(t, entriesIn+2, promtestutil.ToFloat64(metrics.EntriesIn.WithLabelValues("$.fake")))
+}
+
+func TestDeadLetterOutputRejectMetrics(t *testing.T) {
+	cfg := NewOutputConfig("reject_metrics", "test")
+	output, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	output.Reject(context.Background(), entry.New(), fmt.Errorf("rejected"))
+	require.Equal(t, float64(1), promtestutil.ToFloat64(metrics.Dropped.WithLabelValues("$.reject_metrics")))
+}
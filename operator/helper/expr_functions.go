@@ -0,0 +1,304 @@
+package helper
+
+import (
+	"crypto/md5" // #nosec - md5 is offered for fingerprints, not for security
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	strptime "github.com/observiq/ctimefmt"
+)
+
+// exprFunctions are the functions available to every expression, by name
+var exprFunctions = map[string]interface{}{
+	"env":           os.Getenv,
+	"lower":         exprLower,
+	"upper":         exprUpper,
+	"trim":          exprTrim,
+	"regex_match":   exprRegexMatch,
+	"regex_capture": exprRegexCapture,
+	"sha256":        exprSHA256,
+	"md5":           exprMD5,
+	"json_decode":   exprJSONDecode,
+	"base64_encode": exprBase64Encode,
+	"base64_decode": exprBase64Decode,
+	"parse_time":    exprParseTime,
+	"format_time":   exprFormatTime,
+	"in_cidr":       exprInCIDR,
+	"get":           exprGet,
+}
+
+// exprString converts the argument of a function to a string
+func exprString(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("%s: expected a string, but got %T", name, value)
+	}
+}
+
+// exprLower returns the value in lower case
+func exprLower(value interface{}) (string, error) {
+	str, err := exprString("lower", value)
+	return strings.ToLower(str), err
+}
+
+// exprUpper returns the value in upper case
+func exprUpper(value interface{}) (string, error) {
+	str, err := exprString("upper", value)
+	return strings.ToUpper(str), err
+}
+
+// exprTrim returns the value without leading and trailing whitespace
+func exprTrim(value interface{}) (string, error) {
+	str, err := exprString("trim", value)
+	return strings.TrimSpace(str), err
+}
+
+// maxCachedRegexes limits the number of compiled regexes kept between calls
+const maxCachedRegexes = 256
+
+// regexCache holds compiled regexes, since expressions usually use the same few patterns
+var regexCache = struct {
+	sync.Mutex
+	regexes map[string]*regexp.Regexp
+}{regexes: make(map[string]*regexp.Regexp)}
+
+// compileRegex compiles a pattern, or returns the regex already compiled for it
+func compileRegex(name string, pattern interface{}) (*regexp.Regexp, error) {
+	str, err := exprString(name, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.Lock()
+	defer regexCache.Unlock()
+
+	if regex, ok := regexCache.regexes[str]; ok {
+		return regex, nil
+	}
+
+	regex, err := regexp.Compile(str)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+
+	if len(regexCache.regexes) < maxCachedRegexes {
+		regexCache.regexes[str] = regex
+	}
+	return regex, nil
+}
+
+// exprRegexMatch returns true if the value matches the regex pattern
+func exprRegexMatch(value, pattern interface{}) (bool, error) {
+	str, err := exprString("regex_match", value)
+	if err != nil {
+		return false, err
+	}
+
+	regex, err := compileRegex("regex_match", pattern)
+	if err != nil {
+		return false, err
+	}
+	return regex.MatchString(str), nil
+}
+
+// exprRegexCapture returns the named capture groups of the regex pattern in the
+// value, as a map. The map is empty if the value does not match the pattern.
+func exprRegexCapture(value, pattern interface{}) (map[string]interface{}, error) {
+	str, err := exprString("regex_capture", value)
+	if err != nil {
+		return nil, err
+	}
+
+	regex, err := compileRegex("regex_capture", pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	captures := make(map[string]interface{})
+	matches := regex.FindStringSubmatch(str)
+	if matches == nil {
+		return captures, nil
+	}
+
+	for i, name := range regex.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		captures[name] = matches[i]
+	}
+	return captures, nil
+}
+
+// exprSHA256 returns the hex encoded sha256 hash of the value
+func exprSHA256(value interface{}) (string, error) {
+	str, err := exprString("sha256", value)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(str))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// exprMD5 returns the hex encoded md5 hash of the value
+func exprMD5(value interface{}) (string, error) {
+	str, err := exprString("md5", value)
+	if err != nil {
+		return "", err
+	}
+	hash := md5.Sum([]byte(str)) // #nosec - md5 is offered for fingerprints, not for security
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// exprJSONDecode decodes a JSON document
+func exprJSONDecode(value interface{}) (interface{}, error) {
+	str, err := exprString("json_decode", value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(str), &decoded); err != nil {
+		return nil, fmt.Errorf("json_decode: %s", err)
+	}
+	return decoded, nil
+}
+
+// exprBase64Encode returns the standard base64 encoding of the value
+func exprBase64Encode(value interface{}) (string, error) {
+	str, err := exprString("base64_encode", value)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(str)), nil
+}
+
+// exprBase64Decode decodes a standard base64 encoded value
+func exprBase64Decode(value interface{}) (string, error) {
+	str, err := exprString("base64_decode", value)
+	if err != nil {
+		return "", err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return "", fmt.Errorf("base64_decode: %s", err)
+	}
+	return string(decoded), nil
+}
+
+// exprParseTime parses a time from the value, using a strptime layout
+func exprParseTime(value, layout interface{}) (time.Time, error) {
+	str, err := exprString("parse_time", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	layoutStr, err := exprString("parse_time", layout)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	parsed, err := strptime.Parse(layoutStr, str)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse_time: %s", err)
+	}
+	return parsed, nil
+}
+
+// exprFormatTime formats a time, using a strptime layout
+func exprFormatTime(value, layout interface{}) (string, error) {
+	t, ok := value.(time.Time)
+	if !ok {
+		return "", fmt.Errorf("format_time: expected a time, but got %T", value)
+	}
+
+	layoutStr, err := exprString("format_time", layout)
+	if err != nil {
+		return "", err
+	}
+
+	formatted, err := strptime.Format(layoutStr, t)
+	if err != nil {
+		return "", fmt.Errorf("format_time: %s", err)
+	}
+	return formatted, nil
+}
+
+// exprInCIDR returns true if the IP address is within the CIDR range, or any of a list of ranges
+func exprInCIDR(value, cidrs interface{}) (bool, error) {
+	str, err := exprString("in_cidr", value)
+	if err != nil {
+		return false, err
+	}
+
+	ip := net.ParseIP(str)
+	if ip == nil {
+		return false, nil
+	}
+
+	var ranges []interface{}
+	switch typed := cidrs.(type) {
+	case []interface{}:
+		ranges = typed
+	case []string:
+		for _, cidr := range typed {
+			ranges = append(ranges, cidr)
+		}
+	default:
+		ranges = []interface{}{cidrs}
+	}
+
+	for _, cidr := range ranges {
+		cidrStr, err := exprString("in_cidr", cidr)
+		if err != nil {
+			return false, err
+		}
+
+		_, network, err := net.ParseCIDR(cidrStr)
+		if err != nil {
+			return false, fmt.Errorf("in_cidr: %s", err)
+		}
+
+		if network.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// exprGet returns the value of a key in a map, or the default if the
+// value is not a map, or the key does not exist or is set to nil
+func exprGet(value, key, defaultValue interface{}) (interface{}, error) {
+	keyStr, err := exprString("get", key)
+	if err != nil {
+		return nil, err
+	}
+
+	var found interface{}
+	switch m := value.(type) {
+	case map[string]interface{}:
+		found = m[keyStr]
+	case map[string]string:
+		if v, ok := m[keyStr]; ok {
+			found = v
+		}
+	}
+
+	if found == nil {
+		return defaultValue, nil
+	}
+	return found, nil
+}
@@ -0,0 +1,122 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/antonmedv/expr"
+	"github.com/observiq/stanza/entry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExprFunctions(t *testing.T) {
+	exampleEntry := func() *entry.Entry {
+		e := entry.New()
+		e.Timestamp = time.Date(2020, time.June, 4, 12, 30, 15, 0, time.UTC)
+		e.Record = map[string]interface{}{
+			"message": "  GET /index.html 200  ",
+			"user":    "Alice",
+			"ip":      "10.1.2.3",
+			"json":    `{"key":"value","list":[1,2]}`,
+			"encoded": "aGVsbG8=",
+			"date":    "2020-06-04 12:30:15",
+			"nested": map[string]interface{}{
+				"key": "value",
+				"nil": nil,
+			},
+		}
+		e.Labels = map[string]interface{}{
+			"env": "prod",
+		}
+		return e
+	}
+
+	cases := []struct {
+		name     string
+		expr     string
+		expected interface{}
+	}{
+		{"Lower", `lower($record.user)`, "alice"},
+		{"Upper", `upper($record.user)`, "ALICE"},
+		{"Trim", `trim($record.message)`, "GET /index.html 200"},
+		{"LowerBytes", `lower($record.bytes)`, "bytes"},
+		{"RegexMatch", `regex_match($record.message, '^\\s*GET\\s')`, true},
+		{"RegexMatchFalse", `regex_match($record.message, '^POST')`, false},
+		{"RegexCapture", `regex_capture($record.message, '(?P<method>[A-Z]+) (?P<path>\\S+) (\\d+)')`, map[string]interface{}{"method": "GET", "path": "/index.html"}},
+		{"RegexCaptureField", `regex_capture($record.message, '(?P<status>\\d+)').status`, "200"},
+		{"RegexCaptureNoMatch", `regex_capture($record.user, '(?P<digits>\\d+)')`, map[string]interface{}{}},
+		{"SHA256", `sha256($record.user)`, "3bc51062973c458d5a6f2d8d64a023246354ad7e064b1e4e009ec8a0699a3043"},
+		{"MD5", `md5($record.user)`, "64489c85dc2fe0787b85cd87214b3810"},
+		{"JSONDecode", `json_decode($record.json)`, map[string]interface{}{"key": "value", "list": []interface{}{1.0, 2.0}}},
+		{"JSONDecodeField", `json_decode($record.json).key`, "value"},
+		{"Base64Encode", `base64_encode($record.user)`, "QWxpY2U="},
+		{"Base64Decode", `base64_decode($record.encoded)`, "hello"},
+		{"ParseTime", `parse_time($record.date, '%Y-%m-%d %H:%M:%S')`, time.Date(2020, time.June, 4, 12, 30, 15, 0, time.UTC)},
+		{"ParseTimeCompare", `parse_time($record.date, '%Y-%m-%d %H:%M:%S') == $timestamp`, true},
+		{"FormatTime", `format_time($timestamp, '%d/%m/%Y %H:%M')`, "04/06/2020 12:30"},
+		{"InCIDR", `in_cidr($record.ip, '10.0.0.0/8')`, true},
+		{"InCIDRFalse", `in_cidr($record.ip, '192.168.0.0/16')`, false},
+		{"InCIDRList", `in_cidr($record.ip, ['192.168.0.0/16', '10.1.0.0/16'])`, true},
+		{"InCIDRIPv6", `in_cidr('2001:db8::1', '2001:db8::/32')`, true},
+		{"InCIDRNotIP", `in_cidr($record.user, '10.0.0.0/8')`, false},
+		{"Get", `get($record.nested, 'key', 'default')`, "value"},
+		{"GetMissing", `get($record.nested, 'missing', 'default')`, "default"},
+		{"GetNil", `get($record.nested, 'nil', 'default')`, "default"},
+		{"GetNotMap", `get($record.missing, 'key', 'default')`, "default"},
+		{"GetLabels", `get($labels, 'env', 'dev')`, "prod"},
+		{"Combined", `upper(get(regex_capture(trim($record.message), '^(?P<method>\\w+)'), 'method', 'none'))`, "GET"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := expr.Compile(tc.expr, expr.AllowUndefinedVariables())
+			require.NoError(t, err)
+
+			e := exampleEntry()
+			e.Record.(map[string]interface{})["bytes"] = []byte("BYTES")
+			env := GetExprEnv(e)
+			defer PutExprEnv(env)
+
+			result, err := expr.Run(program, env)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestExprFunctionsInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		expr string
+	}{
+		{"LowerNotString", `lower($record.number)`},
+		{"RegexInvalidPattern", `regex_match($record.message, '(')`},
+		{"RegexCaptureNotString", `regex_capture($record.number, '\\d')`},
+		{"SHA256NotString", `sha256($record.number)`},
+		{"JSONDecodeInvalid", `json_decode($record.message)`},
+		{"Base64DecodeInvalid", `base64_decode($record.message)`},
+		{"ParseTimeMismatch", `parse_time($record.message, '%Y-%m-%d')`},
+		{"FormatTimeNotTime", `format_time($record.message, '%Y')`},
+		{"InCIDRInvalid", `in_cidr('10.1.2.3', 'not a cidr')`},
+		{"GetKeyNotString", `get($record, 1, 'default')`},
+		{"WrongArgumentCount", `lower($record.message, 'extra')`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			program, err := expr.Compile(tc.expr, expr.AllowUndefinedVariables())
+			require.NoError(t, err)
+
+			e := entry.New()
+			e.Record = map[string]interface{}{
+				"message": "not valid!",
+				"number":  10,
+			}
+			env := GetExprEnv(e)
+			defer PutExprEnv(env)
+
+			_, err = expr.Run(program, env)
+			require.Error(t, err)
+		})
+	}
+}
@@ -1,8 +1,8 @@
 package helper
 
 import (
+	"encoding/hex"
 	"fmt"
-	"os"
 	"strings"
 	"sync"
 
@@ -115,9 +115,11 @@ func (e *ExprString) Render(env map[string]interface{}) (string, error) {
 
 var envPool = sync.Pool{
 	New: func() interface{} {
-		return map[string]interface{}{
-			"env": os.Getenv,
+		env := make(map[string]interface{}, len(exprFunctions)+8)
+		for name, function := range exprFunctions {
+			env[name] = function
 		}
+		return env
 	},
 }
 
@@ -129,6 +131,9 @@ func GetExprEnv(e *entry.Entry) map[string]interface{} {
 	env["$labels"] = e.Labels
 	env["$resource"] = e.Resource
 	env["$timestamp"] = e.Timestamp
+	env["$trace_id"] = hex.EncodeToString(e.TraceID)
+	env["$span_id"] = hex.EncodeToString(e.SpanID)
+	env["$trace_flags"] = hex.EncodeToString(e.TraceFlags)
 
 	return env
 }
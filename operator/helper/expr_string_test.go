@@ -18,9 +18,12 @@ func TestExprString(t *testing.T) {
 		e.Record = map[string]interface{}{
 			"test": "value",
 		}
-		e.Resource = map[string]string{
+		e.Resource = map[string]interface{}{
 			"id": "value",
 		}
+		e.TraceID = []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+		e.SpanID = []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+		e.TraceFlags = []byte{0x01}
 		return e
 	}
 
@@ -84,6 +87,10 @@ func TestExprString(t *testing.T) {
 			"EXPR( $resource.id )",
 			"value",
 		},
+		{
+			"EXPR( $trace_id + '-' + $span_id + '-' + $trace_flags )",
+			"4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
 	}
 
 	for i, tc := range cases {
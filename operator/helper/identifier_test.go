@@ -30,7 +30,7 @@ func TestIdentifier(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "value1",
 				}
 				return e
@@ -48,7 +48,7 @@ func TestIdentifier(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "startend",
 				}
 				return e
@@ -66,7 +66,7 @@ func TestIdentifier(t *testing.T) {
 			entry.New(),
 			func() *entry.Entry {
 				e := entry.New()
-				e.Resource = map[string]string{
+				e.Resource = map[string]interface{}{
 					"key1": "foo",
 				}
 				return e
@@ -2,6 +2,8 @@ package helper
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/errors"
@@ -60,6 +62,34 @@ type InputOperator struct {
 	Identifier
 	WriterOperator
 	WriteTo entry.Field
+
+	// unhealthy holds the reason the input stopped working, if it has
+	unhealthy atomic.Value
+}
+
+// SetUnhealthy will mark the input as unhealthy, with a message describing why.
+func (i *InputOperator) SetUnhealthy(message string) {
+	i.unhealthy.Store(message)
+}
+
+// RoutineExited is meant to be deferred by the long running goroutines of an input.
+// If the context has not been cancelled when the goroutine returns, the goroutine
+// stopped unexpectedly and the input is marked as unhealthy.
+func (i *InputOperator) RoutineExited(ctx context.Context, name string) {
+	select {
+	case <-ctx.Done():
+	default:
+		i.Errorw("Goroutine exited unexpectedly", "goroutine", name)
+		i.SetUnhealthy(fmt.Sprintf("%s goroutine exited unexpectedly", name))
+	}
+}
+
+// Status will report the input as unhealthy if any of its goroutines stopped unexpectedly.
+func (i *InputOperator) Status() operator.Status {
+	if message, ok := i.unhealthy.Load().(string); ok {
+		return operator.Status{Healthy: false, Message: message}
+	}
+	return operator.Status{Healthy: true}
 }
 
 // NewEntry will create a new entry using the `write_to`, `labels`, and `resource` configuration.
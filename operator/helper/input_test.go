@@ -131,3 +131,27 @@ func TestInputOperatorNewEntry(t *testing.T) {
 	require.True(t, exists)
 	require.Equal(t, "resource", resourceValue)
 }
+
+func TestInputOperatorRoutineExited(t *testing.T) {
+	buildContext := testutil.NewBuildContext(t)
+	input := InputOperator{
+		WriterOperator: WriterOperator{
+			BasicOperator: BasicOperator{
+				OperatorID:    "test-id",
+				OperatorType:  "test-type",
+				SugaredLogger: buildContext.Logger.SugaredLogger,
+			},
+		},
+	}
+	require.True(t, input.Status().Healthy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	input.RoutineExited(ctx, "poller")
+	require.True(t, input.Status().Healthy)
+
+	input.RoutineExited(context.Background(), "poller")
+	status := input.Status()
+	require.False(t, status.Healthy)
+	require.Equal(t, "poller goroutine exited unexpectedly", status.Message)
+}
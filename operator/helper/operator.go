@@ -16,8 +16,9 @@ func NewBasicConfig(operatorID, operatorType string) BasicConfig {
 
 // BasicConfig provides a basic implemention for an operator config.
 type BasicConfig struct {
-	OperatorID   string `json:"id"   yaml:"id"`
-	OperatorType string `json:"type" yaml:"type"`
+	OperatorID   string       `json:"id"   yaml:"id"`
+	OperatorType string       `json:"type" yaml:"type"`
+	Queue        *QueueConfig `json:"queue,omitempty" yaml:"queue,omitempty"`
 }
 
 // ID will return the operator id.
@@ -33,6 +34,11 @@ func (c BasicConfig) Type() string {
 	return c.OperatorType
 }
 
+// QueueConfig will return the config of the queue in front of the operator, if it has one.
+func (c BasicConfig) QueueConfig() *QueueConfig {
+	return c.Queue
+}
+
 // Build will build a basic operator.
 func (c BasicConfig) Build(context operator.BuildContext) (BasicOperator, error) {
 	if c.OperatorType == "" {
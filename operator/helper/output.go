@@ -1,8 +1,14 @@
 package helper
 
 import (
+	"context"
+
+	"github.com/observiq/stanza/entry"
 	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/metrics"
 	"github.com/observiq/stanza/operator"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 )
 
 // NewOutputConfig creates a new output config
@@ -14,7 +20,8 @@ func NewOutputConfig(operatorID, operatorType string) OutputConfig {
 
 // OutputConfig provides a basic implementation of an output operator config.
 type OutputConfig struct {
-	BasicConfig `mapstructure:",squash" yaml:",inline"`
+	BasicConfig      `mapstructure:",squash" yaml:",inline"`
+	DeadLetterOutput string `json:"dead_letter_output,omitempty" yaml:"dead_letter_output,omitempty"`
 }
 
 // Build will build an output operator.
@@ -26,6 +33,8 @@ func (c OutputConfig) Build(context operator.BuildContext) (OutputOperator, erro
 
 	outputOperator := OutputOperator{
 		BasicOperator: basicOperator,
+		deadLetter:    newDeadLetter(context, basicOperator.ID(), c.DeadLetterOutput),
+		dropped:       metrics.Dropped.WithLabelValues(basicOperator.ID()),
 	}
 
 	return outputOperator, nil
@@ -34,6 +43,11 @@ func (c OutputConfig) Build(context operator.BuildContext) (OutputOperator, erro
 // OutputOperator provides a basic implementation of an output operator.
 type OutputOperator struct {
 	BasicOperator
+
+	deadLetter *deadLetter
+
+	// dropped counts the rejected entries the operator dropped
+	dropped prometheus.Counter
 }
 
 // CanProcess will always return true for an output operator.
@@ -41,20 +55,35 @@ func (o *OutputOperator) CanProcess() bool {
 	return true
 }
 
-// CanOutput will always return false for an output operator.
+// CanOutput will return false for an output operator, unless it has a dead letter output.
 func (o *OutputOperator) CanOutput() bool {
-	return false
+	return o.deadLetter != nil
 }
 
-// Outputs will always return an empty array for an output operator.
+// Outputs will return the dead letter output of an output operator, if it has one.
 func (o *OutputOperator) Outputs() []operator.Operator {
-	return []operator.Operator{}
+	return o.deadLetter.withOutput([]operator.Operator{})
 }
 
-// SetOutputs will return an error if called.
-func (o *OutputOperator) SetOutputs(_ []operator.Operator) error {
-	return errors.NewError(
-		"Operator can not output, but is attempting to set an output.",
-		"This is an unexpected internal error. Please submit a bug/issue.",
-	)
+// SetOutputs will set the dead letter output of an output operator,
+// or return an error if it does not have one.
+func (o *OutputOperator) SetOutputs(operators []operator.Operator) error {
+	if o.deadLetter == nil {
+		return errors.NewError(
+			"Operator can not output, but is attempting to set an output.",
+			"This is an unexpected internal error. Please submit a bug/issue.",
+		)
+	}
+	return o.deadLetter.setOutput(operators)
+}
+
+// Reject will send an entry that the output permanently failed to deliver to its
+// dead letter output. If the output does not have one, the entry is dropped.
+func (o *OutputOperator) Reject(ctx context.Context, e *entry.Entry, err error) {
+	if o.deadLetter.send(ctx, e, err) {
+		o.Warnw("Sent rejected entry to dead letter output", zap.Error(err))
+		return
+	}
+	o.Errorw("Dropping rejected entry", zap.Error(err))
+	resolvedCounter(o.dropped).Inc()
 }
@@ -27,6 +27,7 @@ type ParserConfig struct {
 	PreserveTo           *entry.Field          `json:"preserve_to"         yaml:"preserve_to"`
 	TimeParser           *TimeParser           `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
 	SeverityParserConfig *SeverityParserConfig `json:"severity,omitempty"  yaml:"severity,omitempty"`
+	TraceParser          *TraceParser          `json:"trace,omitempty"     yaml:"trace,omitempty"`
 }
 
 // Build will build a parser operator.
@@ -58,6 +59,13 @@ func (c ParserConfig) Build(context operator.BuildContext) (ParserOperator, erro
 		parserOperator.SeverityParser = &severityParser
 	}
 
+	if c.TraceParser != nil {
+		if err := c.TraceParser.Validate(context); err != nil {
+			return ParserOperator{}, err
+		}
+		parserOperator.TraceParser = c.TraceParser
+	}
+
 	return parserOperator, nil
 }
 
@@ -69,6 +77,7 @@ type ParserOperator struct {
 	PreserveTo     *entry.Field
 	TimeParser     *TimeParser
 	SeverityParser *SeverityParser
+	TraceParser    *TraceParser
 }
 
 // ProcessWith will run ParseWith on the entry, then forward the entry on to the next operators.
@@ -119,15 +128,17 @@ func (p *ParserOperator) ParseWith(ctx context.Context, entry *entry.Entry, pars
 		return p.HandleEntryError(ctx, entry, err)
 	}
 
+	// If a later step fails, the entry is dead lettered as it was before it was changed
+	snapshot := p.snapshot(entry)
 	original, _ := entry.Delete(p.ParseFrom)
 
 	if err := entry.Set(p.ParseTo, newValue); err != nil {
-		return p.HandleEntryError(ctx, entry, errors.Wrap(err, "set parse_to"))
+		return p.handleEntryError(ctx, entry, snapshot, errors.Wrap(err, "set parse_to"))
 	}
 
 	if p.PreserveTo != nil {
 		if err := entry.Set(p.PreserveTo, original); err != nil {
-			return p.HandleEntryError(ctx, entry, errors.Wrap(err, "set preserve_to"))
+			return p.handleEntryError(ctx, entry, snapshot, errors.Wrap(err, "set preserve_to"))
 		}
 	}
 
@@ -141,12 +152,20 @@ func (p *ParserOperator) ParseWith(ctx context.Context, entry *entry.Entry, pars
 		severityParseErr = p.SeverityParser.Parse(entry)
 	}
 
-	// Handle time or severity parsing errors after attempting to parse both
+	var traceParseErr error
+	if p.TraceParser != nil {
+		traceParseErr = p.TraceParser.Parse(entry)
+	}
+
+	// Handle time, severity or trace parsing errors after attempting to parse all of them
 	if timeParseErr != nil {
-		return p.HandleEntryError(ctx, entry, errors.Wrap(timeParseErr, "time parser"))
+		return p.handleEntryError(ctx, entry, snapshot, errors.Wrap(timeParseErr, "time parser"))
 	}
 	if severityParseErr != nil {
-		return p.HandleEntryError(ctx, entry, errors.Wrap(severityParseErr, "severity parser"))
+		return p.handleEntryError(ctx, entry, snapshot, errors.Wrap(severityParseErr, "severity parser"))
+	}
+	if traceParseErr != nil {
+		return p.handleEntryError(ctx, entry, snapshot, errors.Wrap(traceParseErr, "trace parser"))
 	}
 	return nil
 }
@@ -410,6 +410,31 @@ func TestParserPreserve(t *testing.T) {
 	}
 }
 
+func TestParserTrace(t *testing.T) {
+	cfg := NewParserConfig("test-id", "test-type")
+	cfg.TraceParser = &TraceParser{}
+
+	parser, err := cfg.Build(testutil.NewBuildContext(t))
+	require.NoError(t, err)
+
+	parse := func(i interface{}) (interface{}, error) {
+		return i, nil
+	}
+
+	e := entry.New()
+	e.Record = map[string]interface{}{
+		"message":     "test",
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+
+	err = parser.ProcessWith(context.Background(), e, parse)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"message": "test"}, e.Record)
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fmt.Sprintf("%x", e.TraceID))
+	require.Equal(t, "00f067aa0ba902b7", fmt.Sprintf("%x", e.SpanID))
+	require.Equal(t, []byte{0x01}, e.TraceFlags)
+}
+
 func writerWithFakeOut(t *testing.T) (*WriterOperator, *testutil.FakeOutput) {
 	buildContext := testutil.NewBuildContext(t)
 	fakeOut := testutil.NewFakeOutput(t)
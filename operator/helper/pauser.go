@@ -0,0 +1,66 @@
+package helper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/observiq/stanza/operator"
+)
+
+var _ operator.Pausable = (*Pauser)(nil)
+
+// Pauser is a helper that allows an input to be paused and resumed at runtime.
+// Inputs embed it and call Wait before they read more entries.
+type Pauser struct {
+	mux     sync.Mutex
+	paused  bool
+	resumed chan struct{}
+}
+
+// Pause will cause calls to Wait to block until the pauser is resumed.
+func (p *Pauser) Pause() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.paused {
+		return
+	}
+	p.paused = true
+	p.resumed = make(chan struct{})
+}
+
+// Resume will release any calls to Wait that are blocked.
+func (p *Pauser) Resume() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if !p.paused {
+		return
+	}
+	p.paused = false
+	close(p.resumed)
+}
+
+// Paused returns true if the pauser is paused.
+func (p *Pauser) Paused() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.paused
+}
+
+// Wait will block while the pauser is paused. It returns an error
+// if the context is done before the pauser is resumed.
+func (p *Pauser) Wait(ctx context.Context) error {
+	p.mux.Lock()
+	paused, resumed := p.paused, p.resumed
+	p.mux.Unlock()
+
+	if !paused {
+		return nil
+	}
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,54 @@
+package helper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauser(t *testing.T) {
+	t.Run("NotPaused", func(t *testing.T) {
+		var pauser Pauser
+		require.False(t, pauser.Paused())
+		require.NoError(t, pauser.Wait(context.Background()))
+	})
+
+	t.Run("PauseResume", func(t *testing.T) {
+		var pauser Pauser
+		pauser.Pause()
+		pauser.Pause()
+		require.True(t, pauser.Paused())
+
+		waited := make(chan error)
+		go func() { waited <- pauser.Wait(context.Background()) }()
+
+		select {
+		case <-waited:
+			require.FailNow(t, "Wait returned while paused")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		pauser.Resume()
+		pauser.Resume()
+		require.False(t, pauser.Paused())
+
+		select {
+		case err := <-waited:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			require.FailNow(t, "Wait did not return after resume")
+		}
+	})
+
+	t.Run("ContextDone", func(t *testing.T) {
+		var pauser Pauser
+		pauser.Pause()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		require.Error(t, pauser.Wait(ctx))
+		require.True(t, pauser.Paused())
+	})
+}
@@ -0,0 +1,233 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/metrics"
+	"github.com/observiq/stanza/operator"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// NewQueueConfig creates a new queue config with default values
+func NewQueueConfig() QueueConfig {
+	return QueueConfig{
+		Size:    1000,
+		Workers: 1,
+	}
+}
+
+// QueueConfig is the configuration of a queue in front of an operator
+type QueueConfig struct {
+	Size         int    `json:"size"                     yaml:"size"`
+	Workers      int    `json:"workers"                  yaml:"workers"`
+	OrderByLabel string `json:"order_by_label,omitempty" yaml:"order_by_label,omitempty"`
+}
+
+// UnmarshalJSON will unmarshal a queue config from JSON, applying the default values
+func (c *QueueConfig) UnmarshalJSON(raw []byte) error {
+	type queueConfig QueueConfig
+	cfg := queueConfig(NewQueueConfig())
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return err
+	}
+	*c = QueueConfig(cfg)
+	return nil
+}
+
+// UnmarshalYAML will unmarshal a queue config from YAML, applying the default values
+func (c *QueueConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type queueConfig QueueConfig
+	cfg := queueConfig(NewQueueConfig())
+	if err := unmarshal(&cfg); err != nil {
+		return err
+	}
+	*c = QueueConfig(cfg)
+	return nil
+}
+
+// Build will place a queue in front of the supplied operator
+func (c QueueConfig) Build(op operator.Operator) (*QueuedOperator, error) {
+	if !op.CanProcess() {
+		return nil, errors.NewError(
+			"operator has a `queue`, but it can not process entries.",
+			"ensure that the `queue` field is only set on operators that receive entries, like parsers and outputs.",
+			"operator_id", op.ID(),
+		)
+	}
+
+	if c.Size < 1 {
+		return nil, errors.NewError(
+			"queue `size` must be greater than 0.",
+			"ensure that the `size` field of the queue is a positive number.",
+			"operator_id", op.ID(),
+		)
+	}
+
+	if c.Workers < 1 {
+		return nil, errors.NewError(
+			"queue `workers` must be greater than 0.",
+			"ensure that the `workers` field of the queue is a positive number.",
+			"operator_id", op.ID(),
+		)
+	}
+
+	return &QueuedOperator{
+		Operator:     op,
+		size:         c.Size,
+		workers:      c.Workers,
+		orderByLabel: c.OrderByLabel,
+		depth:        metrics.QueueEntries.WithLabelValues(op.ID()),
+	}, nil
+}
+
+// QueuedOperator is an operator with a bounded queue in front of it. Entries are
+// placed on the queue and processed by a pool of workers, so that the operators
+// writing to it are not blocked until the queue is full.
+type QueuedOperator struct {
+	// pending counts the entries that have been queued and not yet processed.
+	// It is the first field so that it is 64-bit aligned for atomic operations.
+	pending int64
+
+	operator.Operator
+
+	size         int
+	workers      int
+	orderByLabel string
+	depth        prometheus.Gauge
+
+	// queues holds a single queue shared by the workers, or a queue per worker
+	// if entries are ordered by label
+	queues []chan *entry.Entry
+
+	// stopMux prevents the queues from being closed while entries are being added
+	stopMux sync.RWMutex
+	done    chan struct{}
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// Unwrap returns the operator behind the queue.
+func (q *QueuedOperator) Unwrap() operator.Operator {
+	return q.Operator
+}
+
+// Start will start the operator and the workers of its queue.
+func (q *QueuedOperator) Start() error {
+	if err := q.Operator.Start(); err != nil {
+		return err
+	}
+
+	if q.orderByLabel == "" {
+		q.queues = []chan *entry.Entry{make(chan *entry.Entry, q.size)}
+	} else {
+		size := q.size / q.workers
+		if size < 1 {
+			size = 1
+		}
+		q.queues = make([]chan *entry.Entry, q.workers)
+		for i := range q.queues {
+			q.queues[i] = make(chan *entry.Entry, size)
+		}
+	}
+	q.done = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx, q.queues[i%len(q.queues)])
+	}
+	return nil
+}
+
+// Stop will stop accepting entries, wait for the entries being placed on a full
+// queue and the queued entries to be processed, and then stop the operator.
+func (q *QueuedOperator) Stop() error {
+	if q.done != nil {
+		close(q.done)
+		q.stopMux.Lock()
+		for _, queue := range q.queues {
+			close(queue)
+		}
+		q.stopMux.Unlock()
+		q.wg.Wait()
+		q.cancel()
+	}
+	return q.Operator.Stop()
+}
+
+// Process will place an entry on the queue, blocking while the queue is full.
+func (q *QueuedOperator) Process(ctx context.Context, e *entry.Entry) error {
+	q.stopMux.RLock()
+	defer q.stopMux.RUnlock()
+
+	if q.done == nil {
+		return fmt.Errorf("queue of operator '%s' is not started", q.ID())
+	}
+
+	select {
+	case <-q.done:
+		return fmt.Errorf("queue of operator '%s' is stopped", q.ID())
+	default:
+	}
+
+	// The entry is retained until a worker has processed it. An entry that is
+	// waiting for room on a full queue is still queued if the queue is stopped,
+	// since the workers keep processing entries until Stop has closed the queues.
+	e.Retain()
+	q.depth.Inc()
+	atomic.AddInt64(&q.pending, 1)
+	select {
+	case q.queues[q.queueIndex(e)] <- e:
+		return nil
+	case <-ctx.Done():
+	}
+	atomic.AddInt64(&q.pending, -1)
+	q.depth.Dec()
+	e.Release()
+	return fmt.Errorf("queue of operator '%s' is full: %s", q.ID(), ctx.Err())
+}
+
+// Status will return the status of the operator behind the queue.
+func (q *QueuedOperator) Status() operator.Status {
+	return operator.GetStatus(q.Operator)
+}
+
+// Pending returns the number of queued entries that have not been processed,
+// along with the entries held by the operator behind the queue.
+func (q *QueuedOperator) Pending() int {
+	return int(atomic.LoadInt64(&q.pending)) + operator.GetPending(q.Operator)
+}
+
+// queueIndex returns the index of the queue an entry should be placed on, so
+// that entries with the same value for the order label are processed in order
+func (q *QueuedOperator) queueIndex(e *entry.Entry) int {
+	if len(q.queues) == 1 {
+		return 0
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(entry.AttributeString(e.Labels[q.orderByLabel])))
+	return int(hash.Sum32() % uint32(len(q.queues)))
+}
+
+// work will process the entries on a queue until it is closed
+func (q *QueuedOperator) work(ctx context.Context, queue chan *entry.Entry) {
+	defer q.wg.Done()
+	for e := range queue {
+		q.depth.Dec()
+		if err := q.Operator.Process(ctx, e); err != nil {
+			q.Logger().Errorw("Failed to process queued entry", zap.Error(err))
+		}
+		e.Release()
+		atomic.AddInt64(&q.pending, -1)
+	}
+}
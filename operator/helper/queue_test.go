@@ -0,0 +1,214 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// blockingOutput is a fake output that waits for each entry to be released before processing it
+type blockingOutput struct {
+	*testutil.FakeOutput
+	release chan struct{}
+}
+
+func (b *blockingOutput) Process(ctx context.Context, e *entry.Entry) error {
+	<-b.release
+	return b.FakeOutput.Process(ctx, e)
+}
+
+func TestQueueConfigDefaults(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		var cfg QueueConfig
+		require.NoError(t, json.Unmarshal([]byte(`{"workers":4}`), &cfg))
+		require.Equal(t, QueueConfig{Size: 1000, Workers: 4}, cfg)
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		var cfg QueueConfig
+		require.NoError(t, yaml.Unmarshal([]byte("size: 10\norder_by_label: file_name\n"), &cfg))
+		require.Equal(t, QueueConfig{Size: 10, Workers: 1, OrderByLabel: "file_name"}, cfg)
+	})
+}
+
+func TestQueueConfigBuild(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		_, err := NewQueueConfig().Build(testutil.NewFakeOutput(t))
+		require.NoError(t, err)
+	})
+
+	t.Run("CanNotProcess", func(t *testing.T) {
+		op := &testutil.Operator{}
+		op.On("ID").Return("test")
+		op.On("CanProcess").Return(false)
+		_, err := NewQueueConfig().Build(op)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "can not process entries")
+	})
+
+	t.Run("InvalidSize", func(t *testing.T) {
+		cfg := NewQueueConfig()
+		cfg.Size = 0
+		_, err := cfg.Build(testutil.NewFakeOutput(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "`size` must be greater than 0")
+	})
+
+	t.Run("InvalidWorkers", func(t *testing.T) {
+		cfg := NewQueueConfig()
+		cfg.Workers = 0
+		_, err := cfg.Build(testutil.NewFakeOutput(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "`workers` must be greater than 0")
+	})
+}
+
+func TestQueuedOperatorProcess(t *testing.T) {
+	fake := testutil.NewFakeOutput(t)
+	cfg := NewQueueConfig()
+	cfg.Workers = 4
+	queued, err := cfg.Build(fake)
+	require.NoError(t, err)
+
+	require.Error(t, queued.Process(context.Background(), entry.New()))
+
+	require.NoError(t, queued.Start())
+	for i := 0; i < 10; i++ {
+		require.NoError(t, queued.Process(context.Background(), entry.New()))
+	}
+	for i := 0; i < 10; i++ {
+		fake.ExpectRecord(t, nil)
+	}
+	require.NoError(t, queued.Stop())
+
+	require.Error(t, queued.Process(context.Background(), entry.New()))
+}
+
+func TestQueuedOperatorUnwrap(t *testing.T) {
+	fake := testutil.NewFakeOutput(t)
+	queued, err := NewQueueConfig().Build(fake)
+	require.NoError(t, err)
+	require.Same(t, fake, operator.Unwrap(queued))
+	require.Same(t, fake, operator.Unwrap(fake))
+}
+
+func TestQueuedOperatorBackpressure(t *testing.T) {
+	output := &blockingOutput{
+		FakeOutput: testutil.NewFakeOutput(t),
+		release:    make(chan struct{}),
+	}
+	cfg := NewQueueConfig()
+	cfg.Size = 1
+	queued, err := cfg.Build(output)
+	require.NoError(t, err)
+	require.NoError(t, queued.Start())
+
+	// The first entry is taken by the worker, and the second fills the queue
+	require.NoError(t, queued.Process(context.Background(), entry.New()))
+	require.Eventually(t, func() bool { return len(queued.queues[0]) == 0 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, queued.Process(context.Background(), entry.New()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.Error(t, queued.Process(ctx, entry.New()))
+
+	close(output.release)
+	output.ExpectRecord(t, nil)
+	output.ExpectRecord(t, nil)
+	require.NoError(t, queued.Stop())
+	output.ExpectNoEntry(t, 100*time.Millisecond)
+}
+
+func TestQueuedOperatorStopDrains(t *testing.T) {
+	output := &blockingOutput{
+		FakeOutput: testutil.NewFakeOutput(t),
+		release:    make(chan struct{}),
+	}
+	queued, err := NewQueueConfig().Build(output)
+	require.NoError(t, err)
+	require.NoError(t, queued.Start())
+
+	var acked int64
+	for i := 0; i < 5; i++ {
+		e := entry.New()
+		e.SetAck(entry.NewAck(func() { atomic.AddInt64(&acked, 1) }))
+		require.NoError(t, queued.Process(context.Background(), e))
+		e.Release()
+	}
+	require.Equal(t, int64(0), atomic.LoadInt64(&acked))
+	require.Equal(t, 5, queued.Pending())
+
+	close(output.release)
+	require.NoError(t, queued.Stop())
+	require.Len(t, output.Received, 5)
+	require.Equal(t, int64(5), atomic.LoadInt64(&acked))
+	require.Equal(t, 0, queued.Pending())
+}
+
+func TestQueuedOperatorStopWhileFull(t *testing.T) {
+	output := &blockingOutput{
+		FakeOutput: testutil.NewFakeOutput(t),
+		release:    make(chan struct{}),
+	}
+	cfg := NewQueueConfig()
+	cfg.Size = 1
+	queued, err := cfg.Build(output)
+	require.NoError(t, err)
+	require.NoError(t, queued.Start())
+
+	require.NoError(t, queued.Process(context.Background(), entry.New()))
+	require.Eventually(t, func() bool { return len(queued.queues[0]) == 0 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, queued.Process(context.Background(), entry.New()))
+
+	// The entry waiting for room on the full queue is queued, even though the queue is stopped
+	processed := make(chan error)
+	go func() { processed <- queued.Process(context.Background(), entry.New()) }()
+	require.Eventually(t, func() bool { return queued.Pending() == 3 }, time.Second, 10*time.Millisecond)
+	stopped := make(chan error)
+	go func() { stopped <- queued.Stop() }()
+
+	close(output.release)
+	require.NoError(t, <-processed)
+	require.NoError(t, <-stopped)
+	require.Len(t, output.Received, 3)
+	require.Error(t, queued.Process(context.Background(), entry.New()))
+}
+
+func TestQueuedOperatorOrderByLabel(t *testing.T) {
+	fake := testutil.NewFakeOutput(t)
+	cfg := NewQueueConfig()
+	cfg.Workers = 4
+	cfg.OrderByLabel = "source"
+	queued, err := cfg.Build(fake)
+	require.NoError(t, err)
+	require.Len(t, queued.queues, 0)
+	require.NoError(t, queued.Start())
+	require.Len(t, queued.queues, 4)
+
+	for i := 0; i < 20; i++ {
+		e := entry.New()
+		e.Record = i
+		e.AddLabel("source", fmt.Sprintf("source-%d", i%3))
+		require.NoError(t, queued.Process(context.Background(), e))
+	}
+	require.NoError(t, queued.Stop())
+
+	last := map[string]int{}
+	for i := 0; i < 20; i++ {
+		e := <-fake.Received
+		source := e.Labels["source"].(string)
+		if previous, ok := last[source]; ok {
+			require.Greater(t, e.Record.(int), previous)
+		}
+		last[source] = e.Record.(int)
+	}
+}
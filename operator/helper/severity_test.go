@@ -387,3 +387,28 @@ func (tc severityTestCase) run(parseFrom entry.Field) func(*testing.T) {
 
 	}
 }
+
+func TestParseSeverity(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected entry.Severity
+	}{
+		{"error", entry.Error},
+		{"warn", entry.Warning},
+		{"fatal", entry.Emergency},
+		{"debug2", entry.Debug2},
+		{60, entry.Error},
+		{"55", entry.Severity(55)},
+	}
+
+	for _, tc := range cases {
+		severity, err := ParseSeverity(tc.value)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, severity)
+	}
+
+	for _, value := range []interface{}{"loud", 101, 1.5} {
+		_, err := ParseSeverity(value)
+		require.Error(t, err, "%v", value)
+	}
+}
@@ -0,0 +1,249 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+)
+
+const (
+	traceIDSize    = 16
+	spanIDSize     = 8
+	traceFlagsSize = 1
+)
+
+// NewTraceParser creates a new trace parser with default values
+func NewTraceParser() TraceParser {
+	parser := TraceParser{}
+	parser.setDefaults()
+	return parser
+}
+
+// TraceParser is a helper that parses the trace context of an entry. It reads either a
+// W3C traceparent header, or the trace ID, span ID and trace flags as separate fields.
+type TraceParser struct {
+	Traceparent TraceFieldParser `json:"traceparent,omitempty" yaml:"traceparent,omitempty"`
+	TraceID     TraceFieldParser `json:"trace_id,omitempty"    yaml:"trace_id,omitempty"`
+	SpanID      TraceFieldParser `json:"span_id,omitempty"     yaml:"span_id,omitempty"`
+	TraceFlags  TraceFieldParser `json:"trace_flags,omitempty" yaml:"trace_flags,omitempty"`
+}
+
+// TraceFieldParser describes where to parse a part of the trace context from
+type TraceFieldParser struct {
+	ParseFrom  *entry.Field `json:"parse_from,omitempty"  yaml:"parse_from,omitempty"`
+	PreserveTo *entry.Field `json:"preserve_to,omitempty" yaml:"preserve_to,omitempty"`
+}
+
+// Validate validates a TraceParser, and sets the default parse_from fields if necessary
+func (t *TraceParser) Validate(_ operator.BuildContext) error {
+	t.setDefaults()
+	return nil
+}
+
+// setDefaults sets the parse_from fields that are not configured to their default values
+func (t *TraceParser) setDefaults() {
+	setDefault := func(p *TraceFieldParser, name string) {
+		if p.ParseFrom == nil {
+			field := entry.NewRecordField(name)
+			p.ParseFrom = &field
+		}
+	}
+	setDefault(&t.Traceparent, "traceparent")
+	setDefault(&t.TraceID, "trace_id")
+	setDefault(&t.SpanID, "span_id")
+	setDefault(&t.TraceFlags, "trace_flags")
+}
+
+// Parse will parse the trace context from the fields of an entry and attach it to the entry.
+// Fields that are missing from the entry are skipped, since many entries have no trace context.
+func (t *TraceParser) Parse(ent *entry.Entry) error {
+	err := t.Traceparent.parse(ent, "traceparent", func(value interface{}) error {
+		traceID, spanID, traceFlags, err := parseTraceparent(value)
+		if err != nil {
+			return err
+		}
+		ent.TraceID, ent.SpanID, ent.TraceFlags = traceID, spanID, traceFlags
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.TraceID.parse(ent, "trace_id", func(value interface{}) error {
+		traceID, err := parseTraceBytes(value, traceIDSize)
+		if err != nil {
+			return err
+		}
+		ent.TraceID = traceID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.SpanID.parse(ent, "span_id", func(value interface{}) error {
+		spanID, err := parseTraceBytes(value, spanIDSize)
+		if err != nil {
+			return err
+		}
+		ent.SpanID = spanID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return t.TraceFlags.parse(ent, "trace_flags", func(value interface{}) error {
+		traceFlags, err := parseTraceFlags(value)
+		if err != nil {
+			return err
+		}
+		ent.TraceFlags = traceFlags
+		return nil
+	})
+}
+
+// parse will parse a value from the parse_from field of the entry, if it exists. The
+// field is only removed from the entry, and preserved, after it is parsed successfully.
+func (p *TraceFieldParser) parse(ent *entry.Entry, name string, parse func(interface{}) error) error {
+	if p.ParseFrom == nil {
+		return nil
+	}
+
+	value, ok := ent.Get(*p.ParseFrom)
+	if !ok {
+		return nil
+	}
+
+	if err := parse(value); err != nil {
+		return errors.Wrap(err, "parse "+name)
+	}
+
+	ent.Delete(*p.ParseFrom)
+	if p.PreserveTo != nil {
+		if err := ent.Set(p.PreserveTo, value); err != nil {
+			return errors.Wrap(err, "set preserve_to")
+		}
+	}
+	return nil
+}
+
+// parseTraceparent parses a W3C traceparent header, formatted as version-traceid-spanid-flags
+func parseTraceparent(value interface{}) ([]byte, []byte, []byte, error) {
+	str, err := traceString(value)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	parts := strings.Split(strings.TrimSpace(str), "-")
+	if len(parts) < 4 {
+		return nil, nil, nil, fmt.Errorf("traceparent %q is not formatted as version-traceid-spanid-flags", str)
+	}
+
+	version, err := decodeTraceHex(parts[0], 1)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "version")
+	}
+	if version[0] == 0xff {
+		return nil, nil, nil, fmt.Errorf("traceparent version ff is invalid")
+	}
+	if version[0] == 0 && len(parts) != 4 {
+		return nil, nil, nil, fmt.Errorf("traceparent %q has too many parts for version 00", str)
+	}
+
+	traceID, err := parseTraceBytes(parts[1], traceIDSize)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "trace id")
+	}
+
+	spanID, err := parseTraceBytes(parts[2], spanIDSize)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "span id")
+	}
+
+	traceFlags, err := decodeTraceHex(parts[3], traceFlagsSize)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "trace flags")
+	}
+
+	return traceID, spanID, traceFlags, nil
+}
+
+// parseTraceBytes parses a trace or span ID from a hex string, or from raw bytes of the
+// right size. IDs made only of zeros are invalid, as described by the W3C trace context.
+func parseTraceBytes(value interface{}, size int) ([]byte, error) {
+	var id []byte
+	if raw, ok := value.([]byte); ok && len(raw) == size {
+		id = raw
+	} else {
+		str, err := traceString(value)
+		if err != nil {
+			return nil, err
+		}
+		id, err = decodeTraceHex(str, size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if bytes.Equal(id, make([]byte, size)) {
+		return nil, fmt.Errorf("id must not be all zeros")
+	}
+	return id, nil
+}
+
+// parseTraceFlags parses trace flags from a hex string or an integer
+func parseTraceFlags(value interface{}) ([]byte, error) {
+	var flags int
+	switch v := value.(type) {
+	case int:
+		flags = v
+	case int64:
+		flags = int(v)
+	case float64:
+		if v != float64(int(v)) {
+			return nil, fmt.Errorf("trace flags must be a whole number, but got %v", v)
+		}
+		flags = int(v)
+	default:
+		str, err := traceString(value)
+		if err != nil {
+			return nil, err
+		}
+		return decodeTraceHex(str, traceFlagsSize)
+	}
+
+	if flags < 0 || flags > 0xff {
+		return nil, fmt.Errorf("trace flags must be between 0 and 255, but got %d", flags)
+	}
+	return []byte{byte(flags)}, nil
+}
+
+// traceString returns the string form of a value holding hex characters
+func traceString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("type '%T' cannot be parsed as trace context", value)
+	}
+}
+
+// decodeTraceHex decodes a hex string that must hold exactly size bytes
+func decodeTraceHex(str string, size int) ([]byte, error) {
+	if len(str) != size*2 {
+		return nil, fmt.Errorf("expected %d hex characters, but got %q", size*2, str)
+	}
+	decoded, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a hex encoded string", str)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,149 @@
+package helper
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceParser(t *testing.T) {
+	traceID, _ := hex.DecodeString("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := hex.DecodeString("00f067aa0ba902b7")
+
+	cases := []struct {
+		name           string
+		record         map[string]interface{}
+		expectedTrace  []byte
+		expectedSpan   []byte
+		expectedFlags  []byte
+		expectedRecord map[string]interface{}
+	}{
+		{
+			"Traceparent",
+			map[string]interface{}{
+				"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"message":     "test",
+			},
+			traceID,
+			spanID,
+			[]byte{0x01},
+			map[string]interface{}{"message": "test"},
+		},
+		{
+			"FutureVersion",
+			map[string]interface{}{
+				"traceparent": "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra",
+			},
+			traceID,
+			spanID,
+			[]byte{0x00},
+			map[string]interface{}{},
+		},
+		{
+			"SeparateFields",
+			map[string]interface{}{
+				"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+				"span_id":     "00f067aa0ba902b7",
+				"trace_flags": "01",
+			},
+			traceID,
+			spanID,
+			[]byte{0x01},
+			map[string]interface{}{},
+		},
+		{
+			"IntegerFlags",
+			map[string]interface{}{
+				"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+				"trace_flags": 1,
+			},
+			traceID,
+			nil,
+			[]byte{0x01},
+			map[string]interface{}{},
+		},
+		{
+			"RawBytes",
+			map[string]interface{}{
+				"trace_id": traceID,
+				"span_id":  spanID,
+			},
+			traceID,
+			spanID,
+			nil,
+			map[string]interface{}{},
+		},
+		{
+			"Missing",
+			map[string]interface{}{"message": "test"},
+			nil,
+			nil,
+			nil,
+			map[string]interface{}{"message": "test"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewTraceParser()
+			ent := entry.New()
+			ent.Record = tc.record
+
+			require.NoError(t, parser.Parse(ent))
+			require.Equal(t, tc.expectedTrace, ent.TraceID)
+			require.Equal(t, tc.expectedSpan, ent.SpanID)
+			require.Equal(t, tc.expectedFlags, ent.TraceFlags)
+			require.Equal(t, tc.expectedRecord, ent.Record)
+		})
+	}
+}
+
+func TestTraceParserErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		record map[string]interface{}
+	}{
+		{"TraceparentTooShort", map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"}},
+		{"TraceparentInvalidVersion", map[string]interface{}{"traceparent": "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+		{"TraceparentExtraParts", map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"}},
+		{"TraceparentZeroTraceID", map[string]interface{}{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}},
+		{"TraceparentZeroSpanID", map[string]interface{}{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"}},
+		{"TraceIDNotHex", map[string]interface{}{"trace_id": "4bf92f3577b34da6a3ce929d0e0e473z"}},
+		{"TraceIDTooShort", map[string]interface{}{"trace_id": "4bf92f35"}},
+		{"SpanIDWrongType", map[string]interface{}{"span_id": true}},
+		{"FlagsOutOfRange", map[string]interface{}{"trace_flags": 256}},
+		{"FlagsNotWhole", map[string]interface{}{"trace_flags": 1.5}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewTraceParser()
+			ent := entry.New()
+			ent.Record = tc.record
+
+			require.Error(t, parser.Parse(ent))
+			require.Equal(t, tc.record, ent.Record, "the field should not be removed when it fails to parse")
+		})
+	}
+}
+
+func TestTraceParserPreserve(t *testing.T) {
+	parser := TraceParser{}
+	parseFrom := entry.NewRecordField("context")
+	preserveTo := entry.NewRecordField("original")
+	parser.Traceparent.ParseFrom = &parseFrom
+	parser.Traceparent.PreserveTo = &preserveTo
+	require.NoError(t, parser.Validate(testutil.NewBuildContext(t)))
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ent := entry.New()
+	ent.Record = map[string]interface{}{"context": traceparent}
+
+	require.NoError(t, parser.Parse(ent))
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", hex.EncodeToString(ent.TraceID))
+	require.Equal(t, map[string]interface{}{"original": traceparent}, ent.Record)
+	require.Equal(t, entry.NewRecordField("trace_id"), *parser.TraceID.ParseFrom)
+}
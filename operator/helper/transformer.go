@@ -22,9 +22,10 @@ func NewTransformerConfig(operatorID, operatorType string) TransformerConfig {
 
 // TransformerConfig provides a basic implementation of a transformer config.
 type TransformerConfig struct {
-	WriterConfig `yaml:",inline"`
-	OnError      string `json:"on_error" yaml:"on_error"`
-	IfExpr       string `json:"if"                  yaml:"if"`
+	WriterConfig     `yaml:",inline"`
+	OnError          string `json:"on_error" yaml:"on_error"`
+	DeadLetterOutput string `json:"dead_letter_output,omitempty" yaml:"dead_letter_output,omitempty"`
+	IfExpr           string `json:"if"                  yaml:"if"`
 }
 
 // Build will build a transformer operator.
@@ -36,10 +37,18 @@ func (c TransformerConfig) Build(context operator.BuildContext) (TransformerOper
 
 	switch c.OnError {
 	case SendOnError, DropOnError:
+	case DeadLetterOnError:
+		if c.DeadLetterOutput == "" {
+			return TransformerOperator{}, errors.NewError(
+				"operator config has an `on_error` field of `dead_letter`, but no `dead_letter_output`.",
+				"ensure that the `dead_letter_output` field is set to the ID of an output.",
+				"operator_id", c.ID(),
+			)
+		}
 	default:
 		return TransformerOperator{}, errors.NewError(
 			"operator config has an invalid `on_error` field.",
-			"ensure that the `on_error` field is set to either `send` or `drop`.",
+			"ensure that the `on_error` field is set to `send`, `drop` or `dead_letter`.",
 			"on_error", c.OnError,
 		)
 	}
@@ -49,6 +58,10 @@ func (c TransformerConfig) Build(context operator.BuildContext) (TransformerOper
 		OnError:        c.OnError,
 	}
 
+	if c.OnError == DeadLetterOnError {
+		transformerOperator.deadLetter = newDeadLetter(context, writerOperator.ID(), c.DeadLetterOutput)
+	}
+
 	if c.IfExpr != "" {
 		compiled, err := expr.Compile(c.IfExpr, expr.AsBool(), expr.AllowUndefinedVariables())
 		if err != nil {
@@ -65,6 +78,8 @@ type TransformerOperator struct {
 	WriterOperator
 	OnError string
 	IfExpr  *vm.Program
+
+	deadLetter *deadLetter
 }
 
 // CanProcess will always return true for a transformer operator.
@@ -72,6 +87,19 @@ func (t *TransformerOperator) CanProcess() bool {
 	return true
 }
 
+// Outputs returns the outputs of the transformer operator, including its dead letter output.
+func (t *TransformerOperator) Outputs() []operator.Operator {
+	return t.deadLetter.withOutput(t.WriterOperator.Outputs())
+}
+
+// SetOutputs will set the outputs of the transformer operator, including its dead letter output.
+func (t *TransformerOperator) SetOutputs(operators []operator.Operator) error {
+	if err := t.WriterOperator.SetOutputs(operators); err != nil {
+		return err
+	}
+	return t.deadLetter.setOutput(operators)
+}
+
 // ProcessWith will process an entry with a transform function.
 func (t *TransformerOperator) ProcessWith(ctx context.Context, entry *entry.Entry, transform TransformFunction) error {
 	// Short circuit if the "if" condition does not match
@@ -84,8 +112,9 @@ func (t *TransformerOperator) ProcessWith(ctx context.Context, entry *entry.Entr
 		return nil
 	}
 
+	original := t.snapshot(entry)
 	if err := transform(entry); err != nil {
-		return t.HandleEntryError(ctx, entry, err)
+		return t.handleEntryError(ctx, entry, original, err)
 	}
 	t.Write(ctx, entry)
 	return nil
@@ -93,13 +122,38 @@ func (t *TransformerOperator) ProcessWith(ctx context.Context, entry *entry.Entr
 
 // HandleEntryError will handle an entry error using the on_error strategy.
 func (t *TransformerOperator) HandleEntryError(ctx context.Context, entry *entry.Entry, err error) error {
+	return t.handleEntryError(ctx, entry, entry, err)
+}
+
+// handleEntryError will handle an entry error using the on_error strategy. The original
+// entry, as it was before it was changed by the failed transform, is dead lettered.
+func (t *TransformerOperator) handleEntryError(ctx context.Context, entry, original *entry.Entry, err error) error {
 	t.Errorw("Failed to process entry", zap.Any("error", err), zap.Any("action", t.OnError), zap.Any("entry", entry))
-	if t.OnError == SendOnError {
+	errorCounter, droppedCounter := t.errorCounters()
+	errorCounter.Inc()
+	switch t.OnError {
+	case SendOnError:
 		t.Write(ctx, entry)
+	case DeadLetterOnError:
+		if !t.deadLetter.send(ctx, original, err) {
+			droppedCounter.Inc()
+		}
+	default:
+		droppedCounter.Inc()
 	}
 	return err
 }
 
+// snapshot returns a copy of an entry that is about to be transformed, so that it can be
+// dead lettered as it was if the transform fails. Without a dead letter output, the entry
+// itself is returned, since it is not needed.
+func (t *TransformerOperator) snapshot(entry *entry.Entry) *entry.Entry {
+	if t.OnError != DeadLetterOnError {
+		return entry
+	}
+	return entry.Copy()
+}
+
 // Skip if entry doesn't match expression
 func (t *TransformerOperator) Skip(_ context.Context, entry *entry.Entry) (bool, error) {
 	if t.IfExpr == nil {
@@ -125,3 +179,6 @@ const SendOnError = "send"
 
 // DropOnError specifies an on_error mode for dropping entries after an error.
 const DropOnError = "drop"
+
+// DeadLetterOnError specifies an on_error mode for sending entries to a dead letter output after an error.
+const DeadLetterOnError = "dead_letter"
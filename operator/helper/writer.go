@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
 	"github.com/observiq/stanza/operator"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // NewWriterConfig creates a new writer config
@@ -47,12 +50,114 @@ type WriterOperator struct {
 	BasicOperator
 	OutputIDs       OutputIDs
 	OutputOperators []operator.Operator
+
+	// outputs holds the outputs last set with SetOutputs, which may be
+	// replaced while the operator is running when the pipeline is reloaded
+	outputs atomic.Value
+}
+
+// writerOutputs is a set of outputs along with the counters of entries written to them, and the
+// counters of entries the writer failed to process or dropped, which are resolved along with them
+type writerOutputs struct {
+	operators  []operator.Operator
+	entriesIn  []prometheus.Counter
+	entriesOut prometheus.Counter
+	errors     prometheus.Counter
+	dropped    prometheus.Counter
+
+	// taps observe the entries written, and are empty unless a tap is attached
+	taps []operator.Tap
+}
+
+// newWriterOutputs creates the set of outputs of the writer identified by operatorID
+func newWriterOutputs(operatorID string, operators []operator.Operator) *writerOutputs {
+	entriesIn := make([]prometheus.Counter, 0, len(operators))
+	for _, operator := range operators {
+		entriesIn = append(entriesIn, metrics.EntriesIn.WithLabelValues(operator.ID()))
+	}
+
+	return &writerOutputs{
+		operators:  operators,
+		entriesIn:  entriesIn,
+		entriesOut: metrics.EntriesOut.WithLabelValues(operatorID),
+		errors:     metrics.Errors.WithLabelValues(operatorID),
+		dropped:    metrics.Dropped.WithLabelValues(operatorID),
+	}
+}
+
+// unregisteredCounter counts the entries of operators whose counters were not resolved, because they
+// were not built from a config or their outputs were assigned directly, and is not exposed
+var unregisteredCounter = prometheus.NewCounter(prometheus.CounterOpts{Name: "unregistered"})
+
+// resolvedCounter returns a counter, or the unregistered counter if the counter was not resolved
+func resolvedCounter(counter prometheus.Counter) prometheus.Counter {
+	if counter == nil {
+		return unregisteredCounter
+	}
+	return counter
+}
+
+// loadOutputs returns the current set of outputs of the writer, which are created
+// from the outputs that were assigned directly if SetOutputs has not been called
+func (w *WriterOperator) loadOutputs() *writerOutputs {
+	if outputs, ok := w.outputs.Load().(*writerOutputs); ok {
+		return outputs
+	}
+
+	entriesIn := make([]prometheus.Counter, len(w.OutputOperators))
+	for i := range entriesIn {
+		entriesIn[i] = unregisteredCounter
+	}
+	return &writerOutputs{
+		operators:  w.OutputOperators,
+		entriesIn:  entriesIn,
+		entriesOut: unregisteredCounter,
+		errors:     unregisteredCounter,
+		dropped:    unregisteredCounter,
+	}
+}
+
+// errorCounters returns the counters of the entries the writer failed to process and dropped
+func (w *WriterOperator) errorCounters() (errors, dropped prometheus.Counter) {
+	if outputs, ok := w.outputs.Load().(*writerOutputs); ok {
+		return outputs.errors, outputs.dropped
+	}
+	return unregisteredCounter, unregisteredCounter
+}
+
+// CountDropped counts an entry that the operator dropped on purpose, such as an entry over a
+// rate limit or a duplicate, in the dropped entries of the operator.
+func (w *WriterOperator) CountDropped() {
+	_, dropped := w.errorCounters()
+	dropped.Inc()
 }
 
 // Write will write an entry to the outputs of the operator.
+// Each output receives its own copy, sharing the entry's acknowledgement.
 func (w *WriterOperator) Write(ctx context.Context, e *entry.Entry) {
-	for i, operator := range w.OutputOperators {
-		if i == len(w.OutputOperators)-1 {
+	outputs, ok := w.outputs.Load().(*writerOutputs)
+	if !ok {
+		// The outputs were assigned directly rather than with SetOutputs, so they have no counters or taps
+		w.writeTo(ctx, e, w.OutputOperators, nil)
+		return
+	}
+
+	for _, tap := range outputs.taps {
+		tap.Observe(e)
+	}
+
+	outputs.entriesOut.Inc()
+	w.writeTo(ctx, e, outputs.operators, outputs.entriesIn)
+}
+
+// writeTo will write an entry to a set of outputs, counting the entries each receives
+// if their counters are supplied
+func (w *WriterOperator) writeTo(ctx context.Context, e *entry.Entry, operators []operator.Operator, entriesIn []prometheus.Counter) {
+	for i, operator := range operators {
+		if entriesIn != nil {
+			entriesIn[i].Inc()
+		}
+		if i == len(operators)-1 {
 			if err := operator.Process(ctx, e); err != nil {
 				w.Errorf("error while writing entry: %s", err)
 			}
@@ -71,6 +176,9 @@ func (w *WriterOperator) CanOutput() bool {
 
 // Outputs returns the outputs of the writer operator.
 func (w *WriterOperator) Outputs() []operator.Operator {
+	if outputs, ok := w.outputs.Load().(*writerOutputs); ok {
+		return outputs.operators
+	}
 	return w.OutputOperators
 }
 
@@ -92,9 +200,48 @@ func (w *WriterOperator) SetOutputs(operators []operator.Operator) error {
 	}
 
 	w.OutputOperators = outputOperators
+	w.updateOutputs(func(current *writerOutputs) *writerOutputs {
+		outputs := newWriterOutputs(w.ID(), outputOperators)
+		outputs.taps = current.taps
+		return outputs
+	})
 	return nil
 }
 
+// AttachTap will attach a tap that observes the entries written by the operator.
+func (w *WriterOperator) AttachTap(tap operator.Tap) {
+	w.updateOutputs(func(current *writerOutputs) *writerOutputs {
+		outputs := *current
+		outputs.taps = append(append(make([]operator.Tap, 0, len(current.taps)+1), current.taps...), tap)
+		return &outputs
+	})
+}
+
+// DetachTap will detach a tap from the operator.
+func (w *WriterOperator) DetachTap(tap operator.Tap) {
+	w.updateOutputs(func(current *writerOutputs) *writerOutputs {
+		outputs := *current
+		outputs.taps = make([]operator.Tap, 0, len(current.taps))
+		for _, attached := range current.taps {
+			if attached != tap {
+				outputs.taps = append(outputs.taps, attached)
+			}
+		}
+		return &outputs
+	})
+}
+
+// updateOutputs will replace the outputs of the writer with the result of update,
+// retrying if the outputs are replaced concurrently
+func (w *WriterOperator) updateOutputs(update func(*writerOutputs) *writerOutputs) {
+	for {
+		previous := w.outputs.Load()
+		if w.outputs.CompareAndSwap(previous, update(w.loadOutputs())) {
+			return
+		}
+	}
+}
+
 // FindOperator will find an operator matching the supplied id.
 func (w *WriterOperator) findOperator(operators []operator.Operator, operatorID string) (operator.Operator, bool) {
 	for _, operator := range operators {
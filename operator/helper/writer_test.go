@@ -6,8 +6,10 @@ import (
 	"testing"
 
 	"github.com/observiq/stanza/entry"
+	"github.com/observiq/stanza/metrics"
 	"github.com/observiq/stanza/operator"
 	"github.com/observiq/stanza/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	yaml "gopkg.in/yaml.v2"
@@ -116,6 +118,38 @@ func TestWriterSetOutputsValid(t *testing.T) {
 	require.Equal(t, []operator.Operator{output1, output2}, writer.Outputs())
 }
 
+func TestWriterOperatorMetrics(t *testing.T) {
+	output := &testutil.Operator{}
+	output.On("ID").Return("$.metrics_output")
+	output.On("CanProcess").Return(true)
+	output.On("Process", mock.Anything, mock.Anything).Return(nil)
+	writer := WriterOperator{
+		BasicOperator: BasicOperator{OperatorID: "$.metrics_writer"},
+		OutputIDs:     OutputIDs{"$.metrics_output"},
+	}
+	require.NoError(t, writer.SetOutputs([]operator.Operator{output}))
+
+	writer.Write(context.Background(), entry.New())
+	writer.Write(context.Background(), entry.New())
+	require.Equal(t, float64(2), promtestutil.ToFloat64(metrics.EntriesOut.WithLabelValues("$.metrics_writer")))
+	require.Equal(t, float64(2), promtestutil.ToFloat64(metrics.EntriesIn.WithLabelValues("$.metrics_output")))
+}
+
+func TestWriterOperatorWriteDirectOutputs(t *testing.T) {
+	output := testutil.NewFakeOutput(t)
+	writer := WriterOperator{
+		OutputOperators: []operator.Operator{output},
+	}
+
+	// Writing to outputs that were assigned directly does not allocate
+	e := entry.New()
+	allocs := testing.AllocsPerRun(10, func() {
+		writer.Write(context.Background(), e)
+		<-output.Received
+	})
+	require.Zero(t, allocs)
+}
+
 func TestUnmarshalJSONString(t *testing.T) {
 	bytes := []byte("{\"output\":\"test\"}")
 	var config WriterConfig
@@ -195,3 +229,40 @@ func TestUnmarshalYAMLInvalidArray(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "value in array is not of type string")
 }
+
+type recordingTap struct {
+	records []interface{}
+}
+
+func (r *recordingTap) Observe(e *entry.Entry) {
+	r.records = append(r.records, e.Record)
+}
+
+func TestWriterOperatorTap(t *testing.T) {
+	output := testutil.NewFakeOutput(t)
+	writer := WriterOperator{
+		OutputIDs: OutputIDs{output.ID()},
+	}
+	require.NoError(t, writer.SetOutputs([]operator.Operator{output}))
+
+	tap := &recordingTap{}
+	other := &recordingTap{}
+	writer.AttachTap(tap)
+	writer.AttachTap(other)
+
+	writer.Write(context.Background(), &entry.Entry{Record: "first"})
+	output.ExpectRecord(t, "first")
+
+	// Taps are kept when the outputs are replaced
+	require.NoError(t, writer.SetOutputs([]operator.Operator{output}))
+	writer.Write(context.Background(), &entry.Entry{Record: "second"})
+	output.ExpectRecord(t, "second")
+
+	writer.DetachTap(tap)
+	writer.Write(context.Background(), &entry.Entry{Record: "third"})
+	output.ExpectRecord(t, "third")
+
+	require.Equal(t, []interface{}{"first", "second"}, tap.records)
+	require.Equal(t, []interface{}{"first", "second", "third"}, other.records)
+	require.Equal(t, []operator.Operator{output}, writer.Outputs())
+}
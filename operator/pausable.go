@@ -0,0 +1,15 @@
+package operator
+
+// Pausable is an optional interface implemented by operators that can stop reading
+// new entries while they are running, like inputs. A paused operator keeps its
+// connections and offsets, and continues where it left off when it is resumed.
+type Pausable interface {
+	// Pause stops the operator from reading new entries.
+	Pause()
+
+	// Resume allows a paused operator to read new entries again.
+	Resume()
+
+	// Paused returns true if the operator is paused.
+	Paused() bool
+}
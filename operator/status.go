@@ -0,0 +1,23 @@
+package operator
+
+// Status is a report of an operator's health.
+type Status struct {
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// StatusReporter is an optional interface implemented by operators that are able
+// to report their own health. Operators that do not implement it are assumed healthy.
+type StatusReporter interface {
+	// Status returns the current status of the operator.
+	Status() Status
+}
+
+// GetStatus returns the status of an operator, or a healthy status
+// if the operator does not report its own.
+func GetStatus(op Operator) Status {
+	if reporter, ok := op.(StatusReporter); ok {
+		return reporter.Status()
+	}
+	return Status{Healthy: true}
+}
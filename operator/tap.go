@@ -0,0 +1,21 @@
+package operator
+
+import "github.com/observiq/stanza/entry"
+
+// Tap observes the entries written by an operator, for debugging a running pipeline.
+type Tap interface {
+	// Observe is called with each entry written by the operator, before it is sent to the
+	// operator's outputs. It must not block or modify the entry, and must copy the entry
+	// if it keeps it.
+	Observe(*entry.Entry)
+}
+
+// Tappable is an optional interface implemented by operators that write entries
+// to other operators, allowing taps to be attached while they are running.
+type Tappable interface {
+	// AttachTap attaches a tap to the operator.
+	AttachTap(Tap)
+
+	// DetachTap detaches a tap from the operator.
+	DetachTap(Tap)
+}
@@ -0,0 +1,21 @@
+package operator
+
+// Wrapper is an optional interface implemented by operators that wrap another operator,
+// like an operator with a queue. The optional interfaces of the wrapped operator, like
+// Tappable and Pausable, are found by unwrapping it.
+type Wrapper interface {
+	// Unwrap returns the wrapped operator.
+	Unwrap() Operator
+}
+
+// Unwrap returns the innermost operator wrapped by an operator, or the
+// operator itself if it does not wrap another operator.
+func Unwrap(op Operator) Operator {
+	for {
+		wrapper, ok := op.(Wrapper)
+		if !ok {
+			return op
+		}
+		op = wrapper.Unwrap()
+	}
+}
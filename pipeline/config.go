@@ -1,7 +1,13 @@
 package pipeline
 
 import (
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/observiq/stanza/errors"
 	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/helper"
 )
 
 // Config is the configuration of a pipeline.
@@ -12,7 +18,7 @@ func (c Config) BuildOperators(bc operator.BuildContext) ([]operator.Operator, e
 	operators := make([]operator.Operator, 0, len(c))
 	for i, builder := range c {
 		nbc := getBuildContextWithDefaultOutput(c, i, bc)
-		op, err := builder.Build(nbc)
+		op, err := buildOperators(builder, nbc)
 		if err != nil {
 			return nil, err
 		}
@@ -27,16 +33,64 @@ func (c Config) BuildPipeline(bc operator.BuildContext, defaultOperator operator
 		bc.DefaultOutputIDs = []string{defaultOperator.ID()}
 	}
 
-	operators, err := c.BuildOperators(bc)
-	if err != nil {
-		return nil, err
+	operators := make([]operator.Operator, 0, len(c))
+	builds := make(map[string][]operator.Operator, len(c))
+	for i, builder := range c {
+		nbc := getBuildContextWithDefaultOutput(c, i, bc)
+		ops, err := buildOperators(builder, nbc)
+		if err != nil {
+			return nil, err
+		}
+		if hash := configHash(builder, nbc); hash != "" {
+			builds[hash] = ops
+		}
+		operators = append(operators, ops...)
 	}
 
 	if defaultOperator != nil {
 		operators = append(operators, defaultOperator)
 	}
 
-	return NewDirectedPipeline(operators)
+	pipeline, err := NewDirectedPipeline(operators)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline.builds = builds
+	return pipeline, nil
+}
+
+// queuedBuilder is a builder that may place a queue in front of the operator it builds
+type queuedBuilder interface {
+	QueueConfig() *helper.QueueConfig
+}
+
+// buildOperators builds the operators of a config, placing a queue in front
+// of the operator if the config has one
+func buildOperators(config operator.Config, bc operator.BuildContext) ([]operator.Operator, error) {
+	ops, err := config.Build(bc)
+	if err != nil {
+		return nil, err
+	}
+
+	queued, ok := config.Builder.(queuedBuilder)
+	if !ok || queued.QueueConfig() == nil {
+		return ops, nil
+	}
+
+	if len(ops) != 1 {
+		return nil, errors.NewError(
+			"operator has a `queue`, but it builds multiple operators.",
+			"ensure that the `queue` field is not set on plugins.",
+			"operator_id", config.ID(),
+		)
+	}
+
+	op, err := queued.QueueConfig().Build(ops[0])
+	if err != nil {
+		return nil, err
+	}
+	return []operator.Operator{op}, nil
 }
 
 func getBuildContextWithDefaultOutput(configs []operator.Config, i int, bc operator.BuildContext) operator.BuildContext {
@@ -48,3 +102,20 @@ func getBuildContextWithDefaultOutput(configs []operator.Config, i int, bc opera
 	id = bc.PrependNamespace(id)
 	return bc.WithDefaultOutputIDs([]string{id})
 }
+
+// configHash returns a hash that identifies an operator config as built with
+// the supplied build context, or an empty string if the config can not be hashed.
+func configHash(config operator.Config, bc operator.BuildContext) string {
+	contents, err := json.Marshal(config)
+	if err != nil {
+		return ""
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write(contents)
+	for _, value := range append([]string{bc.Namespace}, bc.DefaultOutputIDs...) {
+		_, _ = hash.Write([]byte{0})
+		_, _ = hash.Write([]byte(value))
+	}
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
@@ -0,0 +1,50 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/builtin/input/generate"
+	"github.com/observiq/stanza/operator/builtin/transformer/noop"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPipelineQueue(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		generateConfig := generate.NewGenerateInputConfig("generate")
+		generateConfig.Count = 1
+		generateConfig.Entry.Record = "test"
+		generateConfig.OutputIDs = []string{"noop"}
+
+		queue := helper.NewQueueConfig()
+		noopConfig := noop.NewNoopOperatorConfig("noop")
+		noopConfig.Queue = &queue
+
+		bc := testutil.NewBuildContext(t)
+		output := testutil.NewFakeOutput(t)
+		cfg := Config{
+			operator.Config{Builder: generateConfig},
+			operator.Config{Builder: noopConfig},
+		}
+		pipeline, err := cfg.BuildPipeline(bc, output)
+		require.NoError(t, err)
+		require.IsType(t, &helper.QueuedOperator{}, findOperator(t, pipeline, "$.noop"))
+
+		require.NoError(t, pipeline.Start())
+		output.ExpectRecord(t, "test")
+		require.NoError(t, pipeline.Stop())
+	})
+
+	t.Run("CanNotProcess", func(t *testing.T) {
+		queue := helper.NewQueueConfig()
+		generateConfig := generate.NewGenerateInputConfig("generate")
+		generateConfig.Queue = &queue
+
+		cfg := Config{operator.Config{Builder: generateConfig}}
+		_, err := cfg.BuildPipeline(testutil.NewBuildContext(t), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "can not process entries")
+	})
+}
@@ -2,7 +2,9 @@ package pipeline
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/observiq/stanza/errors"
 	"github.com/observiq/stanza/operator"
@@ -16,42 +18,102 @@ var _ Pipeline = (*DirectedPipeline)(nil)
 // DirectedPipeline is a pipeline backed by a directed graph
 type DirectedPipeline struct {
 	Graph *simple.DirectedGraph
+
+	// builds holds the operators of the pipeline keyed by a hash of
+	// the config that built them, so they can be reused on reload
+	builds map[string][]operator.Operator
+
+	// started holds the IDs of the operators that have been started successfully
+	startedMux sync.Mutex
+	started    map[string]bool
 }
 
 // Start will start the operators in a pipeline in reverse topological order
 func (p *DirectedPipeline) Start() error {
+	return startOperators(p, func(operator.Operator) bool { return true })
+}
+
+// Stop will stop the operators in a pipeline in topological order
+func (p *DirectedPipeline) Stop() error {
+	return stopOperators(p, func(operator.Operator) bool { return true })
+}
+
+// startOperators will start the matching operators of a pipeline in reverse topological order
+func startOperators(p *DirectedPipeline, match func(operator.Operator) bool) error {
 	sortedNodes, err := topo.Sort(p.Graph)
 	if err != nil {
 		return err
 	}
 	for i := len(sortedNodes) - 1; i >= 0; i-- {
 		operator := sortedNodes[i].(OperatorNode).Operator()
+		if !match(operator) {
+			continue
+		}
 		operator.Logger().Debug("Starting operator")
 		if err := operator.Start(); err != nil {
 			return err
 		}
+		p.setStarted(operator.ID(), true)
 		operator.Logger().Debug("Started operator")
 	}
 
 	return nil
 }
 
-// Stop will stop the operators in a pipeline in topological order
-func (p *DirectedPipeline) Stop() error {
+// stopOperators will stop the matching operators of a pipeline in topological order
+func stopOperators(p *DirectedPipeline, match func(operator.Operator) bool) error {
 	sortedNodes, err := topo.Sort(p.Graph)
 	if err != nil {
 		return err
 	}
 	for _, node := range sortedNodes {
 		operator := node.(OperatorNode).Operator()
+		if !match(operator) {
+			continue
+		}
 		operator.Logger().Debug("Stopping operator")
 		_ = operator.Stop()
+		p.setStarted(operator.ID(), false)
 		operator.Logger().Debug("Stopped operator")
 	}
 
 	return nil
 }
 
+// setStarted will record whether the operator with the supplied ID is started
+func (p *DirectedPipeline) setStarted(operatorID string, started bool) {
+	p.startedMux.Lock()
+	defer p.startedMux.Unlock()
+	if p.started == nil {
+		p.started = make(map[string]bool)
+	}
+	if started {
+		p.started[operatorID] = true
+	} else {
+		delete(p.started, operatorID)
+	}
+}
+
+// isStarted returns true if the operator with the supplied ID has been started
+func (p *DirectedPipeline) isStarted(operatorID string) bool {
+	p.startedMux.Lock()
+	defer p.startedMux.Unlock()
+	return p.started[operatorID]
+}
+
+// Unstarted returns the sorted IDs of the operators in the pipeline that have
+// not been started successfully
+func (p *DirectedPipeline) Unstarted() []string {
+	unstarted := make([]string, 0)
+	for _, operator := range p.Operators() {
+		if !p.isStarted(operator.ID()) {
+			unstarted = append(unstarted, operator.ID())
+		}
+	}
+	sort.Strings(unstarted)
+	return unstarted
+}
+
 // Render will render the pipeline as a dot graph
 func (p *DirectedPipeline) Render() ([]byte, error) {
 	return dot.Marshal(p.Graph, "G", "", " ")
@@ -93,6 +155,11 @@ func connectNodes(graph *simple.DirectedGraph) error {
 		}
 	}
 
+	return checkCycles(graph)
+}
+
+// checkCycles will return an error if the supplied graph has a circular dependency.
+func checkCycles(graph *simple.DirectedGraph) error {
 	if _, err := topo.Sort(graph); err != nil {
 		return errors.NewError(
 			"pipeline has a circular dependency",
@@ -100,7 +167,6 @@ func connectNodes(graph *simple.DirectedGraph) error {
 			"cycles", unorderableToCycles(err.(topo.Unorderable)),
 		)
 	}
-
 	return nil
 }
 
@@ -116,6 +182,15 @@ func connectNode(graph *simple.DirectedGraph, inputNode OperatorNode) error {
 			)
 		}
 
+		if outputNodeID == inputNode.ID() {
+			return errors.NewError(
+				"operators cannot be connected, because the operator outputs to itself",
+				"ensure that the output of the operator is a different operator",
+				"input_operator", inputNode.Operator().ID(),
+				"output_operator", outputOperatorID,
+			)
+		}
+
 		outputNode := graph.Node(outputNodeID).(OperatorNode)
 		if !outputNode.Operator().CanProcess() {
 			return errors.NewError(
@@ -142,9 +217,9 @@ func connectNode(graph *simple.DirectedGraph, inputNode OperatorNode) error {
 	return nil
 }
 
-// setOperatorOutputs will set the outputs on operators that can output.
-func setOperatorOutputs(operators []operator.Operator) error {
-	for _, operator := range operators {
+// setOperatorOutputs will set the outputs on the targeted operators that can output.
+func setOperatorOutputs(targets []operator.Operator, operators []operator.Operator) error {
+	for _, operator := range targets {
 		if !operator.CanOutput() {
 			continue
 		}
@@ -158,10 +233,20 @@ func setOperatorOutputs(operators []operator.Operator) error {
 
 // NewDirectedPipeline creates a new directed pipeline
 func NewDirectedPipeline(operators []operator.Operator) (*DirectedPipeline, error) {
-	if err := setOperatorOutputs(operators); err != nil {
+	if err := setOperatorOutputs(operators, operators); err != nil {
+		return nil, err
+	}
+
+	graph, err := newOperatorGraph(operators)
+	if err != nil {
 		return nil, err
 	}
 
+	return &DirectedPipeline{Graph: graph}, nil
+}
+
+// newOperatorGraph will create a graph of operators connected to their current outputs.
+func newOperatorGraph(operators []operator.Operator) (*simple.DirectedGraph, error) {
 	graph := simple.NewDirectedGraph()
 	if err := addNodes(graph, operators); err != nil {
 		return nil, err
@@ -171,7 +256,7 @@ func NewDirectedPipeline(operators []operator.Operator) (*DirectedPipeline, erro
 		return nil, err
 	}
 
-	return &DirectedPipeline{Graph: graph}, nil
+	return graph, nil
 }
 
 func unorderableToCycles(err topo.Unorderable) string {
@@ -208,6 +208,36 @@ func TestPipelineStartOrder(t *testing.T) {
 	require.Contains(t, err.Error(), "operator 1 failed to start")
 	require.True(t, mock2Started)
 	require.True(t, mock3Started)
+	require.Equal(t, []string{"operator1"}, pipeline.Unstarted())
+}
+
+func TestPipelineUnstarted(t *testing.T) {
+	mockOperator1 := testutil.NewMockOperator("operator1")
+	mockOperator2 := testutil.NewMockOperator("operator2")
+
+	mockOperator1.On("Outputs").Return([]operator.Operator{mockOperator2})
+	mockOperator2.On("Outputs").Return(nil)
+
+	mockOperator1.On("SetOutputs", mock.Anything).Return(nil)
+	mockOperator2.On("SetOutputs", mock.Anything).Return(nil)
+
+	mockOperator1.On("Logger", mock.Anything).Return(zap.NewNop().Sugar())
+	mockOperator2.On("Logger", mock.Anything).Return(zap.NewNop().Sugar())
+
+	mockOperator1.On("Start").Return(nil)
+	mockOperator2.On("Start").Return(nil)
+	mockOperator1.On("Stop").Return(nil)
+	mockOperator2.On("Stop").Return(nil)
+
+	pipeline, err := NewDirectedPipeline([]operator.Operator{mockOperator1, mockOperator2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"operator1", "operator2"}, pipeline.Unstarted())
+
+	require.NoError(t, pipeline.Start())
+	require.Empty(t, pipeline.Unstarted())
+
+	require.NoError(t, pipeline.Stop())
+	require.Equal(t, []string{"operator1", "operator2"}, pipeline.Unstarted())
 }
 
 func TestPipelineStopOrder(t *testing.T) {
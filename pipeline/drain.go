@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/observiq/stanza/operator"
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+// drainInterval is how often an operator is checked for pending entries while it is drained
+var drainInterval = 10 * time.Millisecond
+
+// DrainResult describes the entries held by an operator while its pipeline was drained
+type DrainResult struct {
+	OperatorID string
+	// Pending is the number of entries the operator held once the inputs were stopped
+	Pending int
+	// Remaining is the number of entries the operator still held when it was stopped
+	Remaining int
+}
+
+// Drain will stop the pipeline, giving its operators a chance to deliver the entries they
+// hold. The inputs are stopped first, so that no new entries enter the pipeline. The remaining
+// operators are then stopped in topological order, each once it no longer holds entries or the
+// context is done. A result is returned for each operator that is able to report its entries.
+func (p *DirectedPipeline) Drain(ctx context.Context) ([]DrainResult, error) {
+	if err := stopOperators(p, isInput); err != nil {
+		return nil, err
+	}
+
+	sortedNodes, err := topo.Sort(p.Graph)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string]int)
+	for _, node := range sortedNodes {
+		op := node.(OperatorNode).Operator()
+		pending[op.ID()] = operator.GetPending(op)
+	}
+
+	results := make([]DrainResult, 0)
+	for _, node := range sortedNodes {
+		op := node.(OperatorNode).Operator()
+		if isInput(op) {
+			continue
+		}
+
+		remaining := waitForDrain(ctx, op)
+		op.Logger().Debug("Stopping operator")
+		if err := op.Stop(); err != nil {
+			op.Logger().Errorw("Failed to stop operator", "error", err)
+		}
+		p.setStarted(op.ID(), false)
+		op.Logger().Debug("Stopped operator")
+
+		if _, ok := op.(operator.Drainer); ok {
+			results = append(results, DrainResult{
+				OperatorID: op.ID(),
+				Pending:    pending[op.ID()],
+				Remaining:  remaining,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// waitForDrain waits until the operator holds no entries or the context is done,
+// and returns the number of entries it still holds
+func waitForDrain(ctx context.Context, op operator.Operator) int {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for {
+		pending := operator.GetPending(op)
+		if pending == 0 {
+			return 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return pending
+		case <-ticker.C:
+		}
+	}
+}
+
+// isInput returns true if the operator only produces entries
+func isInput(op operator.Operator) bool {
+	return !op.CanProcess()
+}
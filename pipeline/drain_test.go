@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// drainingOperator is a mock operator that holds a number of pending entries
+type drainingOperator struct {
+	*testutil.Operator
+	pending int64
+}
+
+func (d *drainingOperator) Pending() int {
+	return int(atomic.LoadInt64(&d.pending))
+}
+
+func newDrainTestPipeline(t *testing.T, pending int64) (*DirectedPipeline, *testutil.Operator, *drainingOperator) {
+	input := &testutil.Operator{}
+	input.On("ID").Return("input")
+	input.On("CanProcess").Return(false)
+	input.On("CanOutput").Return(true)
+
+	output := &drainingOperator{
+		Operator: testutil.NewMockOperator("output"),
+		pending:  pending,
+	}
+
+	input.On("Outputs").Return([]operator.Operator{output})
+	output.On("Outputs").Return(nil)
+
+	for _, op := range []*testutil.Operator{input, output.Operator} {
+		op.On("SetOutputs", mock.Anything).Return(nil)
+		op.On("Logger", mock.Anything).Return(zap.NewNop().Sugar())
+		op.On("Start").Return(nil)
+	}
+
+	pipeline, err := NewDirectedPipeline([]operator.Operator{input, output})
+	require.NoError(t, err)
+	require.NoError(t, pipeline.Start())
+	return pipeline, input, output
+}
+
+func TestPipelineDrain(t *testing.T) {
+	t.Run("Drained", func(t *testing.T) {
+		pipeline, input, output := newDrainTestPipeline(t, 3)
+
+		var inputStopped int64
+		input.On("Stop").Run(func(mock.Arguments) {
+			atomic.StoreInt64(&inputStopped, 1)
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				atomic.StoreInt64(&output.pending, 0)
+			}()
+		}).Return(nil)
+		output.On("Stop").Run(func(mock.Arguments) {
+			require.Equal(t, int64(1), atomic.LoadInt64(&inputStopped))
+			require.Equal(t, 0, output.Pending())
+		}).Return(nil)
+
+		results, err := pipeline.Drain(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []DrainResult{{OperatorID: "output", Pending: 3, Remaining: 0}}, results)
+		require.Equal(t, []string{"input", "output"}, pipeline.Unstarted())
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		pipeline, input, output := newDrainTestPipeline(t, 5)
+		input.On("Stop").Return(nil)
+		output.On("Stop").Return(nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		results, err := pipeline.Drain(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []DrainResult{{OperatorID: "output", Pending: 5, Remaining: 5}}, results)
+		output.AssertCalled(t, "Stop")
+	})
+}
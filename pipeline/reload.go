@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"github.com/observiq/stanza/operator"
+)
+
+// Reload will build the config into a pipeline that replaces the running pipeline.
+//
+// Operators built from a config that has not changed since the running pipeline
+// was built are carried over without being restarted, keeping state such as open
+// files and offsets, and are only connected to their new outputs. The newly built
+// operators that receive entries are started before any carried over operator is
+// connected to them, so that no entry is written to an operator that is not started.
+// The remaining operators of the running pipeline are then stopped, after which the
+// newly built inputs are started, so that they can take over resources such as
+// listening ports from the inputs they replace.
+//
+// If the config fails to build, or a newly built operator that receives entries
+// fails to start, an error is returned and the running pipeline is left untouched.
+// If an input fails to start, the new pipeline is returned alongside the error,
+// since the running pipeline has already been replaced.
+func (c Config) Reload(bc operator.BuildContext, defaultOperator operator.Operator, running *DirectedPipeline) (*DirectedPipeline, error) {
+	if defaultOperator != nil {
+		bc.DefaultOutputIDs = []string{defaultOperator.ID()}
+	}
+
+	runningOperators := make(map[string]operator.Operator)
+	for _, op := range running.Operators() {
+		runningOperators[op.ID()] = op
+	}
+
+	operators := make([]operator.Operator, 0, len(c))
+	builds := make(map[string][]operator.Operator, len(c))
+	reused := make(map[string]bool)
+	for i, builder := range c {
+		nbc := getBuildContextWithDefaultOutput(c, i, bc)
+		hash := configHash(builder, nbc)
+		if ops, ok := running.builds[hash]; ok && hash != "" {
+			for _, op := range ops {
+				reused[op.ID()] = true
+			}
+			builds[hash] = ops
+			operators = append(operators, ops...)
+			continue
+		}
+
+		ops, err := buildOperators(builder, nbc)
+		if err != nil {
+			return nil, err
+		}
+		if hash != "" {
+			builds[hash] = ops
+		}
+		operators = append(operators, ops...)
+	}
+
+	if defaultOperator != nil {
+		if runningOperators[defaultOperator.ID()] == defaultOperator {
+			reused[defaultOperator.ID()] = true
+		}
+		operators = append(operators, defaultOperator)
+	}
+
+	// Connect and validate the new operators before touching any running operator.
+	// The graph is built from the reused operators' current outputs, which share
+	// their IDs with the outputs they will be connected to.
+	newOperators := make([]operator.Operator, 0, len(operators))
+	for _, op := range operators {
+		if !reused[op.ID()] {
+			newOperators = append(newOperators, op)
+		}
+	}
+
+	if err := setOperatorOutputs(newOperators, operators); err != nil {
+		return nil, err
+	}
+
+	graph, err := newOperatorGraph(operators)
+	if err != nil {
+		return nil, err
+	}
+
+	reusedOperators := make([]operator.Operator, 0, len(reused))
+	for _, op := range operators {
+		if reused[op.ID()] {
+			reusedOperators = append(reusedOperators, op)
+		}
+	}
+
+	pipeline := &DirectedPipeline{Graph: graph, builds: builds}
+	isNew := func(op operator.Operator) bool { return !reused[op.ID()] }
+	stopStarted := func() {
+		_ = stopOperators(pipeline, func(op operator.Operator) bool { return isNew(op) && pipeline.isStarted(op.ID()) })
+	}
+	if err := startOperators(pipeline, func(op operator.Operator) bool { return isNew(op) && op.CanProcess() }); err != nil {
+		stopStarted()
+		return nil, err
+	}
+
+	if err := setOperatorOutputs(reusedOperators, operators); err != nil {
+		stopStarted()
+		return nil, err
+	}
+
+	if err := stopOperators(running, isNew); err != nil {
+		return nil, err
+	}
+
+	for id := range reused {
+		pipeline.setStarted(id, running.isStarted(id))
+	}
+	return pipeline, startOperators(pipeline, func(op operator.Operator) bool { return isNew(op) && !op.CanProcess() })
+}
@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/builtin/input/generate"
+	"github.com/observiq/stanza/operator/builtin/transformer/noop"
+	"github.com/observiq/stanza/operator/helper"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newReloadTestConfig(noopOutput string) Config {
+	generateConfig := generate.NewGenerateInputConfig("generate")
+	generateConfig.Count = 1
+	generateConfig.OutputIDs = []string{"noop"}
+
+	noopConfig := noop.NewNoopOperatorConfig("noop")
+	noopConfig.OutputIDs = []string{noopOutput}
+
+	return Config{
+		operator.Config{Builder: generateConfig},
+		operator.Config{Builder: noopConfig},
+	}
+}
+
+func findOperator(t *testing.T, p *DirectedPipeline, id string) operator.Operator {
+	for _, op := range p.Operators() {
+		if op.ID() == id {
+			return op
+		}
+	}
+	require.FailNow(t, "operator not found", id)
+	return nil
+}
+
+func TestReload(t *testing.T) {
+	t.Run("Unchanged", func(t *testing.T) {
+		bc := testutil.NewBuildContext(t)
+		output := testutil.NewFakeOutput(t)
+		running, err := newReloadTestConfig("$.fake").BuildPipeline(bc, output)
+		require.NoError(t, err)
+		require.NoError(t, running.Start())
+		defer running.Stop()
+
+		next, err := newReloadTestConfig("$.fake").Reload(bc, output, running)
+		require.NoError(t, err)
+		require.Same(t, findOperator(t, running, "$.generate"), findOperator(t, next, "$.generate"))
+		require.Same(t, findOperator(t, running, "$.noop"), findOperator(t, next, "$.noop"))
+		require.Same(t, output, findOperator(t, next, "$.fake"))
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		bc := testutil.NewBuildContext(t)
+		output := testutil.NewFakeOutput(t)
+		running, err := newReloadTestConfig("$.fake").BuildPipeline(bc, output)
+		require.NoError(t, err)
+		require.NoError(t, running.Start())
+
+		cfg := newReloadTestConfig("$.fake")
+		cfg[1].Builder.(*noop.NoopOperatorConfig).IfExpr = "true"
+		next, err := cfg.Reload(bc, output, running)
+		require.NoError(t, err)
+		defer next.Stop()
+
+		generateOperator := findOperator(t, next, "$.generate")
+		noopOperator := findOperator(t, next, "$.noop")
+		require.Same(t, findOperator(t, running, "$.generate"), generateOperator)
+		require.NotSame(t, findOperator(t, running, "$.noop"), noopOperator)
+		require.Equal(t, []operator.Operator{noopOperator}, generateOperator.Outputs())
+	})
+
+	t.Run("Queued", func(t *testing.T) {
+		bc := testutil.NewBuildContext(t)
+		output := testutil.NewFakeOutput(t)
+		running, err := newReloadTestConfig("$.fake").BuildPipeline(bc, output)
+		require.NoError(t, err)
+		require.NoError(t, running.Start())
+
+		queue := helper.NewQueueConfig()
+		cfg := newReloadTestConfig("$.fake")
+		cfg[1].Builder.(*noop.NoopOperatorConfig).Queue = &queue
+		next, err := cfg.Reload(bc, output, running)
+		require.NoError(t, err)
+		defer next.Stop()
+
+		noopOperator := findOperator(t, next, "$.noop")
+		require.IsType(t, &helper.QueuedOperator{}, noopOperator)
+		require.Equal(t, []operator.Operator{noopOperator}, findOperator(t, next, "$.generate").Outputs())
+	})
+
+	t.Run("RunningInput", func(t *testing.T) {
+		const count = 100000
+		newConfig := func(ifExpr string) Config {
+			queue := helper.NewQueueConfig()
+			cfg := newReloadTestConfig("$.fake")
+			cfg[0].Builder.(*generate.GenerateInputConfig).Count = count
+			cfg[1].Builder.(*noop.NoopOperatorConfig).IfExpr = ifExpr
+			cfg[1].Builder.(*noop.NoopOperatorConfig).Queue = &queue
+			return cfg
+		}
+
+		bc := testutil.NewBuildContext(t)
+		output := testutil.NewFakeOutput(t)
+		var received int64
+		go func() {
+			for range output.Received {
+				atomic.AddInt64(&received, 1)
+			}
+		}()
+
+		running, err := newConfig("").BuildPipeline(bc, output)
+		require.NoError(t, err)
+		require.NoError(t, running.Start())
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt64(&received) > 0
+		}, 5*time.Second, time.Millisecond)
+
+		// The input keeps writing to the queued operator while it is replaced
+		next, err := newConfig("true").Reload(bc, output, running)
+		require.NoError(t, err)
+		require.Less(t, atomic.LoadInt64(&received), int64(count), "the input finished before the reload")
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt64(&received) == count
+		}, 10*time.Second, 10*time.Millisecond)
+		require.NoError(t, next.Stop())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		bc := testutil.NewBuildContext(t)
+		output := testutil.NewFakeOutput(t)
+		running, err := newReloadTestConfig("$.fake").BuildPipeline(bc, output)
+		require.NoError(t, err)
+		require.NoError(t, running.Start())
+		defer running.Stop()
+
+		noopOperator := findOperator(t, running, "$.noop")
+		generateOperator := findOperator(t, running, "$.generate")
+
+		_, err = newReloadTestConfig("$.missing").Reload(bc, output, running)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not exist")
+		require.Equal(t, []operator.Operator{noopOperator}, generateOperator.Outputs())
+	})
+}
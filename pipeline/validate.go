@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"strings"
+
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Validate will build the operators of the config and connect them into a graph, without
+// starting them. Unlike BuildPipeline, it does not stop at the first error, and returns every
+// error it finds. Errors caused by a single operator have an `operator_id` detail.
+func (c Config) Validate(bc operator.BuildContext) []error {
+	errs := make([]error, 0)
+
+	operators := make([]operator.Operator, 0, len(c))
+	for i, builder := range c {
+		nbc := getBuildContextWithDefaultOutput(c, i, bc)
+		ops, err := buildOperators(builder, nbc)
+		if err != nil {
+			errs = append(errs, withOperatorID(err, bc.PrependNamespace(builder.ID())))
+			continue
+		}
+		operators = append(operators, ops...)
+	}
+
+	for _, op := range operators {
+		if !op.CanOutput() {
+			continue
+		}
+		if err := op.SetOutputs(operators); err != nil {
+			errs = append(errs, withOperatorID(err, op.ID()))
+		}
+	}
+
+	graph := simple.NewDirectedGraph()
+	for _, op := range operators {
+		if err := addNodes(graph, []operator.Operator{op}); err != nil {
+			errs = append(errs, withOperatorID(err, op.ID()))
+		}
+	}
+
+	nodes := graph.Nodes()
+	for nodes.Next() {
+		if err := connectNode(graph, nodes.Node().(OperatorNode)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := checkCycles(graph); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// withOperatorID will set the `operator_id` detail of an error, unless it already names
+// an operator within the namespace of the operator, like an operator built by a plugin
+func withOperatorID(err error, operatorID string) error {
+	if agentErr, ok := err.(errors.AgentError); ok {
+		if strings.HasPrefix(agentErr.Details["operator_id"], operatorID+".") {
+			return agentErr
+		}
+	}
+	return errors.WithDetails(err, "operator_id", operatorID)
+}
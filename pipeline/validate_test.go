@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/observiq/stanza/errors"
+	"github.com/observiq/stanza/operator"
+	"github.com/observiq/stanza/operator/builtin/transformer/noop"
+	"github.com/observiq/stanza/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		cfg := newReloadTestConfig("output")
+		cfg = append(cfg, operator.Config{Builder: noop.NewNoopOperatorConfig("output")})
+		errs := cfg.Validate(testutil.NewBuildContext(t))
+		require.Empty(t, errs)
+	})
+
+	t.Run("AllErrors", func(t *testing.T) {
+		missingType := noop.NewNoopOperatorConfig("missing_type")
+		missingType.OperatorType = ""
+
+		missingOutput := noop.NewNoopOperatorConfig("missing_output")
+		missingOutput.OutputIDs = []string{"missing"}
+
+		first := noop.NewNoopOperatorConfig("first")
+		first.OutputIDs = []string{"second"}
+		second := noop.NewNoopOperatorConfig("second")
+		second.OutputIDs = []string{"first"}
+
+		cfg := Config{
+			operator.Config{Builder: missingType},
+			operator.Config{Builder: missingOutput},
+			operator.Config{Builder: first},
+			operator.Config{Builder: second},
+		}
+
+		errs := cfg.Validate(testutil.NewBuildContext(t))
+		require.Len(t, errs, 3, "%v", errs)
+
+		require.Contains(t, errs[0].Error(), "missing required `type` field")
+		require.Equal(t, "$.missing_type", errs[0].(errors.AgentError).Details["operator_id"])
+
+		require.Contains(t, errs[1].Error(), "operator '$.missing' does not exist")
+		require.Equal(t, "$.missing_output", errs[1].(errors.AgentError).Details["operator_id"])
+
+		require.Contains(t, errs[2].Error(), "circular dependency")
+	})
+
+	t.Run("DuplicateID", func(t *testing.T) {
+		cfg := Config{
+			operator.Config{Builder: noop.NewNoopOperatorConfig("noop")},
+			operator.Config{Builder: noop.NewNoopOperatorConfig("noop")},
+		}
+
+		errs := cfg.Validate(testutil.NewBuildContext(t))
+		require.Len(t, errs, 2, "%v", errs)
+		require.Contains(t, errs[0].Error(), "already exists")
+		require.Contains(t, errs[1].Error(), "outputs to itself")
+	})
+}
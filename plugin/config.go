@@ -38,7 +38,8 @@ func (c *Config) Build(bc operator.BuildContext) ([]operator.Operator, error) {
 		Pipeline pipeline.Config
 	}
 	if err := yaml.Unmarshal(pipelineConfigBytes, &pipelineConfig); err != nil {
-		return nil, err
+		// The rendered plugin may contain secrets, which are redacted by an agent error
+		return nil, errors.Wrap(err, "read rendered plugin as yaml")
 	}
 
 	nbc := bc.WithSubNamespace(c.ID()).WithIncrementedDepth()
@@ -0,0 +1,218 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Redacted is the text that replaces a secret when it is redacted
+const Redacted = "[REDACTED]"
+
+// referenceRegex matches references like `${env:NAME}`, `${env:NAME:-default}` and `${file:/path}`,
+// which may be marked as not secret like `${plain:env:NAME}`, along with references escaped by an
+// extra `$`, like `$${env:NAME}`
+var referenceRegex = regexp.MustCompile(`\$?\$\{(plain:)?(env|file):([^}]*)\}`)
+
+// registry holds the secret values that have been substituted into configs, so they can be redacted
+var registry = struct {
+	sync.RWMutex
+	secrets []string
+}{}
+
+// Expand will replace the references to environment variables and files in the values of a YAML
+// config with their values. References are only replaced in the values of the config, so references
+// in comments are left as they are, and values that contain characters that are special in YAML
+// are kept as they are. A value that is only a reference takes the type of its substituted value,
+// unless the reference is quoted. The substituted values are remembered as secrets, so they can be
+// redacted wherever the config is echoed back, unless their reference is marked as plain. A reference
+// escaped with an extra `$` is replaced by the reference itself.
+func Expand(text []byte) ([]byte, error) {
+	if !referenceRegex.Match(text) {
+		return text, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(text, &document); err != nil {
+		// The config is not valid YAML, so it is left for the config parser to report
+		return text, nil
+	}
+
+	if err := expandNode(&document); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, fmt.Errorf("encode config: %s", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode config: %s", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// expandNode replaces the references in each scalar of a YAML node
+func expandNode(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return expandScalar(node)
+	case yaml.AliasNode:
+		// The node an alias refers to is expanded where it is defined
+		return nil
+	default:
+		for _, child := range node.Content {
+			if err := expandNode(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// expandScalar replaces the references in the value of a YAML scalar
+func expandScalar(node *yaml.Node) error {
+	if !referenceRegex.MatchString(node.Value) {
+		return nil
+	}
+
+	value, err := expandString(node.Value)
+	if err != nil {
+		return err
+	}
+
+	// A plain value that is only a reference is resolved from its substituted value, so that a
+	// reference can be used for numbers and booleans. Other values, including empty values, stay
+	// strings, and are quoted by the encoder as needed.
+	if node.Style == 0 && value != "" && isReference(node.Value) {
+		node.Tag = ""
+	}
+	node.Value = value
+	return nil
+}
+
+// isReference returns true if a value is a single reference that is not escaped
+func isReference(value string) bool {
+	loc := referenceRegex.FindStringIndex(value)
+	return loc != nil && loc[0] == 0 && loc[1] == len(value) && !strings.HasPrefix(value, "$$")
+}
+
+// expandString replaces the references in a string with their values
+func expandString(text string) (string, error) {
+	var expandErr error
+	expanded := referenceRegex.ReplaceAllStringFunc(text, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+
+		if match[1] == '$' {
+			return match[1:]
+		}
+
+		submatches := referenceRegex.FindStringSubmatch(match)
+		isSecret := submatches[1] == ""
+		var value string
+		var err error
+		switch submatches[2] {
+		case "env":
+			value, err = expandEnv(submatches[3], isSecret)
+		case "file":
+			value, err = expandFile(submatches[3], isSecret)
+		}
+		if err != nil {
+			expandErr = err
+			return match
+		}
+		return value
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
+
+// expandEnv returns the value of an environment variable reference, which may have a default value.
+// Unless the reference is plain, the value of the variable is registered as a secret.
+func expandEnv(reference string, isSecret bool) (string, error) {
+	name, defaultValue, hasDefault := reference, "", false
+	if i := strings.Index(reference, ":-"); i >= 0 {
+		name, defaultValue, hasDefault = reference[:i], reference[i+2:], true
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("reference `${env:%s}` is missing the name of the environment variable", reference)
+	}
+
+	value, ok := os.LookupEnv(name)
+	switch {
+	case hasDefault && value == "":
+		return defaultValue, nil
+	case !ok:
+		return "", fmt.Errorf("environment variable %s is not set, and the reference to it has no default", name)
+	}
+
+	if isSecret {
+		register(value)
+	}
+	return value, nil
+}
+
+// expandFile returns the contents of a file reference, without a trailing newline.
+// Unless the reference is plain, the contents are registered as a secret.
+func expandFile(path string, isSecret bool) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("reference `${file:}` is missing the path of the file")
+	}
+
+	contents, err := ioutil.ReadFile(path) // #nosec - secrets load based on user specified path
+	if err != nil {
+		return "", fmt.Errorf("read secret file: %s", err)
+	}
+
+	value := strings.TrimRight(string(contents), "\r\n")
+	if isSecret {
+		register(value)
+	}
+	return value, nil
+}
+
+// register will remember a value as a secret
+func register(value string) {
+	if value == "" {
+		return
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	for _, secret := range registry.secrets {
+		if secret == value {
+			return
+		}
+	}
+
+	// The longest secrets are redacted first, in case one secret contains another
+	registry.secrets = append(registry.secrets, value)
+	sort.Slice(registry.secrets, func(i, j int) bool {
+		return len(registry.secrets[i]) > len(registry.secrets[j])
+	})
+}
+
+// Redact will replace each secret that has been substituted into a config with a placeholder
+func Redact(text string) string {
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, secret := range registry.secrets {
+		text = strings.ReplaceAll(text, secret, Redacted)
+	}
+	return text
+}
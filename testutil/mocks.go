@@ -99,3 +99,29 @@ func (f *FakeOutput) ExpectNoEntry(t testing.TB, timeout time.Duration) {
 		return
 	}
 }
+
+// ReceiveEntry expects that an entry will be received by the fake operator within a second
+// and returns it
+func (f *FakeOutput) ReceiveEntry(t testing.TB) *entry.Entry {
+	select {
+	case e := <-f.Received:
+		return e
+	case <-time.After(time.Second):
+		require.FailNow(t, "Timed out waiting for entry")
+		return nil
+	}
+}
+
+// ReceiveAll returns the entries that the fake operator has received and that have not been read,
+// without waiting for more
+func (f *FakeOutput) ReceiveAll() []*entry.Entry {
+	received := make([]*entry.Entry, 0)
+	for {
+		select {
+		case e := <-f.Received:
+			received = append(received, e)
+		default:
+			return received
+		}
+	}
+}